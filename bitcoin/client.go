@@ -79,13 +79,49 @@ const (
 	// https://developer.bitcoin.org/reference/rpc/estimatesmartfee.html
 	requestMethodEstimateSmartFee requestMethod = "estimatesmartfee"
 
+	// https://bitcoin.org/en/developer-reference#estimatefee
+	requestMethodEstimateFee requestMethod = "estimatefee"
+
 	// https://developer.bitcoin.org/reference/rpc/getrawmempool.html
 	requestMethodRawMempool requestMethod = "getrawmempool"
 
+	// https://developer.bitcoin.org/reference/rpc/getrawtransaction.html
+	requestMethodGetRawTransaction requestMethod = "getrawtransaction"
+
 	// blockNotFoundErrCode is the RPC error code when a block cannot be found
 	blockNotFoundErrCode = -5
 )
 
+// RPC error codes of bitcoind
+// Source: https://github.com/dogecoin/dogecoin/blob/v1.14.5/src/rpc/protocol.h
+const (
+	// RPCMiscErrCode is returned for errors without
+	// a specific code, like requests for pruned blocks.
+	RPCMiscErrCode = -1
+
+	// RPCInvalidAddressOrKeyErrCode is returned when
+	// a transaction or block cannot be found.
+	RPCInvalidAddressOrKeyErrCode = -5
+
+	// RPCVerifyErrCode is returned when a transaction
+	// or block cannot be verified, like when the inputs
+	// of a transaction are missing.
+	RPCVerifyErrCode = -25
+
+	// RPCVerifyRejectedErrCode is returned when a
+	// transaction or block is rejected by the
+	// network rules.
+	RPCVerifyRejectedErrCode = -26
+
+	// RPCVerifyAlreadyInChainErrCode is returned when
+	// a transaction is already in the chain.
+	RPCVerifyAlreadyInChainErrCode = -27
+
+	// RPCInWarmupErrCode is returned when bitcoind
+	// is still starting up.
+	RPCInWarmupErrCode = -28
+)
+
 const (
 	defaultTimeout = 100 * time.Second
 	dialTimeout    = 5 * time.Second
@@ -301,6 +337,26 @@ func (b *Client) SuggestedFeeRate(
 	return response.Result.FeeRate, nil
 }
 
+// EstimateFee estimates the approximate fee per kB needed
+// to get a transaction in a block within nblocks using
+// the legacy `estimatefee` RPC. A negative rate is returned
+// when the node does not have enough data to estimate.
+func (b *Client) EstimateFee(
+	ctx context.Context,
+	nblocks int64,
+) (float64, error) {
+	// Parameters:
+	//   1. nblocks (confirmation target in blocks)
+	params := []interface{}{nblocks}
+
+	response := &estimateFeeResponse{}
+	if err := b.post(ctx, requestMethodEstimateFee, params, response); err != nil {
+		return -1, fmt.Errorf("%w: error getting fee estimate", err)
+	}
+
+	return response.Result, nil
+}
+
 // PruneBlockchain prunes up to the provided height.
 // https://bitcoincore.org/en/doc/0.20.0/rpc/blockchain/pruneblockchain
 func (b *Client) PruneBlockchain(
@@ -337,6 +393,108 @@ func (b *Client) RawMempool(
 	return response.Result, nil
 }
 
+// GetRawTransaction returns the decoded transaction with the
+// provided hash. Without a transaction index, bitcoind only
+// returns transactions that are in the mempool.
+func (b *Client) GetRawTransaction(
+	ctx context.Context,
+	hash string,
+) (*Transaction, error) {
+	// Parameters:
+	//   1. txid
+	//   2. verbose
+	params := []interface{}{hash, true}
+
+	response := &rawTransactionResponse{}
+	if err := b.post(ctx, requestMethodGetRawTransaction, params, response); err != nil {
+		return nil, fmt.Errorf("%w: error getting raw transaction %s", err, hash)
+	}
+
+	// dogecoind does not return the weight of transactions
+	if response.Result.Weight == 0 {
+		response.Result.Weight = response.Result.Vsize * weightMultiplier
+	}
+
+	return response.Result, nil
+}
+
+// GetRawBlockTransaction returns the hex encoded transaction with
+// the provided hash from the block with the provided hash. Unlike
+// GetRawTransaction, it does not need a transaction index.
+func (b *Client) GetRawBlockTransaction(
+	ctx context.Context,
+	blockHash string,
+	hash string,
+) (string, error) {
+	// Parameters:
+	//   1. Block hash (string, required)
+	//   2. Verbosity (bool, optional, default=false)
+	params := []interface{}{blockHash, false}
+	response := &stringResponse{}
+	if err := b.post(ctx, requestMethodGetBlock, params, response); err != nil {
+		return "", fmt.Errorf("%w: error fetching block by hash %s", err, blockHash)
+	}
+
+	block, err := hex.DecodeString(response.Result)
+	if err != nil {
+		return "", fmt.Errorf("%w: error decoding block %s", err, blockHash)
+	}
+
+	var msgBlock AuxBlock
+	if err := msgBlock.Deserialize(bytes.NewReader(block)); err != nil {
+		return "", fmt.Errorf("%w: error deserializing block %s", err, blockHash)
+	}
+
+	for _, tx := range msgBlock.Transactions {
+		if tx.TxHash().String() != hash {
+			continue
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return "", fmt.Errorf("%w: error serializing transaction %s", err, hash)
+		}
+
+		return hex.EncodeToString(buf.Bytes()), nil
+	}
+
+	return "", fmt.Errorf("transaction %s is not in block %s", hash, blockHash)
+}
+
+// ParseTransaction returns the *types.Transaction of a transaction
+// that is not in a block, like a mempool transaction. The coins
+// spent by the transaction are used to hydrate its inputs.
+func (b *Client) ParseTransaction(
+	ctx context.Context,
+	transaction *Transaction,
+	coins map[string]*types.AccountCoin,
+) (*types.Transaction, error) {
+	if transaction == nil {
+		return nil, errors.New("error parsing nil transaction")
+	}
+
+	// Transactions outside of blocks are never coinbase
+	// transactions, which are only the first transaction
+	// of a block.
+	txOps, err := b.parseTxOperations(transaction, -1, coins)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error parsing transaction operations", err)
+	}
+
+	metadata, err := transaction.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get metadata for transaction", err)
+	}
+
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: transaction.Hash,
+		},
+		Operations: txOps,
+		Metadata:   metadata,
+	}, nil
+}
+
 // getPeerInfo performs the `getpeerinfo` JSON-RPC request
 func (b *Client) getPeerInfo(
 	ctx context.Context,
@@ -878,9 +1036,17 @@ func (b *Client) post(
 	}
 	defer res.Body.Close()
 
-	// We expect JSON-RPC responses to return `200 OK` statuses
+	// We expect JSON-RPC responses to return `200 OK` statuses,
+	// but bitcoind returns RPC errors with error statuses
+	// (like `500 Internal Server Error`).
 	if res.StatusCode != http.StatusOK {
 		val, _ := ioutil.ReadAll(res.Body)
+		if json.Unmarshal(val, response) == nil {
+			if err := response.Err(); err != nil {
+				return err
+			}
+		}
+
 		return fmt.Errorf("invalid response: %s %s", res.Status, string(val))
 	}
 
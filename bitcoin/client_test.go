@@ -15,7 +15,9 @@
 package bitcoin
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -24,6 +26,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/btcsuite/btcd/wire"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -1345,6 +1348,88 @@ func TestSuggestedFeeRate(t *testing.T) {
 	}
 }
 
+func TestEstimateFee(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedRate  float64
+		expectedError error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("estimate_fee.json"),
+					url:    url,
+				},
+			},
+			expectedRate: float64(0.01),
+		},
+		"insufficient data": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("insufficient_estimate_fee.json"),
+					url:    url,
+				},
+			},
+			expectedRate: float64(-1),
+		},
+		"invalid range error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("invalid_estimate_fee.json"),
+					url:    url,
+				},
+			},
+			expectedError: errors.New("error getting fee estimate"),
+		},
+		"500 error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			rate, err := client.EstimateFee(context.Background(), 1)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedRate, rate)
+			}
+		})
+	}
+}
+
 func TestRawMempool(t *testing.T) {
 	tests := map[string]struct {
 		responses []responseFixture
@@ -1411,6 +1496,402 @@ func TestRawMempool(t *testing.T) {
 	}
 }
 
+func TestSendRawTransaction(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedHash  string
+		expectedError error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("send_raw_transaction_response.json"),
+					url:    url,
+				},
+			},
+			expectedHash: "e3c281a98475ea6e06a5757ba7726204552b2a782a78c23cd298fbb61fdc04d1",
+		},
+		"rpc error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   loadFixture("send_raw_transaction_missing_inputs_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: &RPCError{
+				Code:    RPCVerifyErrCode,
+				Message: "Missing inputs",
+			},
+		},
+		"500 error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			hash, err := client.SendRawTransaction(context.Background(), "00")
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+
+				var rpcError *RPCError
+				if expected, ok := test.expectedError.(*RPCError); ok {
+					assert.True(errors.As(err, &rpcError))
+					assert.Equal(expected, rpcError)
+					assert.True(errors.Is(err, ErrJSONRPCError))
+				} else {
+					assert.False(errors.As(err, &rpcError))
+				}
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedHash, hash)
+			}
+		})
+	}
+}
+
+func TestGetRawTransaction(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedTransaction *Transaction
+		expectedError       error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_raw_transaction_response.json"),
+					url:    url,
+				},
+			},
+			expectedTransaction: mempoolTransaction(),
+		},
+		"not found": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   loadFixture("get_raw_transaction_not_found_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: &RPCError{
+				Code:    blockNotFoundErrCode,
+				Message: "No such mempool transaction. Use -txindex to enable blockchain transaction queries. Use gettransaction for wallet transactions.",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			tx, err := client.GetRawTransaction(
+				context.Background(),
+				"2f3b5a9ad4a2bd47a6e7c3b2b3c9a4f2b2e1a3c4d5e6f708192a3b4c5d6e7f80",
+			)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+
+				var rpcError *RPCError
+				assert.True(errors.As(err, &rpcError))
+				assert.Equal(test.expectedError, rpcError)
+			} else {
+				assert.NoError(err)
+				tx.Hex = ""
+				assert.Equal(test.expectedTransaction, tx)
+			}
+		})
+	}
+}
+
+func TestGetRawBlockTransaction(t *testing.T) {
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: wire.MaxPrevOutIndex}, []byte{0x51}, nil))
+	coinbase.AddTxOut(wire.NewTxOut(1000000000000, []byte{0x51}))
+	spend := wire.NewMsgTx(1)
+	spend.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: coinbase.TxHash()}, []byte{0x51}, nil))
+	spend.AddTxOut(wire.NewTxOut(500000000000, []byte{0x51}))
+
+	block := wire.NewMsgBlock(&wire.BlockHeader{Version: 1})
+	assert.NoError(t, block.AddTransaction(coinbase))
+	assert.NoError(t, block.AddTransaction(spend))
+	blockBuf := new(bytes.Buffer)
+	assert.NoError(t, block.Serialize(blockBuf))
+	spendBuf := new(bytes.Buffer)
+	assert.NoError(t, spend.Serialize(spendBuf))
+
+	blockHash := block.BlockHash().String()
+	blockBody := fmt.Sprintf(`{"result":"%s","error":null,"id":"curltest"}`, hex.EncodeToString(blockBuf.Bytes()))
+	tests := map[string]struct {
+		hash string
+
+		expectedTransaction string
+		expectedError       error
+	}{
+		"successful": {
+			hash:                spend.TxHash().String(),
+			expectedTransaction: hex.EncodeToString(spendBuf.Bytes()),
+		},
+		"not in block": {
+			hash:          "2f3b5a9ad4a2bd47a6e7c3b2b3c9a4f2b2e1a3c4d5e6f708192a3b4c5d6e7f80",
+			expectedError: errors.New("is not in block"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(url, r.URL.RequestURI())
+
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintln(w, blockBody)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			tx, err := client.GetRawBlockTransaction(context.Background(), blockHash, test.hash)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedTransaction, tx)
+			}
+		})
+	}
+}
+
+func mempoolTransaction() *Transaction {
+	return &Transaction{
+		Hash:     "2f3b5a9ad4a2bd47a6e7c3b2b3c9a4f2b2e1a3c4d5e6f708192a3b4c5d6e7f80",
+		Size:     225,
+		Vsize:    225,
+		Version:  1,
+		Locktime: 0,
+		Weight:   900,
+		Inputs: []*Input{
+			{
+				TxHash: "0c9c4e2d5b6b8a1f2f0a4bdf543c8c1a9e8a4be8865e2e6d5c7e3a8c6bb07aa3",
+				Vout:   1,
+				ScriptSig: &ScriptSig{
+					ASM: "304402206d4d3bdb1b6a1c0e3c5f0a4f2c7d1e5b6a9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f02203a5b7c9d1e3f5a7b9c1d3e5f7a9b1c3d5e7f9a1b3c5d7e9f1a3b5c7d9e1f3a5b[ALL] 0311b2b4e1c9b8a7f6e5d4c3b2a1908f7e6d5c4b3a2918f7e6d5c4b3a2918f7e6d5",
+					Hex: "47304402206d4d3bdb1b6a1c0e3c5f0a4f2c7d1e5b6a9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f02203a5b7c9d1e3f5a7b9c1d3e5f7a9b1c3d5e7f9a1b3c5d7e9f1a3b5c7d9e1f3a5b01210311b2b4e1c9b8a7f6e5d4c3b2a1908f7e6d5c4b3a2918f7e6d5c4b3a2918f7e6d5",
+				},
+				Sequence: 4294967295,
+			},
+		},
+		Outputs: []*Output{
+			{
+				Value: 1,
+				Index: 0,
+				ScriptPubKey: &ScriptPubKey{
+					ASM:          "OP_DUP OP_HASH160 61263b081bf62c04642bf0f90fffbb94248f7c26 OP_EQUALVERIFY OP_CHECKSIG",
+					Hex:          "76a91461263b081bf62c04642bf0f90fffbb94248f7c2688ac",
+					RequiredSigs: 1,
+					Type:         "pubkeyhash",
+					Addresses:    []string{"DDzmtB41R1dNRLst99jciLWhKVmYw1yEEY"},
+				},
+			},
+			{
+				Value: 0.5,
+				Index: 1,
+				ScriptPubKey: &ScriptPubKey{
+					ASM:          "OP_DUP OP_HASH160 a60e695fe410bc4878d8192de88853fd397c27a3 OP_EQUALVERIFY OP_CHECKSIG",
+					Hex:          "76a914a60e695fe410bc4878d8192de88853fd397c27a388ac",
+					RequiredSigs: 1,
+					Type:         "pubkeyhash",
+					Addresses:    []string{"DLH7ySRbNFkUfSrBVxhZ1FRruNEE3rxKsS"},
+				},
+			},
+		},
+	}
+}
+
+func TestParseTransaction(t *testing.T) {
+	inputCoin := "0c9c4e2d5b6b8a1f2f0a4bdf543c8c1a9e8a4be8865e2e6d5c7e3a8c6bb07aa3:1"
+	tests := map[string]struct {
+		coins map[string]*types.AccountCoin
+
+		expectedTransaction *types.Transaction
+		expectedError       error
+	}{
+		"successful": {
+			coins: map[string]*types.AccountCoin{
+				inputCoin: {
+					Account: &types.AccountIdentifier{
+						Address: "D7hTxD1d8J2XTNfa9NvDZk6xzq5UsKGVjb",
+					},
+					Coin: &types.Coin{
+						CoinIdentifier: &types.CoinIdentifier{Identifier: inputCoin},
+						Amount: &types.Amount{
+							Value:    "160000000",
+							Currency: MainnetCurrency,
+						},
+					},
+				},
+			},
+			expectedTransaction: &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{
+					Hash: "2f3b5a9ad4a2bd47a6e7c3b2b3c9a4f2b2e1a3c4d5e6f708192a3b4c5d6e7f80",
+				},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        0,
+							NetworkIndex: int64Pointer(0),
+						},
+						Type:   InputOpType,
+						Status: types.String(SuccessStatus),
+						Account: &types.AccountIdentifier{
+							Address: "D7hTxD1d8J2XTNfa9NvDZk6xzq5UsKGVjb",
+						},
+						Amount: &types.Amount{
+							Value:    "-160000000",
+							Currency: MainnetCurrency,
+						},
+						CoinChange: &types.CoinChange{
+							CoinIdentifier: &types.CoinIdentifier{Identifier: inputCoin},
+							CoinAction:     types.CoinSpent,
+						},
+						Metadata: mustMarshalMap(&OperationMetadata{
+							ScriptSig: mempoolTransaction().Inputs[0].ScriptSig,
+							Sequence:  4294967295,
+						}),
+					},
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        1,
+							NetworkIndex: int64Pointer(0),
+						},
+						Type:   OutputOpType,
+						Status: types.String(SuccessStatus),
+						Account: &types.AccountIdentifier{
+							Address: "DDzmtB41R1dNRLst99jciLWhKVmYw1yEEY",
+						},
+						Amount: &types.Amount{
+							Value:    "100000000",
+							Currency: MainnetCurrency,
+						},
+						CoinChange: &types.CoinChange{
+							CoinIdentifier: &types.CoinIdentifier{
+								Identifier: "2f3b5a9ad4a2bd47a6e7c3b2b3c9a4f2b2e1a3c4d5e6f708192a3b4c5d6e7f80:0",
+							},
+							CoinAction: types.CoinCreated,
+						},
+						Metadata: mustMarshalMap(&OperationMetadata{
+							ScriptPubKey: mempoolTransaction().Outputs[0].ScriptPubKey,
+						}),
+					},
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        2,
+							NetworkIndex: int64Pointer(1),
+						},
+						Type:   OutputOpType,
+						Status: types.String(SuccessStatus),
+						Account: &types.AccountIdentifier{
+							Address: "DLH7ySRbNFkUfSrBVxhZ1FRruNEE3rxKsS",
+						},
+						Amount: &types.Amount{
+							Value:    "50000000",
+							Currency: MainnetCurrency,
+						},
+						CoinChange: &types.CoinChange{
+							CoinIdentifier: &types.CoinIdentifier{
+								Identifier: "2f3b5a9ad4a2bd47a6e7c3b2b3c9a4f2b2e1a3c4d5e6f708192a3b4c5d6e7f80:1",
+							},
+							CoinAction: types.CoinCreated,
+						},
+						Metadata: mustMarshalMap(&OperationMetadata{
+							ScriptPubKey: mempoolTransaction().Outputs[1].ScriptPubKey,
+						}),
+					},
+				},
+				Metadata: mustMarshalMap(&TransactionMetadata{
+					Size:    225,
+					Version: 1,
+					Vsize:   225,
+					Weight:  900,
+				}),
+			},
+		},
+		"missing input coin": {
+			coins:         map[string]*types.AccountCoin{},
+			expectedError: errors.New("error finding previous tx"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			client := NewClient(url, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			tx, err := client.ParseTransaction(context.Background(), mempoolTransaction(), test.coins)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedTransaction, tx)
+			}
+		})
+	}
+}
+
 // loadFixture takes a file name and returns the response fixture.
 func loadFixture(fileName string) string {
 	content, err := ioutil.ReadFile(fmt.Sprintf("client_fixtures/%s", fileName))
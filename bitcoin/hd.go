@@ -0,0 +1,199 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitcoin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+const (
+	// BIP44Purpose is the purpose of BIP44 derivation paths.
+	BIP44Purpose = uint32(44)
+
+	// ExternalChain and InternalChain are the change levels
+	// of BIP44 derivation paths for receiving and change
+	// addresses.
+	ExternalChain = uint32(0)
+	InternalChain = uint32(1)
+
+	// accountDepth is the depth of a BIP44 account key
+	// (m/44'/coin_type'/account').
+	accountDepth = 3
+
+	// bip44PathLength is the number of levels of a BIP44
+	// path (m/44'/coin_type'/account'/change/address_index).
+	bip44PathLength = 5
+
+	// childNumOffset is the offset of the child number
+	// in a serialized extended key.
+	childNumOffset = 9
+)
+
+// ParseExtendedPublicKey decodes a base58 encoded extended
+// public key (dgub on mainnet, tpub on testnet) of the
+// network described by chainParams.
+func ParseExtendedPublicKey(
+	chainParams *chaincfg.Params,
+	key string,
+) (*hdkeychain.ExtendedKey, error) {
+	extendedKey, err := hdkeychain.NewKeyFromString(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w unable to decode extended key", err)
+	}
+
+	if extendedKey.IsPrivate() {
+		return nil, errors.New("extended key is private")
+	}
+
+	if !extendedKey.IsForNet(chainParams) {
+		return nil, fmt.Errorf("extended key is not for %s", chainParams.Name)
+	}
+
+	return extendedKey, nil
+}
+
+// ParseDerivationPath parses a derivation path such as
+// m/44'/3'/0'/0/1. Hardened levels are marked with ' or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	levels := strings.Split(path, "/")
+	if len(levels) < 2 || levels[0] != "m" { // nolint:gomnd
+		return nil, fmt.Errorf("derivation path %s must start with m/", path)
+	}
+
+	parsed := make([]uint32, len(levels)-1)
+	for i, level := range levels[1:] {
+		hardened := strings.HasSuffix(level, "'") || strings.HasSuffix(level, "h")
+		if hardened {
+			level = level[:len(level)-1]
+		}
+
+		index, err := strconv.ParseUint(level, 10, 31) // nolint:gomnd
+		if err != nil {
+			return nil, fmt.Errorf("%w invalid level %d of derivation path %s", err, i+1, path)
+		}
+
+		parsed[i] = uint32(index)
+		if hardened {
+			parsed[i] += hdkeychain.HardenedKeyStart
+		}
+	}
+
+	return parsed, nil
+}
+
+// extendedKeyChildNum returns the child number
+// of an extended key.
+func extendedKeyChildNum(extendedKey *hdkeychain.ExtendedKey) uint32 {
+	decoded := base58.Decode(extendedKey.String())
+	return binary.BigEndian.Uint32(decoded[childNumOffset : childNumOffset+4])
+}
+
+// DeriveBIP44Key derives the key at a BIP44 path from the
+// extended public key of the account in the path. Only the
+// change and address index levels, which are not hardened,
+// are derived.
+func DeriveBIP44Key(
+	chainParams *chaincfg.Params,
+	accountKey *hdkeychain.ExtendedKey,
+	path []uint32,
+) (*hdkeychain.ExtendedKey, error) {
+	if len(path) != bip44PathLength {
+		return nil, fmt.Errorf("bip44 paths have %d levels, not %d", bip44PathLength, len(path))
+	}
+
+	if path[0] != BIP44Purpose+hdkeychain.HardenedKeyStart {
+		return nil, errors.New("bip44 paths must start with m/44'")
+	}
+
+	if path[1] != chainParams.HDCoinType+hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf(
+			"coin type of %s is %d'",
+			chainParams.Name,
+			chainParams.HDCoinType,
+		)
+	}
+
+	if accountKey.Depth() != accountDepth || extendedKeyChildNum(accountKey) != path[2] {
+		return nil, errors.New("extended key is not the key of the account in the path")
+	}
+
+	if path[3] != ExternalChain && path[3] != InternalChain {
+		return nil, fmt.Errorf("change level %d must be 0 or 1", path[3])
+	}
+
+	if path[4] >= hdkeychain.HardenedKeyStart {
+		return nil, errors.New("address index must not be hardened")
+	}
+
+	return DeriveChildKey(accountKey, path[3], path[4])
+}
+
+// DeriveChildKey derives the key at chain/index
+// from the extended public key of an account.
+func DeriveChildKey(
+	accountKey *hdkeychain.ExtendedKey,
+	chain uint32,
+	index uint32,
+) (*hdkeychain.ExtendedKey, error) {
+	chainKey, err := accountKey.Child(chain)
+	if err != nil {
+		return nil, fmt.Errorf("%w unable to derive chain %d", err, chain)
+	}
+
+	key, err := chainKey.Child(index)
+	if err != nil {
+		return nil, fmt.Errorf("%w unable to derive index %d", err, index)
+	}
+
+	return key, nil
+}
+
+// DeriveAddress returns the P2PKH address at chain/index
+// of the extended public key of an account.
+func DeriveAddress(
+	chainParams *chaincfg.Params,
+	accountKey *hdkeychain.ExtendedKey,
+	chain uint32,
+	index uint32,
+) (string, error) {
+	key, err := DeriveChildKey(accountKey, chain, index)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return "", fmt.Errorf("%w unable to get public key", err)
+	}
+
+	addr, err := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		chainParams,
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w unable to create address", err)
+	}
+
+	return addr.EncodeAddress(), nil
+}
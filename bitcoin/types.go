@@ -56,6 +56,10 @@ const (
 	// Coinbase.
 	CoinbaseOpType = "COINBASE"
 
+	// DataOpType is used to describe
+	// OP_RETURN data outputs.
+	DataOpType = "DATA"
+
 	// SuccessStatus is the status of all
 	// Bitcoin operations because anything
 	// on-chain is considered successful.
@@ -75,6 +79,24 @@ const (
 	// as the ScriptPubKey.Type for OP_RETURN
 	// locking scripts.
 	NullData = "nulldata"
+
+	// TrackedPending is the status of a tracked
+	// transaction that is not yet in a block.
+	TrackedPending = "pending"
+
+	// TrackedConfirmed is the status of a tracked
+	// transaction included in a block.
+	TrackedConfirmed = "confirmed"
+
+	// TrackedConflicted is the status of a tracked
+	// transaction with an input spent by another
+	// transaction included in a block.
+	TrackedConflicted = "conflicted"
+
+	// TrackedFailed is the status of a tracked
+	// transaction bitcoind will never accept,
+	// which is no longer rebroadcast.
+	TrackedFailed = "failed"
 )
 
 // Fee estimate constants
@@ -121,6 +143,7 @@ var (
 		InputOpType,
 		OutputOpType,
 		CoinbaseOpType,
+		DataOpType,
 	}
 
 	// OperationStatuses are all supported operation.Status.
@@ -147,6 +170,57 @@ type ScriptPubKey struct {
 	Addresses    []string `json:"addresses,omitempty"`
 }
 
+// TrackedTransaction is a transaction submitted through
+// ConstructionSubmit that is rebroadcast until it is
+// included in a block, one of its inputs is spent by a
+// conflicting transaction or bitcoind rejects it. Times
+// are unix times in nanoseconds.
+type TrackedTransaction struct {
+	Hash        string   `json:"hash"`
+	Transaction string   `json:"transaction"`
+	Inputs      []string `json:"inputs"`
+	Status      string   `json:"status"`
+
+	// Block is the block the transaction or the
+	// conflicting transaction is included in, or
+	// the head block when the transaction failed.
+	Block                  *types.BlockIdentifier `json:"block,omitempty"`
+	ConflictingTransaction string                 `json:"conflicting_transaction,omitempty"`
+
+	SubmittedAt   int64  `json:"submitted_at"`
+	Broadcasts    int64  `json:"broadcasts"`
+	LastBroadcast int64  `json:"last_broadcast"`
+	NextBroadcast int64  `json:"next_broadcast"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+// WatchOnlyAccount is an extended public key registered with
+// the indexer. Addresses are derived on its external (receiving)
+// and internal (change) chains until GapLimit addresses after the
+// last used one are unused.
+type WatchOnlyAccount struct {
+	ExtendedKey string          `json:"extended_key"`
+	GapLimit    int64           `json:"gap_limit"`
+	External    *WatchOnlyChain `json:"external"`
+	Internal    *WatchOnlyChain `json:"internal"`
+}
+
+// WatchOnlyChain is a chain of addresses derived from a
+// WatchOnlyAccount. LastUsed is the index of the last address
+// that received or spent coins, -1 if none did.
+type WatchOnlyChain struct {
+	Addresses []string `json:"addresses"`
+	LastUsed  int64    `json:"last_used"`
+}
+
+// MempoolCoins are the coins of accounts created and spent
+// by transactions in the mempool. Coins created and spent
+// within the mempool are in neither list.
+type MempoolCoins struct {
+	Created []*types.Coin `json:"created"`
+	Spent   []*types.Coin `json:"spent"`
+}
+
 // ScriptSig is a script on the input operations of a
 // Bitcoin transaction that satisfies the ScriptPubKey
 // on an output being spent.
@@ -296,6 +370,11 @@ type Transaction struct {
 
 	Inputs  []*Input  `json:"vin"`
 	Outputs []*Output `json:"vout"`
+
+	// BlockHash and Confirmations are only returned by
+	// getrawtransaction for transactions in a block.
+	BlockHash     string `json:"blockhash,omitempty"`
+	Confirmations int64  `json:"confirmations,omitempty"`
 }
 
 // Metadata returns the metadata for a transaction.
@@ -396,15 +475,57 @@ type jSONRPCResponse interface {
 	Err() error
 }
 
-type responseError struct {
+// RPCError is the error of a JSON-RPC response.
+type RPCError struct {
 	Code    int64  `json:"code"`
 	Message string `json:"message"`
 }
 
+// Error returns the code and message of the RPCError.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf(
+		"%s: error JSON RPC response, code: %d, message: %s",
+		ErrJSONRPCError,
+		e.Code,
+		e.Message,
+	)
+}
+
+// Unwrap returns ErrJSONRPCError so every RPCError
+// matches it with errors.Is.
+func (e *RPCError) Unwrap() error {
+	return ErrJSONRPCError
+}
+
+// ConfirmedTransactionError is returned when a transaction looked
+// up in the mempool is already included in a block, which may not
+// be indexed yet.
+type ConfirmedTransactionError struct {
+	Hash          string
+	Block         *types.BlockIdentifier
+	Confirmations int64
+}
+
+// Error returns the hash of the transaction and of its block.
+func (e *ConfirmedTransactionError) Error() string {
+	return fmt.Sprintf("transaction %s is confirmed in block %s", e.Hash, e.Block.Hash)
+}
+
+// HistoryNotIndexedError is returned when a transaction search
+// reaches blocks added before the address index was enabled.
+type HistoryNotIndexedError struct {
+	Start int64
+}
+
+// Error returns the index of the first block in the address index.
+func (e *HistoryNotIndexedError) Error() string {
+	return fmt.Sprintf("address index starts at block %d", e.Start)
+}
+
 // stringResponse is the response body for requests (with verbosity == 0)
 type stringResponse struct {
-	Result string         `json:"result"`
-	Error  *responseError `json:"error"`
+	Result string    `json:"result"`
+	Error  *RPCError `json:"error"`
 }
 
 func (b stringResponse) Err() error {
@@ -416,18 +537,13 @@ func (b stringResponse) Err() error {
 		return ErrBlockNotFound
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		b.Error.Code,
-		b.Error.Message,
-	)
+	return b.Error
 }
 
 // blockResponse is the response body for `getblock` requests (verbosity == 1)
 type blockResponse struct {
-	Result *Block         `json:"result"`
-	Error  *responseError `json:"error"`
+	Result *Block    `json:"result"`
+	Error  *RPCError `json:"error"`
 }
 
 func (b blockResponse) Err() error {
@@ -439,17 +555,12 @@ func (b blockResponse) Err() error {
 		return ErrBlockNotFound
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		b.Error.Code,
-		b.Error.Message,
-	)
+	return b.Error
 }
 
 type pruneBlockchainResponse struct {
-	Result int64          `json:"result"`
-	Error  *responseError `json:"error"`
+	Result int64     `json:"result"`
+	Error  *RPCError `json:"error"`
 }
 
 func (p pruneBlockchainResponse) Err() error {
@@ -457,17 +568,12 @@ func (p pruneBlockchainResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		p.Error.Code,
-		p.Error.Message,
-	)
+	return p.Error
 }
 
 type blockchainInfoResponse struct {
 	Result *BlockchainInfo `json:"result"`
-	Error  *responseError  `json:"error"`
+	Error  *RPCError       `json:"error"`
 }
 
 func (b blockchainInfoResponse) Err() error {
@@ -475,17 +581,12 @@ func (b blockchainInfoResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		b.Error.Code,
-		b.Error.Message,
-	)
+	return b.Error
 }
 
 type peerInfoResponse struct {
-	Result []*PeerInfo    `json:"result"`
-	Error  *responseError `json:"error"`
+	Result []*PeerInfo `json:"result"`
+	Error  *RPCError   `json:"error"`
 }
 
 func (p peerInfoResponse) Err() error {
@@ -493,18 +594,13 @@ func (p peerInfoResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		p.Error.Code,
-		p.Error.Message,
-	)
+	return p.Error
 }
 
 // blockHashResponse is the response body for `getblockhash` requests
 type blockHashResponse struct {
-	Result string         `json:"result"`
-	Error  *responseError `json:"error"`
+	Result string    `json:"result"`
+	Error  *RPCError `json:"error"`
 }
 
 func (b blockHashResponse) Err() error {
@@ -512,18 +608,13 @@ func (b blockHashResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		b.Error.Code,
-		b.Error.Message,
-	)
+	return b.Error
 }
 
 // decodeTransactionResponse is the response body for `decoderawtransaction` requests
 type decodeTransactionResponse struct {
-	Result *Transaction   `json:"result"`
-	Error  *responseError `json:"error"`
+	Result *Transaction `json:"result"`
+	Error  *RPCError    `json:"error"`
 }
 
 func (b decodeTransactionResponse) Err() error {
@@ -531,18 +622,13 @@ func (b decodeTransactionResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		b.Error.Code,
-		b.Error.Message,
-	)
+	return b.Error
 }
 
 // sendRawTransactionResponse is the response body for `sendrawtransaction` requests
 type sendRawTransactionResponse struct {
-	Result string         `json:"result"`
-	Error  *responseError `json:"error"`
+	Result string    `json:"result"`
+	Error  *RPCError `json:"error"`
 }
 
 func (s sendRawTransactionResponse) Err() error {
@@ -550,12 +636,7 @@ func (s sendRawTransactionResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		s.Error.Code,
-		s.Error.Message,
-	)
+	return s.Error
 }
 
 type suggestedFeeRate struct {
@@ -565,7 +646,7 @@ type suggestedFeeRate struct {
 // suggestedFeeRateResponse is the response body for `estimatesmartfee` requests
 type suggestedFeeRateResponse struct {
 	Result *suggestedFeeRate `json:"result"`
-	Error  *responseError    `json:"error"`
+	Error  *RPCError         `json:"error"`
 }
 
 func (s suggestedFeeRateResponse) Err() error {
@@ -573,18 +654,27 @@ func (s suggestedFeeRateResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		s.Error.Code,
-		s.Error.Message,
-	)
+	return s.Error
+}
+
+// estimateFeeResponse is the response body for `estimatefee` requests
+type estimateFeeResponse struct {
+	Result float64   `json:"result"`
+	Error  *RPCError `json:"error"`
+}
+
+func (e estimateFeeResponse) Err() error {
+	if e.Error == nil {
+		return nil
+	}
+
+	return e.Error
 }
 
 // rawMempoolResponse is the response body for `getrawmempool` requests.
 type rawMempoolResponse struct {
-	Result []string       `json:"result"`
-	Error  *responseError `json:"error"`
+	Result []string  `json:"result"`
+	Error  *RPCError `json:"error"`
 }
 
 func (r rawMempoolResponse) Err() error {
@@ -592,12 +682,21 @@ func (r rawMempoolResponse) Err() error {
 		return nil
 	}
 
-	return fmt.Errorf(
-		"%w: error JSON RPC response, code: %d, message: %s",
-		ErrJSONRPCError,
-		r.Error.Code,
-		r.Error.Message,
-	)
+	return r.Error
+}
+
+// rawTransactionResponse is the response body for `getrawtransaction` requests.
+type rawTransactionResponse struct {
+	Result *Transaction `json:"result"`
+	Error  *RPCError    `json:"error"`
+}
+
+func (r rawTransactionResponse) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+
+	return r.Error
 }
 
 // CoinIdentifier converts a tx hash and vout into
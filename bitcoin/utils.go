@@ -15,13 +15,17 @@
 package bitcoin
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
@@ -68,3 +72,117 @@ func ParseSingleAddress(
 
 	return class, address, nil
 }
+
+// ParseMultisigScript extracts the public keys and the number
+// of required signatures from a multisig redeem script.
+func ParseMultisigScript(
+	chainParams *chaincfg.Params,
+	script []byte,
+) ([]*btcutil.AddressPubKey, int, error) {
+	class, addresses, nRequired, err := txscript.ExtractPkScriptAddrs(script, chainParams)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w unable to extract script addresses", err)
+	}
+
+	if class != txscript.MultiSigTy {
+		return nil, 0, fmt.Errorf("expecting multisig script, got %s", class)
+	}
+
+	pubKeys := make([]*btcutil.AddressPubKey, len(addresses))
+	for i, address := range addresses {
+		pubKey, ok := address.(*btcutil.AddressPubKey)
+		if !ok {
+			return nil, 0, fmt.Errorf("unexpected address type %T in multisig script", address)
+		}
+
+		pubKeys[i] = pubKey
+	}
+
+	return pubKeys, nRequired, nil
+}
+
+// DecodeScriptPubKey returns a script in the form of the
+// scriptPubKey of an output returned by bitcoind.
+func DecodeScriptPubKey(chainParams *chaincfg.Params, script []byte) *ScriptPubKey {
+	// Scripts that cannot be parsed are disassembled
+	// up to the first error, like bitcoind does.
+	asm, _ := txscript.DisasmString(script)
+
+	scriptPubKey := &ScriptPubKey{
+		ASM:  asm,
+		Hex:  hex.EncodeToString(script),
+		Type: txscript.NonStandardTy.String(),
+	}
+
+	class, addresses, nRequired, err := txscript.ExtractPkScriptAddrs(script, chainParams)
+	if err != nil {
+		return scriptPubKey
+	}
+
+	scriptPubKey.Type = class.String()
+	scriptPubKey.RequiredSigs = int64(nRequired)
+	for _, address := range addresses {
+		scriptPubKey.Addresses = append(scriptPubKey.Addresses, address.EncodeAddress())
+	}
+
+	return scriptPubKey
+}
+
+// DecodeTransaction returns a transaction in the form returned by
+// the `decoderawtransaction` RPC of bitcoind, without calling it.
+func DecodeTransaction(chainParams *chaincfg.Params, tx *wire.MsgTx) (*Transaction, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return nil, fmt.Errorf("%w: unable to serialize transaction", err)
+	}
+
+	size := int64(tx.SerializeSize())
+	transaction := &Transaction{
+		Hex:      hex.EncodeToString(buf.Bytes()),
+		Hash:     tx.TxHash().String(),
+		Size:     size,
+		Vsize:    size,
+		Version:  tx.Version,
+		Locktime: int64(tx.LockTime),
+		Weight:   size * weightMultiplier,
+		Inputs:   make([]*Input, len(tx.TxIn)),
+		Outputs:  make([]*Output, len(tx.TxOut)),
+	}
+
+	coinbase := len(tx.TxIn) == 1 &&
+		tx.TxIn[0].PreviousOutPoint.Index == math.MaxUint32 &&
+		tx.TxIn[0].PreviousOutPoint.Hash == chainhash.Hash{}
+	for i, txIn := range tx.TxIn {
+		input := &Input{
+			Sequence: int64(txIn.Sequence),
+		}
+
+		if coinbase {
+			input.Coinbase = hex.EncodeToString(txIn.SignatureScript)
+		} else {
+			asm, _ := txscript.DisasmString(txIn.SignatureScript)
+			input.TxHash = txIn.PreviousOutPoint.Hash.String()
+			input.Vout = int64(txIn.PreviousOutPoint.Index)
+			input.ScriptSig = &ScriptSig{
+				ASM: asm,
+				Hex: hex.EncodeToString(txIn.SignatureScript),
+			}
+		}
+
+		for _, witness := range txIn.Witness {
+			input.TxInWitness = append(input.TxInWitness, hex.EncodeToString(witness))
+		}
+
+		transaction.Inputs[i] = input
+	}
+
+	for i, txOut := range tx.TxOut {
+		transaction.Outputs[i] = &Output{
+			Value:        btcutil.Amount(txOut.Value).ToBTC(),
+			Index:        int64(i),
+			ScriptPubKey: DecodeScriptPubKey(chainParams, txOut.PkScript),
+		}
+	}
+
+	return transaction, nil
+}
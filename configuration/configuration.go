@@ -99,6 +99,49 @@ const (
 	// read to determine the port for the Rosetta
 	// implementation.
 	PortEnv = "PORT"
+
+	// FeeEstimatorEnv is the environment variable
+	// read to determine the fee estimator used in
+	// the Construction API.
+	FeeEstimatorEnv = "FEE_ESTIMATOR"
+
+	// StaticFeeRateEnv is the environment variable
+	// read to determine the fee rate (in coins per kB)
+	// used by the static fee estimator.
+	StaticFeeRateEnv = "STATIC_FEE_RATE"
+
+	// CoinReservationTTLEnv is the environment variable
+	// read to determine how long coins used in the
+	// Construction API stay reserved (reservations
+	// are disabled when unset or 0).
+	CoinReservationTTLEnv = "COIN_RESERVATION_TTL"
+
+	// AddressIndexEnv is the environment variable
+	// read to determine if the indexer maintains
+	// the address index used by /search/transactions.
+	AddressIndexEnv = "ADDRESS_INDEX"
+)
+
+// FeeEstimator is the setting that determines how
+// fee rates are estimated in the Construction API.
+type FeeEstimator string
+
+const (
+	// StaticFeeEstimator always suggests the fee
+	// rate provided in the configuration.
+	StaticFeeEstimator FeeEstimator = "STATIC"
+
+	// EstimateFeeEstimator suggests the fee rate
+	// returned by the `estimatefee` RPC.
+	EstimateFeeEstimator FeeEstimator = "ESTIMATEFEE"
+
+	// EstimateSmartFeeEstimator suggests the fee rate
+	// returned by the `estimatesmartfee` RPC.
+	EstimateSmartFeeEstimator FeeEstimator = "ESTIMATESMARTFEE"
+
+	// BlocksFeeEstimator suggests the fee rate paid
+	// by transactions in recently indexed blocks.
+	BlocksFeeEstimator FeeEstimator = "BLOCKS"
 )
 
 // PruningConfiguration is the configuration to
@@ -123,6 +166,10 @@ type Configuration struct {
 	IndexerPath            string
 	BitcoindPath           string
 	Compressors            []*encoder.CompressorEntry
+	FeeEstimator           FeeEstimator
+	StaticFeeRate          float64
+	CoinReservationTTL     time.Duration
+	AddressIndex           bool
 }
 
 // LoadConfiguration attempts to create a new Configuration
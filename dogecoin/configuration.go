@@ -156,6 +156,53 @@ func LoadConfiguration(baseDirectory string) (*configuration.Configuration, erro
 	}
 	config.Port = port
 
+	feeEstimatorValue := configuration.FeeEstimator(os.Getenv(configuration.FeeEstimatorEnv))
+	switch feeEstimatorValue {
+	case configuration.StaticFeeEstimator:
+		rateValue := os.Getenv(configuration.StaticFeeRateEnv)
+		rate, err := strconv.ParseFloat(rateValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse static fee rate %s", err, rateValue)
+		}
+
+		if rate <= 0 {
+			return nil, fmt.Errorf("static fee rate %s must be positive", rateValue)
+		}
+
+		config.FeeEstimator = feeEstimatorValue
+		config.StaticFeeRate = rate
+	case configuration.EstimateFeeEstimator,
+		configuration.EstimateSmartFeeEstimator,
+		configuration.BlocksFeeEstimator:
+		config.FeeEstimator = feeEstimatorValue
+	case "":
+		config.FeeEstimator = configuration.EstimateSmartFeeEstimator
+	default:
+		return nil, fmt.Errorf("%s is not a valid fee estimator", feeEstimatorValue)
+	}
+
+	if ttlValue := os.Getenv(configuration.CoinReservationTTLEnv); len(ttlValue) > 0 {
+		ttl, err := time.ParseDuration(ttlValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse coin reservation ttl %s", err, ttlValue)
+		}
+
+		if ttl < 0 {
+			return nil, fmt.Errorf("coin reservation ttl %s must not be negative", ttlValue)
+		}
+
+		config.CoinReservationTTL = ttl
+	}
+
+	if indexValue := os.Getenv(configuration.AddressIndexEnv); len(indexValue) > 0 {
+		addressIndex, err := strconv.ParseBool(indexValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse address index %s", err, indexValue)
+		}
+
+		config.AddressIndex = addressIndex
+	}
+
 	return config, nil
 }
 
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 
@@ -35,6 +36,13 @@ func TestLoadConfiguration(t *testing.T) {
 		Network string
 		Port    string
 
+		FeeEstimator  string
+		StaticFeeRate string
+
+		CoinReservationTTL string
+
+		AddressIndex string
+
 		cfg *configuration.Configuration
 		err error
 	}{
@@ -64,6 +72,7 @@ func TestLoadConfiguration(t *testing.T) {
 				Currency:               MainnetCurrency,
 				GenesisBlockIdentifier: MainnetGenesisBlockIdentifier,
 				Port:                   1000,
+				FeeEstimator:           configuration.EstimateSmartFeeEstimator,
 				RPCPort:                mainnetRPCPort,
 				ConfigPath:             defaultConfigurationDirectory + "/" + mainnetConfigFile,
 				Pruning: &configuration.PruningConfiguration{
@@ -93,6 +102,110 @@ func TestLoadConfiguration(t *testing.T) {
 				Currency:               TestnetCurrency,
 				GenesisBlockIdentifier: TestnetGenesisBlockIdentifier,
 				Port:                   1000,
+				FeeEstimator:           configuration.EstimateSmartFeeEstimator,
+				RPCPort:                testnetRPCPort,
+				ConfigPath:             defaultConfigurationDirectory + "/" + testnetConfigFile,
+				Pruning: &configuration.PruningConfiguration{
+					Frequency: pruneFrequency,
+					Depth:     pruneDepth,
+					MinHeight: minPruneHeight,
+				},
+				Compressors: []*encoder.CompressorEntry{
+					{
+						Namespace:      transactionNamespace,
+						DictionaryPath: defaultConfigurationDirectory + "/" + testnetTxDict,
+					},
+				},
+			},
+		},
+		"static fee estimator": {
+			Mode:    string(configuration.Online),
+			Network: configuration.Testnet,
+			Port:    "1000",
+
+			FeeEstimator:  string(configuration.StaticFeeEstimator),
+			StaticFeeRate: "0.02",
+
+			cfg: &configuration.Configuration{
+				Mode: configuration.Online,
+				Network: &types.NetworkIdentifier{
+					Network:    TestnetNetwork,
+					Blockchain: Blockchain,
+				},
+				Params:                 TestnetParams,
+				Currency:               TestnetCurrency,
+				GenesisBlockIdentifier: TestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				FeeEstimator:           configuration.StaticFeeEstimator,
+				StaticFeeRate:          0.02,
+				RPCPort:                testnetRPCPort,
+				ConfigPath:             defaultConfigurationDirectory + "/" + testnetConfigFile,
+				Pruning: &configuration.PruningConfiguration{
+					Frequency: pruneFrequency,
+					Depth:     pruneDepth,
+					MinHeight: minPruneHeight,
+				},
+				Compressors: []*encoder.CompressorEntry{
+					{
+						Namespace:      transactionNamespace,
+						DictionaryPath: defaultConfigurationDirectory + "/" + testnetTxDict,
+					},
+				},
+			},
+		},
+		"coin reservation ttl": {
+			Mode:    string(configuration.Online),
+			Network: configuration.Testnet,
+			Port:    "1000",
+
+			CoinReservationTTL: "90s",
+
+			cfg: &configuration.Configuration{
+				Mode: configuration.Online,
+				Network: &types.NetworkIdentifier{
+					Network:    TestnetNetwork,
+					Blockchain: Blockchain,
+				},
+				Params:                 TestnetParams,
+				Currency:               TestnetCurrency,
+				GenesisBlockIdentifier: TestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				FeeEstimator:           configuration.EstimateSmartFeeEstimator,
+				CoinReservationTTL:     90 * time.Second,
+				RPCPort:                testnetRPCPort,
+				ConfigPath:             defaultConfigurationDirectory + "/" + testnetConfigFile,
+				Pruning: &configuration.PruningConfiguration{
+					Frequency: pruneFrequency,
+					Depth:     pruneDepth,
+					MinHeight: minPruneHeight,
+				},
+				Compressors: []*encoder.CompressorEntry{
+					{
+						Namespace:      transactionNamespace,
+						DictionaryPath: defaultConfigurationDirectory + "/" + testnetTxDict,
+					},
+				},
+			},
+		},
+		"address index": {
+			Mode:    string(configuration.Online),
+			Network: configuration.Testnet,
+			Port:    "1000",
+
+			AddressIndex: "true",
+
+			cfg: &configuration.Configuration{
+				Mode: configuration.Online,
+				Network: &types.NetworkIdentifier{
+					Network:    TestnetNetwork,
+					Blockchain: Blockchain,
+				},
+				Params:                 TestnetParams,
+				Currency:               TestnetCurrency,
+				GenesisBlockIdentifier: TestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				FeeEstimator:           configuration.EstimateSmartFeeEstimator,
+				AddressIndex:           true,
 				RPCPort:                testnetRPCPort,
 				ConfigPath:             defaultConfigurationDirectory + "/" + testnetConfigFile,
 				Pruning: &configuration.PruningConfiguration{
@@ -126,6 +239,50 @@ func TestLoadConfiguration(t *testing.T) {
 			Port:    "bad port",
 			err:     errors.New("unable to parse port bad port"),
 		},
+		"invalid fee estimator": {
+			Mode:         string(configuration.Offline),
+			Network:      configuration.Testnet,
+			Port:         "1000",
+			FeeEstimator: "bad estimator",
+			err:          errors.New("bad estimator is not a valid fee estimator"),
+		},
+		"invalid static fee rate": {
+			Mode:          string(configuration.Offline),
+			Network:       configuration.Testnet,
+			Port:          "1000",
+			FeeEstimator:  string(configuration.StaticFeeEstimator),
+			StaticFeeRate: "-1",
+			err:           errors.New("static fee rate -1 must be positive"),
+		},
+		"unparsable static fee rate": {
+			Mode:          string(configuration.Offline),
+			Network:       configuration.Testnet,
+			Port:          "1000",
+			FeeEstimator:  string(configuration.StaticFeeEstimator),
+			StaticFeeRate: "cheap",
+			err:           errors.New("unable to parse static fee rate cheap"),
+		},
+		"invalid coin reservation ttl": {
+			Mode:               string(configuration.Offline),
+			Network:            configuration.Testnet,
+			Port:               "1000",
+			CoinReservationTTL: "forever",
+			err:                errors.New("unable to parse coin reservation ttl forever"),
+		},
+		"negative coin reservation ttl": {
+			Mode:               string(configuration.Offline),
+			Network:            configuration.Testnet,
+			Port:               "1000",
+			CoinReservationTTL: "-1m",
+			err:                errors.New("coin reservation ttl -1m must not be negative"),
+		},
+		"invalid address index": {
+			Mode:         string(configuration.Offline),
+			Network:      configuration.Testnet,
+			Port:         "1000",
+			AddressIndex: "sometimes",
+			err:          errors.New("unable to parse address index sometimes"),
+		},
 	}
 
 	for name, test := range tests {
@@ -137,6 +294,10 @@ func TestLoadConfiguration(t *testing.T) {
 			os.Setenv(configuration.ModeEnv, test.Mode)
 			os.Setenv(configuration.NetworkEnv, test.Network)
 			os.Setenv(configuration.PortEnv, test.Port)
+			os.Setenv(configuration.FeeEstimatorEnv, test.FeeEstimator)
+			os.Setenv(configuration.StaticFeeRateEnv, test.StaticFeeRate)
+			os.Setenv(configuration.CoinReservationTTLEnv, test.CoinReservationTTL)
+			os.Setenv(configuration.AddressIndexEnv, test.AddressIndex)
 
 			cfg, err := LoadConfiguration(newDir)
 			if test.err != nil {
@@ -42,6 +42,77 @@ const (
 	// TransactionHashLength is the length
 	// of any transaction hash in Dogecoin.
 	TransactionHashLength = 64
+
+	// MessageMagic is prepended to messages signed
+	// with the signmessage RPC of dogecoind.
+	MessageMagic = "Dogecoin Signed Message:\n"
+)
+
+// Transaction size constants used to estimate fees. Dogecoin
+// does not support segwit, so all sizes are in bytes.
+const (
+	// TransactionOverhead is the size of the version
+	// and lock time, excluding the input and output counts.
+	TransactionOverhead = 8 // 4 version, 4 lock time
+
+	// InputOverhead is the size of an input,
+	// excluding its signature script and script length.
+	InputOverhead = 40 // 32 prev hash, 4 prev index, 4 sequence
+
+	// OutputOverhead is the size of an output,
+	// excluding its script and script length.
+	OutputOverhead = 8 // 8 value
+
+	// SignaturePushSize is the size of a pushed signature.
+	SignaturePushSize = 73 // 1 push, <= 71 DER signature, 1 sighash type
+
+	// CompressedPubKeyPushSize is the size of a
+	// pushed compressed public key.
+	CompressedPubKeyPushSize = 34 // 1 push, 33 public key
+
+	// UncompressedPubKeyPushSize is the size of a
+	// pushed uncompressed public key.
+	UncompressedPubKeyPushSize = 66 // 1 push, 65 public key
+
+	// P2PKHScriptPubkeySize is the size of a P2PKH scriptPubKey.
+	P2PKHScriptPubkeySize = 25
+)
+
+// Fee policy constants of Dogecoin Core 1.14
+// Source: https://github.com/dogecoin/dogecoin/blob/v1.14.5/doc/fee-recommendation.md
+const (
+	// MinRelayFeeRate is the recommended minimum
+	// fee rate in DOGE per kB.
+	MinRelayFeeRate = float64(0.01) // nolint:gomnd
+
+	// MinRelayTxFeeRate is the fee rate in DOGE per kB
+	// below which transactions are not relayed.
+	MinRelayTxFeeRate = float64(0.001) // nolint:gomnd
+
+	// DustLimit is the soft dust limit in koinu. Every
+	// output below it adds DustLimit to the minimum fee
+	// of a transaction.
+	DustLimit = int64(1000000) // nolint:gomnd
+
+	// HardDustLimit is the hard dust limit in koinu.
+	// Outputs below it are not relayed.
+	HardDustLimit = int64(100000) // nolint:gomnd
+)
+
+// Standardness constants of Dogecoin Core 1.14
+// Source: https://github.com/dogecoin/dogecoin/blob/v1.14.5/src/script/standard.h
+const (
+	// MaxDataCarrierSize is the maximum number of bytes
+	// of data in a standard OP_RETURN output.
+	MaxDataCarrierSize = 80
+
+	// MaxStandardTxSize is the maximum size in
+	// bytes of a standard transaction.
+	MaxStandardTxSize = 100000
+
+	// MaxStandardScriptSigSize is the maximum size in
+	// bytes of a standard signature script.
+	MaxStandardScriptSigSize = 1650
 )
 
 var (
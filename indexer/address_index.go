@@ -0,0 +1,545 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// historyNamespace is prepended to the key of each
+	// entry of the address index, which maps accounts
+	// and coins to the transactions that touched them.
+	historyNamespace = "history"
+
+	accountHistory = "account"
+	coinHistory    = "coin"
+
+	// historySeekEnd sorts after the transaction
+	// hashes of the history keys of a block.
+	historySeekEnd = "~"
+
+	// historyStartKey stores the index of the first block
+	// added to the address index. Blocks synced before the
+	// index was enabled are not in it.
+	historyStartKey = "history-start"
+)
+
+var (
+	errMissingSearchCondition = errors.New(
+		"transaction_identifier, account_identifier, address or coin_identifier must be provided",
+	)
+)
+
+// historyEntry is a transaction in the history
+// of an account or coin.
+type historyEntry struct {
+	block *types.BlockIdentifier
+	hash  string
+}
+
+func getHistoryPrefix(kind string, key string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s/", historyNamespace, kind, key))
+}
+
+// getHistoryKey returns the key of a transaction in the history
+// of an account or coin. Block indexes are zero-padded so keys
+// are sorted by block.
+func getHistoryKey(
+	kind string,
+	key string,
+	block *types.BlockIdentifier,
+	hash string,
+) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%s/%020d/%s", historyNamespace, kind, key, block.Index, hash))
+}
+
+// getHistoryStart returns the index of the first block
+// in the address index, if any block was added to it.
+func getHistoryStart(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (int64, bool, error) {
+	exists, val, err := dbTx.Get(ctx, []byte(historyStartKey))
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to get address index start", err)
+	}
+
+	if !exists {
+		return -1, false, nil
+	}
+
+	start, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: invalid address index start %s", err, string(val))
+	}
+
+	return start, true, nil
+}
+
+// historyKeys returns the history keys of the
+// accounts and coins touched by a block.
+func historyKeys(block *types.Block) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for _, tx := range block.Transactions {
+		hash := tx.TransactionIdentifier.Hash
+		for _, op := range tx.Operations {
+			if op.Account != nil {
+				key := getHistoryKey(accountHistory, op.Account.Address, block.BlockIdentifier, hash)
+				keys[string(key)] = struct{}{}
+			}
+
+			if op.CoinChange != nil {
+				key := getHistoryKey(
+					coinHistory,
+					op.CoinChange.CoinIdentifier.Identifier,
+					block.BlockIdentifier,
+					hash,
+				)
+				keys[string(key)] = struct{}{}
+			}
+		}
+	}
+
+	return keys
+}
+
+// historyWorker is the modules.BlockWorker writing the
+// address index in the database transaction that adds or
+// removes a block, so the index never diverges from the
+// indexed blocks.
+type historyWorker struct{}
+
+var _ modules.BlockWorker = (*historyWorker)(nil)
+
+// AddingBlock adds the transactions of a
+// block to the address index.
+func (w *historyWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	_, exists, err := getHistoryStart(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		start := []byte(strconv.FormatInt(block.BlockIdentifier.Index, 10))
+		if err := dbTx.Set(ctx, []byte(historyStartKey), start, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store address index start", err)
+		}
+	}
+
+	for key := range historyKeys(block) {
+		if err := dbTx.Set(ctx, []byte(key), []byte(block.BlockIdentifier.Hash), true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store history key %s", err, key)
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock removes the transactions of
+// a block from the address index.
+func (w *historyWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	start, _, err := getHistoryStart(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The next block added starts the index again.
+	if start == block.BlockIdentifier.Index {
+		if err := dbTx.Delete(ctx, []byte(historyStartKey)); err != nil {
+			return nil, fmt.Errorf("%w: unable to delete address index start", err)
+		}
+	}
+
+	for key := range historyKeys(block) {
+		if err := dbTx.Delete(ctx, []byte(key)); err != nil {
+			return nil, fmt.Errorf("%w: unable to delete history key %s", err, key)
+		}
+	}
+
+	return nil, nil
+}
+
+// resetHistoryStart forgets the start of the address index,
+// so it starts again at the next block added once it is
+// enabled again. Blocks added in the meantime are not in it.
+func (i *Indexer) resetHistoryStart(ctx context.Context) error {
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	if err := dbTx.Delete(ctx, []byte(historyStartKey)); err != nil {
+		return fmt.Errorf("%w: unable to delete address index start", err)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit address index start", err)
+	}
+
+	return nil
+}
+
+// getHistory returns the transactions in the history of
+// an account or coin up to maxBlock, most recent first.
+func (i *Indexer) getHistory(
+	ctx context.Context,
+	dbTx database.Transaction,
+	kind string,
+	key string,
+	maxBlock int64,
+) ([]*historyEntry, error) {
+	prefix := getHistoryPrefix(kind, key)
+	seekStart := append(
+		append([]byte{}, prefix...),
+		[]byte(fmt.Sprintf("%020d/%s", maxBlock, historySeekEnd))...,
+	)
+
+	entries := []*historyEntry{}
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		seekStart,
+		func(k []byte, v []byte) error {
+			vals := strings.Split(strings.TrimPrefix(string(k), string(prefix)), "/")
+			if len(vals) != 2 { // nolint:gomnd
+				return fmt.Errorf("invalid history key %s", string(k))
+			}
+
+			index, err := strconv.ParseInt(vals[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: invalid history key %s", err, string(k))
+			}
+
+			entries = append(entries, &historyEntry{
+				block: &types.BlockIdentifier{
+					Index: index,
+					Hash:  string(v),
+				},
+				hash: vals[1],
+			})
+			return nil
+		},
+		false,
+		true,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan history of %s %s", err, kind, key)
+	}
+
+	return entries, nil
+}
+
+// searchCandidates returns the transactions matching the indexed
+// conditions of a search (transaction, account, address and coin)
+// from start to maxBlock, most recent first. With the AND operator,
+// transactions must match all of them, and with the OR operator, any
+// of them.
+func (i *Indexer) searchCandidates(
+	ctx context.Context,
+	dbTx database.Transaction,
+	request *types.SearchTransactionsRequest,
+	start int64,
+	maxBlock int64,
+) ([]*historyEntry, error) {
+
+	sets := [][]*historyEntry{}
+	if request.TransactionIdentifier != nil {
+		block, _, err := i.blockStorage.FindTransaction(ctx, request.TransactionIdentifier, dbTx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to find transaction", err)
+		}
+
+		set := []*historyEntry{}
+		if block != nil && block.Index >= start && block.Index <= maxBlock {
+			set = append(set, &historyEntry{block: block, hash: request.TransactionIdentifier.Hash})
+		}
+
+		sets = append(sets, set)
+	}
+
+	histories := [][2]string{}
+	if request.AccountIdentifier != nil {
+		histories = append(histories, [2]string{accountHistory, request.AccountIdentifier.Address})
+	}
+
+	if request.Address != nil {
+		histories = append(histories, [2]string{accountHistory, *request.Address})
+	}
+
+	if request.CoinIdentifier != nil {
+		histories = append(histories, [2]string{coinHistory, request.CoinIdentifier.Identifier})
+	}
+
+	for _, history := range histories {
+		entries, err := i.getHistory(ctx, dbTx, history[0], history[1], maxBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		// Entries below the start were left by an earlier
+		// run with the index enabled and may be stale.
+		set := []*historyEntry{}
+		for _, entry := range entries {
+			if entry.block.Index >= start {
+				set = append(set, entry)
+			}
+		}
+
+		sets = append(sets, set)
+	}
+
+	if len(sets) == 0 {
+		return nil, errMissingSearchCondition
+	}
+
+	counts := map[string]int{}
+	candidates := []*historyEntry{}
+	for _, set := range sets {
+		for _, entry := range set {
+			counts[entry.hash]++
+			if counts[entry.hash] == 1 {
+				candidates = append(candidates, entry)
+			}
+		}
+	}
+
+	if request.Operator == nil || *request.Operator == types.AND {
+		matching := []*historyEntry{}
+		for _, entry := range candidates {
+			if counts[entry.hash] == len(sets) {
+				matching = append(matching, entry)
+			}
+		}
+
+		candidates = matching
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].block.Index != candidates[b].block.Index {
+			return candidates[a].block.Index > candidates[b].block.Index
+		}
+
+		return candidates[a].hash > candidates[b].hash
+	})
+
+	return candidates, nil
+}
+
+// operationConditions returns the conditions
+// of a search that apply to operations.
+func operationConditions(
+	request *types.SearchTransactionsRequest,
+) []func(*types.Operation) bool {
+	conditions := []func(*types.Operation) bool{}
+	if request.AccountIdentifier != nil {
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.Account != nil && types.Hash(op.Account) == types.Hash(request.AccountIdentifier)
+		})
+	}
+
+	if request.Address != nil {
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.Account != nil && op.Account.Address == *request.Address
+		})
+	}
+
+	if request.CoinIdentifier != nil {
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.CoinChange != nil &&
+				op.CoinChange.CoinIdentifier.Identifier == request.CoinIdentifier.Identifier
+		})
+	}
+
+	if request.Currency != nil {
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.Amount != nil && types.Hash(op.Amount.Currency) == types.Hash(request.Currency)
+		})
+	}
+
+	if request.Status != nil {
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.Status != nil && *op.Status == *request.Status
+		})
+	}
+
+	if request.Type != nil {
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.Type == *request.Type
+		})
+	}
+
+	if request.Success != nil {
+		successful := map[string]bool{}
+		for _, status := range bitcoin.OperationStatuses {
+			successful[status.Status] = status.Successful
+		}
+
+		conditions = append(conditions, func(op *types.Operation) bool {
+			return op.Status != nil && successful[*op.Status] == *request.Success
+		})
+	}
+
+	return conditions
+}
+
+// matchesSearch returns whether a transaction matches a search. With
+// the AND operator, one of its operations must match all operation
+// conditions, and with the OR operator, any of them.
+func matchesSearch(
+	request *types.SearchTransactionsRequest,
+	tx *types.Transaction,
+) bool {
+	and := request.Operator == nil || *request.Operator == types.AND
+	hashMatches := request.TransactionIdentifier != nil &&
+		tx.TransactionIdentifier.Hash == request.TransactionIdentifier.Hash
+	if !and && hashMatches {
+		return true
+	}
+
+	if and && request.TransactionIdentifier != nil && !hashMatches {
+		return false
+	}
+
+	conditions := operationConditions(request)
+	if len(conditions) == 0 {
+		return and
+	}
+
+	for _, op := range tx.Operations {
+		matches := 0
+		for _, condition := range conditions {
+			if condition(op) {
+				matches++
+			}
+		}
+
+		if (and && matches == len(conditions)) || (!and && matches > 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SearchTransactions returns the transactions in the address index
+// matching a search, most recent first. Searches must include a
+// transaction, account, address or coin condition. Offsets refer to
+// the transactions matching these indexed conditions, which are only
+// hydrated (and checked against the other conditions) until the page
+// is full, so TotalCount is an upper bound of the matching transactions.
+//
+// When the address index was enabled after the genesis block was
+// synced, searches reaching the blocks added before it (up to a max
+// block below its start, or past the oldest match in the index) fail
+// with a *bitcoin.HistoryNotIndexedError.
+func (i *Indexer) SearchTransactions(
+	ctx context.Context,
+	request *types.SearchTransactionsRequest,
+) (*types.SearchTransactionsResponse, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	head, err := i.blockStorage.GetHeadBlockIdentifierTransactional(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	maxBlock := head.Index
+	if request.MaxBlock != nil {
+		maxBlock = *request.MaxBlock
+	}
+
+	start, exists, err := getHistoryStart(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		start = head.Index + 1
+	}
+
+	if maxBlock < start {
+		return nil, &bitcoin.HistoryNotIndexedError{Start: start}
+	}
+
+	candidates, err := i.searchCandidates(ctx, dbTx, request, start, maxBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.SearchTransactionsResponse{
+		Transactions: []*types.BlockTransaction{},
+		TotalCount:   int64(len(candidates)),
+	}
+
+	offset := int64(0)
+	if request.Offset != nil {
+		offset = *request.Offset
+	}
+
+	for index := offset; index < response.TotalCount; index++ {
+		if request.Limit != nil && int64(len(response.Transactions)) >= *request.Limit {
+			nextOffset := index
+			response.NextOffset = &nextOffset
+			break
+		}
+
+		candidate := candidates[index]
+		tx, err := i.blockStorage.GetBlockTransaction(
+			ctx,
+			candidate.block,
+			&types.TransactionIdentifier{Hash: candidate.hash},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get transaction %s", err, candidate.hash)
+		}
+
+		if matchesSearch(request, tx) {
+			response.Transactions = append(response.Transactions, &types.BlockTransaction{
+				BlockIdentifier: candidate.block,
+				Transaction:     tx,
+			})
+		}
+	}
+
+	// The last page of a search is only complete
+	// if the index starts at the genesis block.
+	if response.NextOffset == nil && start > i.genesisBlock.Index {
+		return nil, &bitcoin.HistoryNotIndexedError{Start: start}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,530 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/utils"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// broadcastNamespace is prepended to the
+	// key of each tracked transaction.
+	broadcastNamespace = "broadcast"
+
+	// rebroadcastFrequency is how often tracked
+	// transactions are checked for rebroadcast.
+	rebroadcastFrequency = 30 * time.Second
+
+	// rebroadcastBackoff is the delay before the first
+	// rebroadcast of a transaction. It doubles after
+	// every broadcast up to maxRebroadcastBackoff.
+	rebroadcastBackoff    = time.Minute
+	maxRebroadcastBackoff = time.Hour
+
+	// trackingDepth is the number of blocks a confirmed
+	// or conflicted transaction is tracked for, so it
+	// is rebroadcast if its block is orphaned.
+	trackingDepth = int64(100) // nolint:gomnd
+
+	// rejectInvalidPrefix prefixes the message of rejections
+	// with reject code 16 (REJECT_INVALID), returned for
+	// transactions that can never be valid.
+	rejectInvalidPrefix = "16:"
+)
+
+func getBroadcastPrefix() []byte {
+	return []byte(fmt.Sprintf("%s/", broadcastNamespace))
+}
+
+func getBroadcastKey(hash string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", broadcastNamespace, hash))
+}
+
+// nextBroadcast returns the time of the next broadcast
+// of a transaction already broadcast broadcasts times.
+func nextBroadcast(now time.Time, broadcasts int64) int64 {
+	backoff := rebroadcastBackoff
+	for j := int64(1); j < broadcasts && backoff < maxRebroadcastBackoff; j++ {
+		backoff *= 2
+	}
+
+	if backoff > maxRebroadcastBackoff {
+		backoff = maxRebroadcastBackoff
+	}
+
+	return now.Add(backoff).UnixNano()
+}
+
+// getTrackedTransaction returns the tracked transaction
+// with the provided hash or nil if it is not tracked.
+func (i *Indexer) getTrackedTransaction(
+	ctx context.Context,
+	dbTx database.Transaction,
+	hash string,
+) (*bitcoin.TrackedTransaction, error) {
+	exists, val, err := dbTx.Get(ctx, getBroadcastKey(hash))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get tracked transaction %s", err, hash)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	var tracked bitcoin.TrackedTransaction
+	if err := i.database.Encoder().Decode("", val, &tracked, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode tracked transaction %s", err, hash)
+	}
+
+	return &tracked, nil
+}
+
+// setTrackedTransaction stores a tracked transaction.
+func (i *Indexer) setTrackedTransaction(
+	ctx context.Context,
+	dbTx database.Transaction,
+	tracked *bitcoin.TrackedTransaction,
+) error {
+	encoded, err := i.database.Encoder().Encode("", tracked)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode tracked transaction %s", err, tracked.Hash)
+	}
+
+	if err := dbTx.Set(ctx, getBroadcastKey(tracked.Hash), encoded, false); err != nil {
+		return fmt.Errorf("%w: unable to store tracked transaction %s", err, tracked.Hash)
+	}
+
+	return nil
+}
+
+// getTrackedTransactions returns all tracked transactions.
+func (i *Indexer) getTrackedTransactions(
+	ctx context.Context,
+	dbTx database.Transaction,
+) ([]*bitcoin.TrackedTransaction, error) {
+	trackedTransactions := []*bitcoin.TrackedTransaction{}
+	_, err := dbTx.Scan(
+		ctx,
+		getBroadcastPrefix(),
+		getBroadcastPrefix(),
+		func(k []byte, v []byte) error {
+			var tracked bitcoin.TrackedTransaction
+			// We should not reclaim memory during a scan!!
+			if err := i.database.Encoder().Decode("", v, &tracked, false); err != nil {
+				return fmt.Errorf("%w: unable to decode tracked transaction %s", err, string(k))
+			}
+
+			trackedTransactions = append(trackedTransactions, &tracked)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan tracked transactions", err)
+	}
+
+	return trackedTransactions, nil
+}
+
+// TrackTransaction starts tracking a transaction broadcast
+// by ConstructionSubmit. It is rebroadcast until it is
+// included in a block or one of its inputs is spent by a
+// conflicting transaction.
+func (i *Indexer) TrackTransaction(
+	ctx context.Context,
+	hash string,
+	transaction string,
+	inputs []string,
+) error {
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	now := time.Now()
+	tracked, err := i.getTrackedTransaction(ctx, dbTx, hash)
+	if err != nil {
+		return err
+	}
+
+	if tracked == nil {
+		tracked = &bitcoin.TrackedTransaction{
+			Hash:        hash,
+			Transaction: transaction,
+			Inputs:      inputs,
+			Status:      bitcoin.TrackedPending,
+			SubmittedAt: now.UnixNano(),
+		}
+	}
+
+	// Submitting a tracked transaction again
+	// counts as a broadcast.
+	tracked.Broadcasts++
+	tracked.LastBroadcast = now.UnixNano()
+	tracked.NextBroadcast = nextBroadcast(now, tracked.Broadcasts)
+	tracked.LastError = ""
+
+	if err := i.setTrackedTransaction(ctx, dbTx, tracked); err != nil {
+		return err
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// GetTrackedTransaction returns the tracked transaction with
+// the provided hash or nil if it is not tracked.
+func (i *Indexer) GetTrackedTransaction(
+	ctx context.Context,
+	hash string,
+) (*bitcoin.TrackedTransaction, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	return i.getTrackedTransaction(ctx, dbTx, hash)
+}
+
+// GetTrackedTransactions returns all tracked transactions.
+func (i *Indexer) GetTrackedTransactions(
+	ctx context.Context,
+) ([]*bitcoin.TrackedTransaction, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	return i.getTrackedTransactions(ctx, dbTx)
+}
+
+// broadcastWorker is the modules.BlockWorker updating tracked
+// transactions in the database transaction that adds or removes
+// a block.
+type broadcastWorker struct {
+	i *Indexer
+}
+
+var _ modules.BlockWorker = (*broadcastWorker)(nil)
+
+// AddingBlock marks the pending tracked transactions included in
+// a block as confirmed and the ones with an input spent by another
+// transaction in the block as conflicted. Transactions confirmed or
+// conflicted more than trackingDepth blocks ago are no longer tracked.
+func (w *broadcastWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.i.confirmTrackedTransactions(ctx, dbTx, block)
+}
+
+// RemovingBlock marks the tracked transactions confirmed or
+// conflicted in a block as pending so they are rebroadcast.
+func (w *broadcastWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.i.revertTrackedTransactions(ctx, dbTx, block.BlockIdentifier)
+}
+
+func (i *Indexer) confirmTrackedTransactions(
+	ctx context.Context,
+	dbTx database.Transaction,
+	block *types.Block,
+) error {
+	trackedTransactions, err := i.getTrackedTransactions(ctx, dbTx)
+	if err != nil {
+		return err
+	}
+
+	pending := map[string]*bitcoin.TrackedTransaction{}
+	spenders := map[string]*bitcoin.TrackedTransaction{}
+	for _, tracked := range trackedTransactions {
+		if tracked.Status != bitcoin.TrackedPending {
+			if tracked.Block == nil || tracked.Block.Index+trackingDepth < block.BlockIdentifier.Index {
+				if err := dbTx.Delete(ctx, getBroadcastKey(tracked.Hash)); err != nil {
+					return fmt.Errorf("%w: unable to stop tracking %s", err, tracked.Hash)
+				}
+			}
+
+			continue
+		}
+
+		pending[tracked.Hash] = tracked
+		for _, input := range tracked.Inputs {
+			spenders[input] = tracked
+		}
+	}
+
+	for _, tx := range block.Transactions {
+		hash := tx.TransactionIdentifier.Hash
+		if tracked, ok := pending[hash]; ok {
+			tracked.Status = bitcoin.TrackedConfirmed
+			tracked.Block = block.BlockIdentifier
+			if err := i.setTrackedTransaction(ctx, dbTx, tracked); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, op := range tx.Operations {
+			if op.CoinChange == nil || op.CoinChange.CoinAction != types.CoinSpent {
+				continue
+			}
+
+			tracked, ok := spenders[op.CoinChange.CoinIdentifier.Identifier]
+			if !ok || tracked.Status != bitcoin.TrackedPending {
+				continue
+			}
+
+			tracked.Status = bitcoin.TrackedConflicted
+			tracked.Block = block.BlockIdentifier
+			tracked.ConflictingTransaction = hash
+			if err := i.setTrackedTransaction(ctx, dbTx, tracked); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (i *Indexer) revertTrackedTransactions(
+	ctx context.Context,
+	dbTx database.Transaction,
+	blockIdentifier *types.BlockIdentifier,
+) error {
+	trackedTransactions, err := i.getTrackedTransactions(ctx, dbTx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, tracked := range trackedTransactions {
+		if tracked.Status == bitcoin.TrackedFailed ||
+			tracked.Block == nil ||
+			tracked.Block.Hash != blockIdentifier.Hash {
+			continue
+		}
+
+		tracked.Status = bitcoin.TrackedPending
+		tracked.Block = nil
+		tracked.ConflictingTransaction = ""
+		tracked.NextBroadcast = now.UnixNano()
+		if err := i.setTrackedTransaction(ctx, dbTx, tracked); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rebroadcastTransactions broadcasts the pending tracked
+// transactions whose backoff has elapsed.
+func (i *Indexer) rebroadcastTransactions(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "rebroadcaster")
+
+	trackedTransactions, err := i.GetTrackedTransactions(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, tracked := range trackedTransactions {
+		if tracked.Status != bitcoin.TrackedPending || tracked.NextBroadcast > now.UnixNano() {
+			continue
+		}
+
+		_, err := i.client.SendRawTransaction(ctx, tracked.Transaction)
+
+		// A transaction already in the chain will be
+		// confirmed once its block is added.
+		var rpcErr *bitcoin.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == bitcoin.RPCVerifyAlreadyInChainErrCode {
+			err = nil
+		}
+
+		if err != nil {
+			logger.Warnw(
+				"unable to rebroadcast transaction",
+				"hash", tracked.Hash,
+				"broadcasts", tracked.Broadcasts,
+				"error", err,
+			)
+		}
+
+		failed, rejectedErr := i.rejected(ctx, tracked, err)
+		if rejectedErr != nil {
+			return rejectedErr
+		}
+
+		if err := i.updateBroadcast(ctx, tracked.Hash, now, err, failed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rejected returns whether a broadcast error means a tracked
+// transaction will never be accepted by bitcoind: it is invalid,
+// or its inputs were spent in indexed blocks by a conflicting
+// transaction. Other rejections, like a full mempool, a fee below
+// the mempool minimum, too long a chain of unconfirmed parents or
+// a lock time not reached yet, may pass on a later broadcast.
+func (i *Indexer) rejected(
+	ctx context.Context,
+	tracked *bitcoin.TrackedTransaction,
+	broadcastErr error,
+) (bool, error) {
+	var rpcErr *bitcoin.RPCError
+	if !errors.As(broadcastErr, &rpcErr) {
+		return false, nil
+	}
+
+	message := strings.ToLower(rpcErr.Message)
+	switch rpcErr.Code {
+	case bitcoin.RPCVerifyRejectedErrCode:
+		switch {
+		case strings.HasPrefix(message, rejectInvalidPrefix):
+			return true, nil
+		case strings.Contains(message, "txn-mempool-conflict"),
+			strings.Contains(message, "bad-txns-inputs-spent"):
+			return i.inputsSpent(ctx, tracked.Inputs)
+		default:
+			return false, nil
+		}
+	case bitcoin.RPCVerifyErrCode:
+		if !strings.Contains(message, "missing inputs") {
+			return false, nil
+		}
+
+		return i.inputsSpent(ctx, tracked.Inputs)
+	default:
+		return false, nil
+	}
+}
+
+// inputsSpent returns whether any of the coins spent by
+// a tracked transaction was created and spent in indexed
+// blocks. Missing inputs created by transactions that are
+// not indexed may still be created.
+func (i *Indexer) inputsSpent(ctx context.Context, inputs []string) (bool, error) {
+	for _, input := range inputs {
+		coin, _, err := i.GetCoin(ctx, &types.CoinIdentifier{Identifier: input})
+		if err != nil {
+			return false, fmt.Errorf("%w: unable to get coin %s", err, input)
+		}
+
+		if coin != nil {
+			continue
+		}
+
+		block, _, err := i.FindTransaction(
+			ctx,
+			&types.TransactionIdentifier{Hash: bitcoin.TransactionHash(input)},
+		)
+		if err != nil {
+			return false, fmt.Errorf("%w: unable to find transaction of %s", err, input)
+		}
+
+		if block != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// updateBroadcast records a broadcast of a tracked transaction
+// unless it was confirmed or conflicted in the meantime. Failed
+// transactions are no longer rebroadcast, and stop being tracked
+// trackingDepth blocks after the head block.
+func (i *Indexer) updateBroadcast(
+	ctx context.Context,
+	hash string,
+	now time.Time,
+	broadcastErr error,
+	failed bool,
+) error {
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	tracked, err := i.getTrackedTransaction(ctx, dbTx, hash)
+	if err != nil {
+		return err
+	}
+
+	if tracked == nil || tracked.Status != bitcoin.TrackedPending {
+		return nil
+	}
+
+	tracked.Broadcasts++
+	tracked.LastBroadcast = now.UnixNano()
+	tracked.NextBroadcast = nextBroadcast(now, tracked.Broadcasts)
+	tracked.LastError = ""
+	if broadcastErr != nil {
+		tracked.LastError = broadcastErr.Error()
+	}
+
+	if failed {
+		head, err := i.blockStorage.GetHeadBlockIdentifierTransactional(ctx, dbTx)
+		if err != nil && !errors.Is(err, storageErrs.ErrHeadBlockNotFound) {
+			return fmt.Errorf("%w: unable to get head block", err)
+		}
+
+		tracked.Status = bitcoin.TrackedFailed
+		tracked.Block = head
+	}
+
+	if err := i.setTrackedTransaction(ctx, dbTx, tracked); err != nil {
+		return err
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// Rebroadcast rebroadcasts pending tracked transactions
+// every rebroadcastFrequency.
+func (i *Indexer) Rebroadcast(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "rebroadcaster")
+
+	tc := time.NewTicker(rebroadcastFrequency)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Warnw("exiting rebroadcaster")
+			return ctx.Err()
+		case <-tc.C:
+			if err := i.rebroadcastTransactions(ctx); err != nil {
+				logger.Warnw("unable to rebroadcast transactions", "error", err)
+			}
+		}
+	}
+}
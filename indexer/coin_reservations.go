@@ -0,0 +1,237 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// reservationNamespace is prepended to the key
+	// of each coin reservation and identifies the
+	// write lock held while reserving coins.
+	reservationNamespace = "reservation"
+)
+
+var (
+	errCoinReserved = errors.New("coin is reserved")
+)
+
+// coinReservation is a lease on a coin held by a
+// transaction under construction.
+type coinReservation struct {
+	ReservationID string `json:"reservation_id"`
+
+	// Expiry is the unix time in nanoseconds
+	// after which the reservation is released.
+	Expiry int64 `json:"expiry"`
+}
+
+func getReservationKey(identifier *types.CoinIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", reservationNamespace, identifier.Identifier))
+}
+
+// getReservation returns the unexpired reservation of a coin
+// or nil if the coin is not reserved.
+func (i *Indexer) getReservation(
+	ctx context.Context,
+	dbTx database.Transaction,
+	identifier *types.CoinIdentifier,
+	now time.Time,
+) (*coinReservation, error) {
+	exists, val, err := dbTx.Get(ctx, getReservationKey(identifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get reservation of %s", err, identifier.Identifier)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	var reservation coinReservation
+	if err := i.database.Encoder().Decode("", val, &reservation, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode reservation of %s", err, identifier.Identifier)
+	}
+
+	if reservation.Expiry <= now.UnixNano() {
+		return nil, nil
+	}
+
+	return &reservation, nil
+}
+
+// ReserveCoins reserves the provided *types.Coin for reservationID
+// until ttl elapses. Coins already reserved for reservationID are
+// renewed. No coin is reserved if any of them is reserved for
+// another reservationID.
+func (i *Indexer) ReserveCoins(
+	ctx context.Context,
+	reservationID string,
+	coins []*types.Coin,
+	ttl time.Duration,
+) error {
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	now := time.Now()
+	encoded, err := i.database.Encoder().Encode("", &coinReservation{
+		ReservationID: reservationID,
+		Expiry:        now.Add(ttl).UnixNano(),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode reservation", err)
+	}
+
+	for _, coin := range coins {
+		reservation, err := i.getReservation(ctx, dbTx, coin.CoinIdentifier, now)
+		if err != nil {
+			return err
+		}
+
+		if reservation != nil && reservation.ReservationID != reservationID {
+			return fmt.Errorf(
+				"%w: %s is reserved until %s",
+				errCoinReserved,
+				coin.CoinIdentifier.Identifier,
+				time.Unix(0, reservation.Expiry).UTC().Format(time.RFC3339),
+			)
+		}
+
+		if err := dbTx.Set(ctx, getReservationKey(coin.CoinIdentifier), encoded, false); err != nil {
+			return fmt.Errorf(
+				"%w: unable to reserve %s",
+				err,
+				coin.CoinIdentifier.Identifier,
+			)
+		}
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// ReleaseCoins removes the reservations of the provided
+// *types.Coin held for reservationID. Coins reserved for
+// another reservationID are left untouched.
+func (i *Indexer) ReleaseCoins(
+	ctx context.Context,
+	reservationID string,
+	coins []*types.Coin,
+) error {
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	now := time.Now()
+	for _, coin := range coins {
+		reservation, err := i.getReservation(ctx, dbTx, coin.CoinIdentifier, now)
+		if err != nil {
+			return err
+		}
+
+		if reservation == nil || reservation.ReservationID != reservationID {
+			continue
+		}
+
+		if err := dbTx.Delete(ctx, getReservationKey(coin.CoinIdentifier)); err != nil {
+			return fmt.Errorf(
+				"%w: unable to release %s",
+				err,
+				coin.CoinIdentifier.Identifier,
+			)
+		}
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// GetCoinReservations returns whether each of the
+// provided *types.Coin is currently reserved.
+func (i *Indexer) GetCoinReservations(
+	ctx context.Context,
+	coins []*types.Coin,
+) ([]bool, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	now := time.Now()
+	reserved := make([]bool, len(coins))
+	for j, coin := range coins {
+		reservation, err := i.getReservation(ctx, dbTx, coin.CoinIdentifier, now)
+		if err != nil {
+			return nil, err
+		}
+
+		reserved[j] = reservation != nil
+	}
+
+	return reserved, nil
+}
+
+// reservationWorker is the modules.BlockWorker removing
+// the reservations of all coins spent in a block, in the
+// database transaction that adds the block. Once a coin is
+// spent, whether by the reserving transaction or a
+// conflicting one, its lease is no longer needed.
+type reservationWorker struct{}
+
+var _ modules.BlockWorker = (*reservationWorker)(nil)
+
+// AddingBlock releases the coins spent in a block.
+func (w *reservationWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.CoinChange == nil {
+				continue
+			}
+
+			if op.CoinChange.CoinAction != types.CoinSpent {
+				continue
+			}
+
+			if err := dbTx.Delete(ctx, getReservationKey(op.CoinChange.CoinIdentifier)); err != nil {
+				return nil, fmt.Errorf(
+					"%w: unable to release %s",
+					err,
+					op.CoinChange.CoinIdentifier.Identifier,
+				)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock does nothing, released
+// coins are not reserved again.
+func (w *reservationWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
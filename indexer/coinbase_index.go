@@ -0,0 +1,163 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// coinbaseNamespace is prepended to the hash of each
+	// coinbase transaction, which is mapped to the index of
+	// its block so the maturity of coins can be computed
+	// without reading the transactions that created them.
+	coinbaseNamespace = "coinbase"
+)
+
+func getCoinbaseKey(hash string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", coinbaseNamespace, hash))
+}
+
+// getCoinbaseHeight returns the index of the block of a
+// coinbase transaction, or false if the transaction is
+// not a coinbase transaction in an indexed block.
+func getCoinbaseHeight(
+	ctx context.Context,
+	dbTx database.Transaction,
+	hash string,
+) (int64, bool, error) {
+	exists, val, err := dbTx.Get(ctx, getCoinbaseKey(hash))
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to get coinbase transaction %s", err, hash)
+	}
+
+	if !exists {
+		return -1, false, nil
+	}
+
+	index, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: invalid height of coinbase transaction %s", err, hash)
+	}
+
+	return index, true, nil
+}
+
+// storeCoinbases stores the block index of
+// the coinbase transactions of a block.
+func storeCoinbases(
+	ctx context.Context,
+	dbTx database.Transaction,
+	block *types.Block,
+) error {
+	index := []byte(strconv.FormatInt(block.BlockIdentifier.Index, 10))
+	for _, tx := range block.Transactions {
+		if !isCoinbase(tx) {
+			continue
+		}
+
+		hash := tx.TransactionIdentifier.Hash
+		if err := dbTx.Set(ctx, getCoinbaseKey(hash), index, true); err != nil {
+			return fmt.Errorf("%w: unable to store coinbase transaction %s", err, hash)
+		}
+	}
+
+	return nil
+}
+
+// coinbaseWorker is the modules.BlockWorker recording
+// the coinbase transactions of the blocks it adds.
+type coinbaseWorker struct{}
+
+var _ modules.BlockWorker = (*coinbaseWorker)(nil)
+
+// AddingBlock stores the coinbase
+// transactions of a block.
+func (w *coinbaseWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, storeCoinbases(ctx, dbTx, block)
+}
+
+// RemovingBlock deletes the coinbase
+// transactions of a block.
+func (w *coinbaseWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	for _, tx := range block.Transactions {
+		if !isCoinbase(tx) {
+			continue
+		}
+
+		hash := tx.TransactionIdentifier.Hash
+		if err := dbTx.Delete(ctx, getCoinbaseKey(hash)); err != nil {
+			return nil, fmt.Errorf("%w: unable to delete coinbase transaction %s", err, hash)
+		}
+	}
+
+	return nil, nil
+}
+
+// indexRecentCoinbases stores the coinbase transactions of the
+// blocks whose outputs may still be immature. The coinbase
+// transactions of blocks added before they were recorded are
+// otherwise missing, which would make their outputs mature.
+func (i *Indexer) indexRecentCoinbases(
+	ctx context.Context,
+	head *types.BlockIdentifier,
+) error {
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	start := head.Index - int64(i.params.CoinbaseMaturity) + 1
+	if start < i.genesisBlock.Index {
+		start = i.genesisBlock.Index
+	}
+
+	for index := start; index <= head.Index; index++ {
+		blockIndex := index
+		block, err := i.blockStorage.GetBlockTransactional(
+			ctx,
+			dbTx,
+			&types.PartialBlockIdentifier{Index: &blockIndex},
+		)
+		if err != nil {
+			return fmt.Errorf("%w: unable to get block %d", err, index)
+		}
+
+		if err := storeCoinbases(ctx, dbTx, block); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit coinbase transactions", err)
+	}
+
+	return nil
+}
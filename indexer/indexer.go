@@ -28,6 +28,7 @@ import (
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/services"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/utils"
 
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
 	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
@@ -71,6 +72,12 @@ const (
 
 	// semaphoreWeight is the weight of each semaphore request.
 	semaphoreWeight = int64(1)
+
+	// blockSyncIdentifier is the identifier of the write lock
+	// held by modules.BlockStorage while adding or removing a
+	// block. Writers of data also updated by block workers hold
+	// it, so their transactions never conflict with a block.
+	blockSyncIdentifier = "blockSyncIdentifier"
 )
 
 var (
@@ -87,6 +94,15 @@ type Client interface {
 		*bitcoin.Block,
 		map[string]*types.AccountCoin,
 	) (*types.Block, error)
+	SendRawTransaction(context.Context, string) (string, error)
+	RawMempool(context.Context) ([]string, error)
+	GetRawTransaction(context.Context, string) (*bitcoin.Transaction, error)
+	GetRawBlockTransaction(context.Context, string, string) (string, error)
+	ParseTransaction(
+		context.Context,
+		*bitcoin.Transaction,
+		map[string]*types.AccountCoin,
+	) (*types.Transaction, error)
 }
 
 var _ syncer.Handler = (*Indexer)(nil)
@@ -98,7 +114,11 @@ type Indexer struct {
 	cancel context.CancelFunc
 
 	network       *types.NetworkIdentifier
+	params        *chaincfg.Params
+	currency      *types.Currency
+	genesisBlock  *types.BlockIdentifier
 	pruningConfig *configuration.PruningConfiguration
+	addressIndex  bool
 
 	client Client
 
@@ -111,6 +131,8 @@ type Indexer struct {
 
 	waiter *waitTable
 
+	mempool *mempoolTracker
+
 	// Store coins created in pre-store before persisted
 	// in add block so we can optimistically populate
 	// blocks before committed.
@@ -221,11 +243,16 @@ func Initialize(
 	i := &Indexer{
 		cancel:         cancel,
 		network:        config.Network,
+		params:         config.Params,
+		currency:       config.Currency,
+		genesisBlock:   config.GenesisBlockIdentifier,
 		pruningConfig:  config.Pruning,
+		addressIndex:   config.AddressIndex,
 		client:         client,
 		database:       localStore,
 		blockStorage:   blockStorage,
 		waiter:         newWaitTable(),
+		mempool:        newMempoolTracker(),
 		asserter:       asserter,
 		coinCache:      map[string]*types.AccountCoin{},
 		coinCacheMutex: new(sdkUtils.PriorityMutex),
@@ -246,7 +273,19 @@ func Initialize(
 	)
 	i.balanceStorage = balanceStorage
 
-	i.workers = []modules.BlockWorker{coinStorage, balanceStorage}
+	i.workers = []modules.BlockWorker{
+		coinStorage,
+		balanceStorage,
+		&reservationWorker{},
+		&broadcastWorker{i: i},
+		&watchOnlyWorker{i: i},
+		&coinbaseWorker{},
+	}
+	if i.addressIndex {
+		i.workers = append(i.workers, &historyWorker{})
+	} else if err := i.resetHistoryStart(ctx); err != nil {
+		return nil, err
+	}
 
 	return i, nil
 }
@@ -281,6 +320,9 @@ func (i *Indexer) Sync(ctx context.Context) error {
 	head, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
 	if err == nil {
 		startIndex = head.Index + 1
+		if err := i.indexRecentCoinbases(ctx, head); err != nil {
+			return fmt.Errorf("%w: unable to index recent coinbase transactions", err)
+		}
 	}
 
 	// Load in previous blocks into syncer cache to handle reorgs.
@@ -359,6 +401,8 @@ func (i *Indexer) BlockAdded(ctx context.Context, block *types.Block) error {
 		)
 	}
 
+	i.removeMempoolTransactions(block)
+
 	ops := 0
 	for _, transaction := range block.Transactions {
 		ops += len(transaction.Operations)
@@ -507,6 +551,7 @@ func (i *Indexer) BlockRemoved(
 		"hash", blockIdentifier.Hash,
 		"index", blockIdentifier.Index,
 	)
+
 	err := i.blockStorage.RemoveBlock(ctx, blockIdentifier)
 	if err != nil {
 		return fmt.Errorf(
@@ -870,6 +915,144 @@ func (i *Indexer) GetScriptPubKeys(
 	return scripts, nil
 }
 
+// GetCoinBlocks returns the *types.BlockIdentifier of the
+// block that created each of the provided *types.Coin.
+func (i *Indexer) GetCoinBlocks(
+	ctx context.Context,
+	coins []*types.Coin,
+) ([]*types.BlockIdentifier, error) {
+	databaseTransaction := i.database.ReadTransaction(ctx)
+	defer databaseTransaction.Discard(ctx)
+
+	blocks := make([]*types.BlockIdentifier, len(coins))
+	for j, coin := range coins {
+		transactionHash, _, err := bitcoin.ParseCoinIdentifier(coin.CoinIdentifier)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse coin identifier", err)
+		}
+
+		blockIdentifier, _, err := i.blockStorage.FindTransaction(
+			ctx,
+			&types.TransactionIdentifier{Hash: transactionHash.String()},
+			databaseTransaction,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to find transaction %s",
+				err,
+				transactionHash.String(),
+			)
+		}
+
+		if blockIdentifier == nil {
+			return nil, fmt.Errorf(
+				"unable to find transaction %s",
+				transactionHash.String(),
+			)
+		}
+
+		blocks[j] = blockIdentifier
+	}
+
+	return blocks, nil
+}
+
+// isCoinbase returns whether a transaction
+// spends the coinbase input of a block.
+func isCoinbase(transaction *types.Transaction) bool {
+	for _, op := range transaction.Operations {
+		if op.Type == bitcoin.CoinbaseOpType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetRawTransactions returns the hex encoded transactions with
+// the provided hashes, read from the blocks they are indexed in or,
+// for transactions that are not indexed, from the mempool.
+func (i *Indexer) GetRawTransactions(
+	ctx context.Context,
+	hashes []string,
+) ([]string, error) {
+	databaseTransaction := i.database.ReadTransaction(ctx)
+	defer databaseTransaction.Discard(ctx)
+
+	transactions := make([]string, len(hashes))
+	for j, hash := range hashes {
+		blockIdentifier, _, err := i.blockStorage.FindTransaction(
+			ctx,
+			&types.TransactionIdentifier{Hash: hash},
+			databaseTransaction,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to find transaction %s", err, hash)
+		}
+
+		if blockIdentifier == nil {
+			transaction, err := i.client.GetRawTransaction(ctx, hash)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to get transaction %s", err, hash)
+			}
+
+			transactions[j] = transaction.Hex
+			continue
+		}
+
+		transactions[j], err = i.client.GetRawBlockTransaction(ctx, blockIdentifier.Hash, hash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get transaction %s", err, hash)
+		}
+	}
+
+	return transactions, nil
+}
+
+// GetCoinMaturities returns the maturity height of each of the
+// provided *types.Coin that is an immature coinbase output, or 0
+// if the coin can be spent in the next block. Coinbase outputs
+// created at height h can be spent in blocks from height
+// h+CoinbaseMaturity. Coinbase transactions are recorded when
+// their block is added, so coins that are not in an indexed block,
+// like coins created in the mempool, are never coinbase outputs.
+func (i *Indexer) GetCoinMaturities(
+	ctx context.Context,
+	coins []*types.Coin,
+) ([]int64, error) {
+	databaseTransaction := i.database.ReadTransaction(ctx)
+	defer databaseTransaction.Discard(ctx)
+
+	head, err := i.blockStorage.GetHeadBlockIdentifierTransactional(ctx, databaseTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	maturities := make([]int64, len(coins))
+	for j, coin := range coins {
+		transactionHash, _, err := bitcoin.ParseCoinIdentifier(coin.CoinIdentifier)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse coin identifier", err)
+		}
+
+		index, coinbase, err := getCoinbaseHeight(ctx, databaseTransaction, transactionHash.String())
+		if err != nil {
+			return nil, err
+		}
+
+		if !coinbase {
+			continue
+		}
+
+		maturity := index + int64(i.params.CoinbaseMaturity)
+		if head.Index+1 < maturity {
+			maturities[j] = maturity
+		}
+	}
+
+	return maturities, nil
+}
+
 // GetBlockLazy returns a *types.BlockResponse from the indexer's block storage.
 // All transactions in a block must be fetched individually.
 func (i *Indexer) GetBlockLazy(
@@ -893,6 +1076,34 @@ func (i *Indexer) GetBlockTransaction(
 	)
 }
 
+// FindTransaction returns the *types.Transaction with the provided
+// *types.TransactionIdentifier and the block it is included in. Both
+// are nil if the transaction is not in an indexed block.
+func (i *Indexer) FindTransaction(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+) (*types.BlockIdentifier, *types.Transaction, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	return i.blockStorage.FindTransaction(ctx, transactionIdentifier, dbTx)
+}
+
+// GetCoin returns the unspent *types.Coin with the provided
+// *types.CoinIdentifier and the account that owns it. The coin
+// is nil if it does not exist or is already spent.
+func (i *Indexer) GetCoin(
+	ctx context.Context,
+	coinIdentifier *types.CoinIdentifier,
+) (*types.Coin, *types.AccountIdentifier, error) {
+	coin, owner, err := i.coinStorage.GetCoin(ctx, coinIdentifier)
+	if errors.Is(err, storageErrs.ErrCoinNotFound) {
+		return nil, nil, nil
+	}
+
+	return coin, owner, err
+}
+
 // GetCoins returns all unspent coins for a particular *types.AccountIdentifier.
 func (i *Indexer) GetCoins(
 	ctx context.Context,
@@ -250,6 +250,7 @@ func TestIndexer_Transactions(t *testing.T) {
 		Script  *bitcoin.ScriptPubKey
 		Coin    *types.Coin
 		Account *types.AccountIdentifier
+		Block   *types.BlockIdentifier
 	}
 
 	coinBank := map[string]*coinBankEntry{}
@@ -319,6 +320,7 @@ func TestIndexer_Transactions(t *testing.T) {
 				Account: &types.AccountIdentifier{
 					Address: rawHash,
 				},
+				Block: identifier,
 			}
 
 			transactions = append(transactions, tx)
@@ -404,6 +406,7 @@ func TestIndexer_Transactions(t *testing.T) {
 				// Ensure ScriptPubKeys are accessible.
 				allCoins := []*types.Coin{}
 				expectedPubKeys := []*bitcoin.ScriptPubKey{}
+				expectedBlocks := []*types.BlockIdentifier{}
 				for k, v := range coinBank {
 					allCoins = append(allCoins, &types.Coin{
 						CoinIdentifier: &types.CoinIdentifier{Identifier: k},
@@ -413,12 +416,55 @@ func TestIndexer_Transactions(t *testing.T) {
 						},
 					})
 					expectedPubKeys = append(expectedPubKeys, v.Script)
+					expectedBlocks = append(expectedBlocks, v.Block)
 				}
 
 				pubKeys, err := i.GetScriptPubKeys(ctx, allCoins)
 				assert.NoError(t, err)
 				assert.Equal(t, expectedPubKeys, pubKeys)
 
+				// Ensure the blocks that created coins are accessible.
+				blocks, err := i.GetCoinBlocks(ctx, allCoins)
+				assert.NoError(t, err)
+				assert.Equal(t, expectedBlocks, blocks)
+
+				unknownCoin := &types.Coin{
+					CoinIdentifier: &types.CoinIdentifier{
+						Identifier: fmt.Sprintf("%x:0", sha256.Sum256([]byte("unknown"))),
+					},
+				}
+				blocks, err = i.GetCoinBlocks(ctx, []*types.Coin{unknownCoin})
+				assert.Nil(t, blocks)
+				assert.EqualError(t, err, fmt.Sprintf(
+					"unable to find transaction %x",
+					sha256.Sum256([]byte("unknown")),
+				))
+
+				// Ensure the transactions that created coins are read
+				// from their block, or from the mempool once unknown.
+				transactionHash, _, err := bitcoin.ParseCoinIdentifier(allCoins[0].CoinIdentifier)
+				assert.NoError(t, err)
+				mockClient.On(
+					"GetRawBlockTransaction",
+					ctx,
+					expectedBlocks[0].Hash,
+					transactionHash.String(),
+				).Return(
+					"block transaction",
+					nil,
+				).Once()
+				mockClient.On(
+					"GetRawTransaction",
+					ctx,
+					"mempool",
+				).Return(
+					&bitcoin.Transaction{Hex: "mempool transaction"},
+					nil,
+				).Once()
+				transactions, err := i.GetRawTransactions(ctx, []string{transactionHash.String(), "mempool"})
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"block transaction", "mempool transaction"}, transactions)
+
 				cancel()
 				close(waitForFinish)
 				return
@@ -838,3 +884,1249 @@ func TestIndexer_HeaderReorg(t *testing.T) {
 	assert.Len(t, i.waiter.table, 0)
 	mockClient.AssertExpectations(t)
 }
+
+func TestIndexer_CoinReservations(t *testing.T) {
+	// Create Indexer
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    dogecoin.MainnetNetwork,
+			Blockchain: dogecoin.Blockchain,
+		},
+		GenesisBlockIdentifier: dogecoin.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	coins := make([]*types.Coin, 3)
+	for j := range coins {
+		coins[j] = &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: fmt.Sprintf("%x:%d", sha256.Sum256([]byte("reserved")), j),
+			},
+			Amount: &types.Amount{
+				Value:    "-100",
+				Currency: dogecoin.MainnetCurrency,
+			},
+		}
+	}
+
+	// Reserve the first two coins
+	assert.NoError(t, i.ReserveCoins(ctx, "tx 1", coins[:2], time.Minute))
+	reserved, err := i.GetCoinReservations(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, reserved)
+
+	// Conflicting reservations do not reserve any coin
+	err = i.ReserveCoins(ctx, "tx 2", coins[1:], time.Minute)
+	assert.True(t, errors.Is(err, errCoinReserved))
+	reserved, err = i.GetCoinReservations(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, reserved)
+
+	// Reservations can be renewed
+	assert.NoError(t, i.ReserveCoins(ctx, "tx 1", coins[:2], time.Minute))
+
+	// Expired reservations are released
+	assert.NoError(t, i.ReserveCoins(ctx, "tx 2", coins[2:], time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	reserved, err = i.GetCoinReservations(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, reserved)
+	assert.NoError(t, i.ReserveCoins(ctx, "tx 3", coins[2:], time.Minute))
+
+	// Spent coins are released
+	dbTx := i.database.WriteTransaction(ctx, reservationNamespace, true)
+	_, err = (&reservationWorker{}).AddingBlock(ctx, nil, &types.Block{
+		Transactions: []*types.Transaction{
+			{
+				Operations: []*types.Operation{
+					{
+						Type: bitcoin.InputOpType,
+						CoinChange: &types.CoinChange{
+							CoinIdentifier: coins[0].CoinIdentifier,
+							CoinAction:     types.CoinSpent,
+						},
+					},
+				},
+			},
+		},
+	}, dbTx)
+	assert.NoError(t, err)
+	assert.NoError(t, dbTx.Commit(ctx))
+	reserved, err = i.GetCoinReservations(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true, true}, reserved)
+
+	// Released coins are only released for their reservation
+	assert.NoError(t, i.ReleaseCoins(ctx, "tx 1", coins[1:]))
+	reserved, err = i.GetCoinReservations(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false, true}, reserved)
+
+	i.CloseDatabase(ctx)
+}
+
+func TestIndexer_BroadcastTracker(t *testing.T) {
+	// Create Indexer
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    dogecoin.MainnetNetwork,
+			Blockchain: dogecoin.Blockchain,
+		},
+		GenesisBlockIdentifier: dogecoin.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	spend := func(hash string, coin string) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					Type: bitcoin.InputOpType,
+					CoinChange: &types.CoinChange{
+						CoinIdentifier: &types.CoinIdentifier{Identifier: coin},
+						CoinAction:     types.CoinSpent,
+					},
+				},
+			},
+		}
+	}
+
+	// Track two transactions
+	assert.NoError(t, i.TrackTransaction(ctx, "tx 1", "raw 1", []string{"coin:0"}))
+	assert.NoError(t, i.TrackTransaction(ctx, "tx 2", "raw 2", []string{"coin:1"}))
+	tracked, err := i.GetTrackedTransactions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tracked, 2)
+	assert.Equal(t, bitcoin.TrackedPending, tracked[0].Status)
+	assert.Equal(t, int64(1), tracked[0].Broadcasts)
+
+	// Transactions are not rebroadcast before their backoff elapses
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+	mockClient.AssertNotCalled(t, "SendRawTransaction", mock.Anything, mock.Anything)
+
+	// Transactions are rebroadcast once their backoff elapses
+	dbTx := i.database.WriteTransaction(ctx, broadcastNamespace, true)
+	for _, trackedTx := range tracked {
+		trackedTx.NextBroadcast = 0
+		assert.NoError(t, i.setTrackedTransaction(ctx, dbTx, trackedTx))
+	}
+	assert.NoError(t, dbTx.Commit(ctx))
+	dbTx.Discard(ctx)
+
+	mockClient.On("SendRawTransaction", ctx, "raw 1").Return("tx 1", nil).Once()
+	mockClient.On("SendRawTransaction", ctx, "raw 2").Return("", &bitcoin.RPCError{
+		Code:    bitcoin.RPCVerifyErrCode,
+		Message: "Missing inputs",
+	}).Once()
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+
+	tx1, err := i.GetTrackedTransaction(ctx, "tx 1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), tx1.Broadcasts)
+	assert.Empty(t, tx1.LastError)
+	assert.Greater(t, tx1.NextBroadcast, time.Now().UnixNano())
+	tx2, err := i.GetTrackedTransaction(ctx, "tx 2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), tx2.Broadcasts)
+	assert.Contains(t, tx2.LastError, "Missing inputs")
+
+	// Transactions are confirmed or conflicted in blocks
+	worker := &broadcastWorker{i: i}
+	addBlock := func(block *types.Block) {
+		dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, true)
+		defer dbTx.Discard(ctx)
+
+		_, err := worker.AddingBlock(ctx, nil, block, dbTx)
+		assert.NoError(t, err)
+		assert.NoError(t, dbTx.Commit(ctx))
+	}
+	block1 := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Hash: "block 1", Index: 1},
+		Transactions: []*types.Transaction{
+			spend("tx 1", "coin:0"),
+			spend("tx 3", "coin:1"),
+		},
+	}
+	addBlock(block1)
+	tx1, err = i.GetTrackedTransaction(ctx, "tx 1")
+	assert.NoError(t, err)
+	assert.Equal(t, bitcoin.TrackedConfirmed, tx1.Status)
+	assert.Equal(t, block1.BlockIdentifier, tx1.Block)
+	tx2, err = i.GetTrackedTransaction(ctx, "tx 2")
+	assert.NoError(t, err)
+	assert.Equal(t, bitcoin.TrackedConflicted, tx2.Status)
+	assert.Equal(t, "tx 3", tx2.ConflictingTransaction)
+
+	// Confirmed and conflicted transactions are not rebroadcast
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+
+	// Transactions are pending again if their block is removed
+	dbTx = i.database.WriteTransaction(ctx, blockSyncIdentifier, true)
+	_, err = worker.RemovingBlock(ctx, nil, block1, dbTx)
+	assert.NoError(t, err)
+	assert.NoError(t, dbTx.Commit(ctx))
+	tracked, err = i.GetTrackedTransactions(ctx)
+	assert.NoError(t, err)
+	for _, trackedTx := range tracked {
+		assert.Equal(t, bitcoin.TrackedPending, trackedTx.Status)
+		assert.Nil(t, trackedTx.Block)
+		assert.Empty(t, trackedTx.ConflictingTransaction)
+	}
+
+	// Transactions are no longer tracked trackingDepth
+	// blocks after they are confirmed
+	addBlock(block1)
+	addBlock(&types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Hash:  "block 2",
+			Index: block1.BlockIdentifier.Index + trackingDepth + 1,
+		},
+	})
+	tracked, err = i.GetTrackedTransactions(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, tracked)
+
+	// Invalid transactions are no longer rebroadcast
+	assert.NoError(t, i.TrackTransaction(ctx, "tx 4", "raw 4", []string{"coin:2"}))
+	tx4, err := i.GetTrackedTransaction(ctx, "tx 4")
+	assert.NoError(t, err)
+	tx4.NextBroadcast = 0
+	dbTx = i.database.WriteTransaction(ctx, blockSyncIdentifier, true)
+	assert.NoError(t, i.setTrackedTransaction(ctx, dbTx, tx4))
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	// Transient rejections and conflicts with the mempool
+	// are retried with a backoff.
+	mockClient.On("SendRawTransaction", ctx, "raw 4").Return("", &bitcoin.RPCError{
+		Code:    bitcoin.RPCVerifyRejectedErrCode,
+		Message: "66: mempool min fee not met",
+	}).Once()
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+	tx4, err = i.GetTrackedTransaction(ctx, "tx 4")
+	assert.NoError(t, err)
+	assert.Equal(t, bitcoin.TrackedPending, tx4.Status)
+	assert.Contains(t, tx4.LastError, "mempool min fee not met")
+	assert.Greater(t, tx4.NextBroadcast, time.Now().UnixNano())
+
+	tx4.NextBroadcast = 0
+	dbTx = i.database.WriteTransaction(ctx, blockSyncIdentifier, true)
+	assert.NoError(t, i.setTrackedTransaction(ctx, dbTx, tx4))
+	assert.NoError(t, dbTx.Commit(ctx))
+	mockClient.On("SendRawTransaction", ctx, "raw 4").Return("", &bitcoin.RPCError{
+		Code:    bitcoin.RPCVerifyRejectedErrCode,
+		Message: "258: txn-mempool-conflict",
+	}).Once()
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+	tx4, err = i.GetTrackedTransaction(ctx, "tx 4")
+	assert.NoError(t, err)
+	assert.Equal(t, bitcoin.TrackedPending, tx4.Status)
+
+	tx4.NextBroadcast = 0
+	dbTx = i.database.WriteTransaction(ctx, blockSyncIdentifier, true)
+	assert.NoError(t, i.setTrackedTransaction(ctx, dbTx, tx4))
+	assert.NoError(t, dbTx.Commit(ctx))
+	mockClient.On("SendRawTransaction", ctx, "raw 4").Return("", &bitcoin.RPCError{
+		Code:    bitcoin.RPCVerifyRejectedErrCode,
+		Message: "16: mandatory-script-verify-flag-failed (Signature must be zero for failed CHECK(MULTI)SIG operation)",
+	}).Once()
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+	tx4, err = i.GetTrackedTransaction(ctx, "tx 4")
+	assert.NoError(t, err)
+	assert.Equal(t, bitcoin.TrackedFailed, tx4.Status)
+	assert.Contains(t, tx4.LastError, "mandatory-script-verify-flag-failed")
+
+	tx4.NextBroadcast = 0
+	dbTx = i.database.WriteTransaction(ctx, blockSyncIdentifier, true)
+	assert.NoError(t, i.setTrackedTransaction(ctx, dbTx, tx4))
+	assert.NoError(t, dbTx.Commit(ctx))
+	assert.NoError(t, i.rebroadcastTransactions(ctx))
+
+	mockClient.AssertExpectations(t)
+	i.CloseDatabase(ctx)
+}
+
+func TestIndexer_WatchOnly(t *testing.T) {
+	// Create Indexer
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    dogecoin.TestnetNetwork,
+			Blockchain: dogecoin.Blockchain,
+		},
+		Params:                 dogecoin.TestnetParams,
+		Currency:               dogecoin.TestnetCurrency,
+		GenesisBlockIdentifier: dogecoin.TestnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	extendedKey := "tpubDDW4jVEAkwNoHumzePCtQ5FcxXVc8RG8ACszXP1HD1WThkZ19sAoyaNeiXswjTtAKM14zjo8rdhxadti7zuNSfJBMuG68oxQ3Bi1wgo88fD" // nolint
+	external := []string{
+		"nehiWbHwkjvD7drcqzbiJZwo5uCyQ7CCqf",
+		"nrGFcpVcWBEpoGKoN85f65qQTDNLs37bav",
+		"npk6udGxfGy8gjQ28R2giWYLoCq5tuEbtH",
+	}
+	internal := []string{
+		"nWCbFveJs7vMcFGPCxxBw2jSG4zarSySrk",
+		"npxP5pSTX2eGPP8cXaLDweUQP8EsaCoCik",
+		"nXNHVTzVVYvKXcn2mfDzUDRPpaYxCY9Xh8",
+	}
+
+	pay := func(hash string, address string, value int64) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{
+						Index:        0,
+						NetworkIndex: &index0,
+					},
+					Status: types.String(bitcoin.SuccessStatus),
+					Type:   bitcoin.OutputOpType,
+					Account: &types.AccountIdentifier{
+						Address: address,
+					},
+					Amount: &types.Amount{
+						Value:    fmt.Sprintf("%d", value),
+						Currency: dogecoin.TestnetCurrency,
+					},
+					CoinChange: &types.CoinChange{
+						CoinIdentifier: &types.CoinIdentifier{
+							Identifier: fmt.Sprintf("%s:%d", hash, index0),
+						},
+						CoinAction: types.CoinCreated,
+					},
+				},
+			},
+		}
+	}
+
+	hash := func(s string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	}
+
+	// Index activity before the account is registered
+	i.blockStorage.Initialize(i.workers)
+	block0 := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+		ParentBlockIdentifier: &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+		Transactions: []*types.Transaction{
+			pay(hash("tx 1"), external[1], 100),
+			pay(hash("tx 2"), internal[0], 50),
+		},
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block0))
+	assert.NoError(t, i.BlockAdded(ctx, block0))
+
+	// Addresses with a balance history are used
+	account, err := i.RegisterWatchOnlyAccount(ctx, extendedKey, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), account.External.LastUsed)
+	assert.Len(t, account.External.Addresses, 4)
+	assert.Equal(t, external, account.External.Addresses[:3])
+	assert.Equal(t, int64(0), account.Internal.LastUsed)
+	assert.Equal(t, internal, account.Internal.Addresses)
+
+	// Activity in new blocks derives new addresses
+	block1 := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(1), Index: 1},
+		ParentBlockIdentifier: block0.BlockIdentifier,
+		Transactions: []*types.Transaction{
+			pay(hash("tx 3"), account.External.Addresses[3], 25),
+		},
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block1))
+	assert.NoError(t, i.BlockAdded(ctx, block1))
+	account, err = i.GetWatchOnlyAccount(ctx, extendedKey)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), account.External.LastUsed)
+	assert.Len(t, account.External.Addresses, 6)
+
+	// Balances and coins are aggregated
+	amount, block, err := i.GetWatchOnlyBalance(ctx, extendedKey, dogecoin.TestnetCurrency, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "175", amount.Value)
+	assert.Equal(t, block1.BlockIdentifier, block)
+
+	amount, block, err = i.GetWatchOnlyBalance(
+		ctx,
+		extendedKey,
+		dogecoin.TestnetCurrency,
+		&types.PartialBlockIdentifier{Index: &block0.BlockIdentifier.Index},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "150", amount.Value)
+	assert.Equal(t, block0.BlockIdentifier, block)
+
+	coins, block, err := i.GetWatchOnlyCoins(ctx, extendedKey)
+	assert.NoError(t, err)
+	assert.Len(t, coins, 3)
+	assert.Equal(t, block1.BlockIdentifier, block)
+
+	// Registering again raises the gap limit
+	account, err = i.RegisterWatchOnlyAccount(ctx, extendedKey, 3)
+	assert.NoError(t, err)
+	assert.Len(t, account.External.Addresses, 7)
+	assert.Len(t, account.Internal.Addresses, 4)
+
+	// Accounts that are not registered are not watched
+	tpub := "tpubD8eQVK4Kdxg5cRPQoGpdBSAZvwVfXXdsuWkJehjEyoEiEudkUgRUdhpQqEwewNYKYijHjgzsG1VRbXgcJF5oMBajAbJ5GNQ363sKLnLejHL" // nolint
+	account, err = i.GetWatchOnlyAccount(ctx, tpub)
+	assert.NoError(t, err)
+	assert.Nil(t, account)
+	_, _, err = i.GetWatchOnlyCoins(ctx, tpub)
+	assert.Error(t, err)
+
+	i.CloseDatabase(ctx)
+}
+
+func TestIndexer_Mempool(t *testing.T) {
+	// Create Indexer
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    dogecoin.TestnetNetwork,
+			Blockchain: dogecoin.Blockchain,
+		},
+		Params:                 dogecoin.TestnetParams,
+		Currency:               dogecoin.TestnetCurrency,
+		GenesisBlockIdentifier: dogecoin.TestnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	hash := func(s string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	}
+
+	accountA := &types.AccountIdentifier{Address: "nehiWbHwkjvD7drcqzbiJZwo5uCyQ7CCqf"}
+	accountB := &types.AccountIdentifier{Address: "nrGFcpVcWBEpoGKoN85f65qQTDNLs37bav"}
+	accountC := &types.AccountIdentifier{Address: "npk6udGxfGy8gjQ28R2giWYLoCq5tuEbtH"}
+
+	// Index a coin of account A
+	i.blockStorage.Initialize(i.workers)
+	block0 := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+		ParentBlockIdentifier: &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("funding")},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{
+							Index:        0,
+							NetworkIndex: &index0,
+						},
+						Status:  types.String(bitcoin.SuccessStatus),
+						Type:    bitcoin.OutputOpType,
+						Account: accountA,
+						Amount: &types.Amount{
+							Value:    "100000000",
+							Currency: dogecoin.TestnetCurrency,
+						},
+						CoinChange: &types.CoinChange{
+							CoinIdentifier: &types.CoinIdentifier{
+								Identifier: fmt.Sprintf("%s:%d", hash("funding"), index0),
+							},
+							CoinAction: types.CoinCreated,
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block0))
+	assert.NoError(t, i.BlockAdded(ctx, block0))
+
+	output := func(index int64, account *types.AccountIdentifier, value float64) *bitcoin.Output {
+		return &bitcoin.Output{
+			Value: value,
+			Index: index,
+			ScriptPubKey: &bitcoin.ScriptPubKey{
+				Type:      "pubkeyhash",
+				Addresses: []string{account.Address},
+			},
+		}
+	}
+
+	// The parent spends the coin of A and pays B and A. The
+	// child, which is listed first, spends the output of B
+	// and pays C. The orphan spends a coin that is not indexed.
+	parent := &bitcoin.Transaction{
+		Hash:    hash("parent"),
+		Inputs:  []*bitcoin.Input{{TxHash: hash("funding"), Vout: 0}},
+		Outputs: []*bitcoin.Output{output(0, accountB, 0.6), output(1, accountA, 0.4)},
+	}
+	child := &bitcoin.Transaction{
+		Hash:    hash("child"),
+		Inputs:  []*bitcoin.Input{{TxHash: hash("parent"), Vout: 0}},
+		Outputs: []*bitcoin.Output{output(0, accountC, 0.55)},
+	}
+	orphan := &bitcoin.Transaction{
+		Hash:    hash("orphan"),
+		Inputs:  []*bitcoin.Input{{TxHash: hash("missing"), Vout: 0}},
+		Outputs: []*bitcoin.Output{output(0, accountC, 1)},
+	}
+
+	parser := bitcoin.NewClient("", dogecoin.TestnetGenesisBlockIdentifier, dogecoin.TestnetCurrency)
+	parse := func(
+		ctx context.Context,
+		tx *bitcoin.Transaction,
+		coins map[string]*types.AccountCoin,
+	) *types.Transaction {
+		parsed, err := parser.ParseTransaction(ctx, tx, coins)
+		assert.NoError(t, err)
+		return parsed
+	}
+
+	// The mempool is not updated while syncing
+	mockClient.On("NetworkStatus", ctx).Return(&types.NetworkStatusResponse{
+		CurrentBlockIdentifier: &types.BlockIdentifier{Hash: getBlockHash(1), Index: 1},
+	}, nil).Once()
+	assert.NoError(t, i.updateMempool(ctx))
+
+	mockClient.On("NetworkStatus", ctx).Return(&types.NetworkStatusResponse{
+		CurrentBlockIdentifier: block0.BlockIdentifier,
+	}, nil).Twice()
+	mockClient.On("RawMempool", ctx).Return(
+		[]string{child.Hash, parent.Hash, orphan.Hash},
+		nil,
+	).Twice()
+	mockClient.On("GetRawTransaction", ctx, child.Hash).Return(child, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, parent.Hash).Return(parent, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, orphan.Hash).Return(orphan, nil).Once()
+	mockClient.On(
+		"ParseTransaction",
+		ctx,
+		mock.Anything,
+		mock.Anything,
+	).Return(parse, nil).Times(4)
+
+	assert.NoError(t, i.updateMempool(ctx))
+
+	coin := func(hash string, index int64, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: fmt.Sprintf("%s:%d", hash, index),
+			},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: dogecoin.TestnetCurrency,
+			},
+		}
+	}
+
+	// Coins created and spent within the mempool
+	// are in neither side of the overlay
+	coins, err := i.GetMempoolCoins(ctx, []*types.AccountIdentifier{accountA})
+	assert.NoError(t, err)
+	assert.Equal(t, &bitcoin.MempoolCoins{
+		Created: []*types.Coin{coin(parent.Hash, 1, "40000000")},
+		Spent:   []*types.Coin{coin(hash("funding"), 0, "100000000")},
+	}, coins)
+
+	coins, err = i.GetMempoolCoins(ctx, []*types.AccountIdentifier{accountB})
+	assert.NoError(t, err)
+	assert.Equal(t, &bitcoin.MempoolCoins{
+		Created: []*types.Coin{},
+		Spent:   []*types.Coin{},
+	}, coins)
+
+	coins, err = i.GetMempoolCoins(ctx, []*types.AccountIdentifier{accountC})
+	assert.NoError(t, err)
+	assert.Equal(t, &bitcoin.MempoolCoins{
+		Created: []*types.Coin{coin(child.Hash, 0, "55000000")},
+		Spent:   []*types.Coin{},
+	}, coins)
+
+	// Coins spent by mempool transactions are
+	// tracked even if they cannot be hydrated
+	for identifier, spender := range map[string]string{
+		fmt.Sprintf("%s:0", hash("funding")): parent.Hash,
+		fmt.Sprintf("%s:0", parent.Hash):     child.Hash,
+		fmt.Sprintf("%s:1", parent.Hash):     "",
+		fmt.Sprintf("%s:0", hash("missing")): orphan.Hash,
+	} {
+		hash, err := i.GetMempoolSpender(ctx, &types.CoinIdentifier{Identifier: identifier})
+		assert.NoError(t, err)
+		assert.Equal(t, spender, hash)
+	}
+
+	// Transactions that cannot be hydrated are only
+	// retried once the head changes
+	_, _, failed := i.mempool.snapshot()
+	assert.Equal(t, map[string]string{orphan.Hash: block0.BlockIdentifier.Hash}, failed)
+
+	// Known transactions are not fetched or parsed again
+	assert.NoError(t, i.updateMempool(ctx))
+
+	// Tracked transactions are returned hydrated
+	tx, err := i.GetMempoolTransaction(ctx, child.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, accountB, tx.Operations[0].Account)
+	assert.Equal(t, "-60000000", tx.Operations[0].Amount.Value)
+
+	// Other transactions are fetched with their mempool
+	// parents. The late parent spends the output of A.
+	lateParent := &bitcoin.Transaction{
+		Hash:    hash("late parent"),
+		Inputs:  []*bitcoin.Input{{TxHash: parent.Hash, Vout: 1}},
+		Outputs: []*bitcoin.Output{output(0, accountB, 0.3)},
+	}
+	late := &bitcoin.Transaction{
+		Hash:    hash("late"),
+		Inputs:  []*bitcoin.Input{{TxHash: lateParent.Hash, Vout: 0}},
+		Outputs: []*bitcoin.Output{output(0, accountC, 0.25)},
+	}
+	mockClient.On("GetRawTransaction", ctx, late.Hash).Return(late, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, lateParent.Hash).Return(lateParent, nil).Once()
+	tx, err = i.GetMempoolTransaction(ctx, late.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, late.Hash, tx.TransactionIdentifier.Hash)
+	assert.Equal(t, accountB, tx.Operations[0].Account)
+	assert.Equal(t, "-30000000", tx.Operations[0].Amount.Value)
+	assert.Equal(t, accountC, tx.Operations[1].Account)
+
+	notFound := &bitcoin.RPCError{
+		Code:    bitcoin.RPCInvalidAddressOrKeyErrCode,
+		Message: "No such mempool or blockchain transaction",
+	}
+	mockClient.On("GetRawTransaction", ctx, hash("unknown")).Return(nil, notFound).Once()
+	tx, err = i.GetMempoolTransaction(ctx, hash("unknown"))
+	assert.Nil(t, tx)
+	assert.True(t, errors.Is(err, notFound))
+
+	// Transactions in a block are not in the mempool, even
+	// when bitcoind returns them before the block is indexed
+	confirmed := &bitcoin.Transaction{
+		Hash:          hash("confirmed"),
+		Inputs:        []*bitcoin.Input{{TxHash: hash("funding"), Vout: 0}},
+		Outputs:       []*bitcoin.Output{output(0, accountC, 1)},
+		BlockHash:     getBlockHash(1),
+		Confirmations: 2,
+	}
+	mockClient.On("GetRawTransaction", ctx, confirmed.Hash).Return(confirmed, nil).Once()
+	mockClient.On("NetworkStatus", ctx).Return(&types.NetworkStatusResponse{
+		CurrentBlockIdentifier: &types.BlockIdentifier{Hash: getBlockHash(2), Index: 2},
+	}, nil).Once()
+	tx, err = i.GetMempoolTransaction(ctx, confirmed.Hash)
+	assert.Nil(t, tx)
+	var confirmedErr *bitcoin.ConfirmedTransactionError
+	assert.True(t, errors.As(err, &confirmedErr))
+	assert.Equal(t, &types.BlockIdentifier{Hash: getBlockHash(1), Index: 1}, confirmedErr.Block)
+	assert.Equal(t, int64(2), confirmedErr.Confirmations)
+
+	// Orphans cannot be hydrated
+	mockClient.On("GetRawTransaction", ctx, orphan.Hash).Return(orphan, nil).Once()
+	mockClient.On("GetRawTransaction", ctx, hash("missing")).Return(nil, notFound).Once()
+	tx, err = i.GetMempoolTransaction(ctx, orphan.Hash)
+	assert.Nil(t, tx)
+	assert.Error(t, err)
+
+	// Transactions included in a block leave the mempool
+	parsedParent := parse(ctx, parent, map[string]*types.AccountCoin{
+		fmt.Sprintf("%s:0", hash("funding")): {
+			Account: accountA,
+			Coin:    coin(hash("funding"), 0, "100000000"),
+		},
+	})
+	block1 := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(1), Index: 1},
+		ParentBlockIdentifier: block0.BlockIdentifier,
+		Transactions:          []*types.Transaction{parsedParent},
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block1))
+	assert.NoError(t, i.BlockAdded(ctx, block1))
+
+	coins, err = i.GetMempoolCoins(ctx, []*types.AccountIdentifier{accountA})
+	assert.NoError(t, err)
+	assert.Equal(t, &bitcoin.MempoolCoins{
+		Created: []*types.Coin{},
+		Spent:   []*types.Coin{},
+	}, coins)
+
+	coins, err = i.GetMempoolCoins(ctx, []*types.AccountIdentifier{accountB})
+	assert.NoError(t, err)
+	assert.Equal(t, &bitcoin.MempoolCoins{
+		Created: []*types.Coin{},
+		Spent:   []*types.Coin{coin(parent.Hash, 0, "60000000")},
+	}, coins)
+
+	spender, err := i.GetMempoolSpender(ctx, &types.CoinIdentifier{
+		Identifier: fmt.Sprintf("%s:0", hash("funding")),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, spender)
+
+	// Transactions included in a block while an update is
+	// built are not added back by the update
+	raw, transactions, failed := i.mempool.snapshot()
+	i.mempool.startUpdate()
+	i.mempool.remove([]string{child.Hash})
+	i.mempool.update(raw, transactions, failed)
+	i.mempool.stopUpdate()
+	_, transactions, _ = i.mempool.snapshot()
+	assert.NotContains(t, transactions, child.Hash)
+
+	mockClient.AssertExpectations(t)
+	i.CloseDatabase(ctx)
+}
+
+func TestIndexer_AddressIndex(t *testing.T) {
+	// Create Indexer
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    dogecoin.TestnetNetwork,
+			Blockchain: dogecoin.Blockchain,
+		},
+		Params:                 dogecoin.TestnetParams,
+		Currency:               dogecoin.TestnetCurrency,
+		GenesisBlockIdentifier: dogecoin.TestnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+		AddressIndex:           true,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	hash := func(s string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	}
+
+	accountA := &types.AccountIdentifier{Address: "nehiWbHwkjvD7drcqzbiJZwo5uCyQ7CCqf"}
+	accountB := &types.AccountIdentifier{Address: "nrGFcpVcWBEpoGKoN85f65qQTDNLs37bav"}
+	accountC := &types.AccountIdentifier{Address: "npk6udGxfGy8gjQ28R2giWYLoCq5tuEbtH"}
+
+	operation := func(
+		index int64,
+		opType string,
+		account *types.AccountIdentifier,
+		value string,
+		coin string,
+		action types.CoinAction,
+	) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: index},
+			Status:              types.String(bitcoin.SuccessStatus),
+			Type:                opType,
+			Account:             account,
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: coin},
+				CoinAction:     action,
+			},
+		}
+	}
+
+	// A is funded in block 0, pays B in block 1,
+	// and B pays C in block 2.
+	funding := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("funding")},
+		Operations: []*types.Operation{
+			operation(0, bitcoin.OutputOpType, accountA, "100000000", hash("funding")+":0", types.CoinCreated),
+		},
+	}
+	spend := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("spend")},
+		Operations: []*types.Operation{
+			operation(0, bitcoin.InputOpType, accountA, "-100000000", hash("funding")+":0", types.CoinSpent),
+			operation(1, bitcoin.OutputOpType, accountB, "60000000", hash("spend")+":0", types.CoinCreated),
+			operation(2, bitcoin.OutputOpType, accountA, "40000000", hash("spend")+":1", types.CoinCreated),
+		},
+	}
+	pay := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("pay")},
+		Operations: []*types.Operation{
+			operation(0, bitcoin.InputOpType, accountB, "-60000000", hash("spend")+":0", types.CoinSpent),
+			operation(1, bitcoin.OutputOpType, accountC, "55000000", hash("pay")+":0", types.CoinCreated),
+		},
+	}
+
+	i.blockStorage.Initialize(i.workers)
+	blocks := []*types.Block{}
+	for index, tx := range []*types.Transaction{funding, spend, pay} {
+		parent := &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0}
+		if index > 0 {
+			parent = blocks[index-1].BlockIdentifier
+		}
+
+		block := &types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(int64(index)), Index: int64(index)},
+			ParentBlockIdentifier: parent,
+			Transactions:          []*types.Transaction{tx},
+		}
+		assert.NoError(t, i.BlockSeen(ctx, block))
+		assert.NoError(t, i.BlockAdded(ctx, block))
+		blocks = append(blocks, block)
+	}
+
+	blockTransaction := func(block *types.Block) *types.BlockTransaction {
+		return &types.BlockTransaction{
+			BlockIdentifier: block.BlockIdentifier,
+			Transaction:     block.Transactions[0],
+		}
+	}
+
+	address := accountA.Address
+	inputType := bitcoin.InputOpType
+	or := types.OR
+	successful := true
+	failed := false
+	limit := int64(1)
+	offset := int64(1)
+	maxBlock := int64(0)
+	tests := map[string]struct {
+		request *types.SearchTransactionsRequest
+
+		expected *types.SearchTransactionsResponse
+	}{
+		"address": {
+			request: &types.SearchTransactionsRequest{
+				Address: &address,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[1]),
+					blockTransaction(blocks[0]),
+				},
+				TotalCount: 2,
+			},
+		},
+		"account and type": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: accountA,
+				Type:              &inputType,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[1]),
+				},
+				TotalCount: 2,
+			},
+		},
+		"coin": {
+			request: &types.SearchTransactionsRequest{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: hash("funding") + ":0"},
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[1]),
+					blockTransaction(blocks[0]),
+				},
+				TotalCount: 2,
+			},
+		},
+		"transaction": {
+			request: &types.SearchTransactionsRequest{
+				TransactionIdentifier: pay.TransactionIdentifier,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[2]),
+				},
+				TotalCount: 1,
+			},
+		},
+		"successful": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: accountB,
+				Success:           &successful,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[2]),
+					blockTransaction(blocks[1]),
+				},
+				TotalCount: 2,
+			},
+		},
+		"failed": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: accountB,
+				Success:           &failed,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{},
+				TotalCount:   2,
+			},
+		},
+		"and": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: accountC,
+				CoinIdentifier:    &types.CoinIdentifier{Identifier: hash("funding") + ":0"},
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{},
+			},
+		},
+		"or": {
+			request: &types.SearchTransactionsRequest{
+				Operator:          &or,
+				AccountIdentifier: accountC,
+				CoinIdentifier:    &types.CoinIdentifier{Identifier: hash("funding") + ":0"},
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[2]),
+					blockTransaction(blocks[1]),
+					blockTransaction(blocks[0]),
+				},
+				TotalCount: 3,
+			},
+		},
+		"first page": {
+			request: &types.SearchTransactionsRequest{
+				Address: &address,
+				Limit:   &limit,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[1]),
+				},
+				TotalCount: 2,
+				NextOffset: &offset,
+			},
+		},
+		"last page": {
+			request: &types.SearchTransactionsRequest{
+				Address: &address,
+				Limit:   &limit,
+				Offset:  &offset,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[0]),
+				},
+				TotalCount: 2,
+			},
+		},
+		"filtered page": {
+			request: &types.SearchTransactionsRequest{
+				AccountIdentifier: accountB,
+				Success:           &successful,
+				Limit:             &limit,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[2]),
+				},
+				TotalCount: 2,
+				NextOffset: &offset,
+			},
+		},
+		"max block": {
+			request: &types.SearchTransactionsRequest{
+				Address:  &address,
+				MaxBlock: &maxBlock,
+			},
+			expected: &types.SearchTransactionsResponse{
+				Transactions: []*types.BlockTransaction{
+					blockTransaction(blocks[0]),
+				},
+				TotalCount: 1,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := i.SearchTransactions(ctx, test.request)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, response)
+		})
+	}
+
+	_, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{Type: &inputType})
+	assert.True(t, errors.Is(err, errMissingSearchCondition))
+
+	// Removing block 2 removes pay from the index
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2].BlockIdentifier))
+
+	response, err := i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		AccountIdentifier: accountC,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.SearchTransactionsResponse{
+		Transactions: []*types.BlockTransaction{},
+	}, response)
+
+	response, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		AccountIdentifier: accountB,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.SearchTransactionsResponse{
+		Transactions: []*types.BlockTransaction{
+			blockTransaction(blocks[1]),
+		},
+		TotalCount: 1,
+	}, response)
+
+	// Blocks added while the index is disabled are not in
+	// it, so it starts again at the next block once enabled
+	i.CloseDatabase(ctx)
+	cfg.AddressIndex = false
+	i, err = Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+	assert.NoError(t, i.BlockSeen(ctx, blocks[2]))
+	assert.NoError(t, i.BlockAdded(ctx, blocks[2]))
+
+	i.CloseDatabase(ctx)
+	cfg.AddressIndex = true
+	i, err = Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize(i.workers)
+
+	_, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		AccountIdentifier: accountC,
+	})
+	assert.Equal(t, &bitcoin.HistoryNotIndexedError{Start: 3}, err)
+
+	forward := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("forward")},
+		Operations: []*types.Operation{
+			operation(0, bitcoin.InputOpType, accountC, "-55000000", hash("pay")+":0", types.CoinSpent),
+			operation(1, bitcoin.OutputOpType, accountC, "50000000", hash("forward")+":0", types.CoinCreated),
+		},
+	}
+	back := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("back")},
+		Operations: []*types.Operation{
+			operation(0, bitcoin.InputOpType, accountC, "-50000000", hash("forward")+":0", types.CoinSpent),
+			operation(1, bitcoin.OutputOpType, accountC, "45000000", hash("back")+":0", types.CoinCreated),
+		},
+	}
+	block3 := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(3), Index: 3},
+		ParentBlockIdentifier: blocks[2].BlockIdentifier,
+		Transactions:          []*types.Transaction{forward, back},
+	}
+	assert.NoError(t, i.BlockSeen(ctx, block3))
+	assert.NoError(t, i.BlockAdded(ctx, block3))
+
+	// Pages are returned until the search
+	// reaches the blocks that are not indexed
+	first := forward
+	if hash("back") > hash("forward") {
+		first = back
+	}
+	response, err = i.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		AccountIdentifier: accountC,
+		Limit:             &limit,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.SearchTransactionsResponse{
+		Transactions: []*types.BlockTransaction{
+			{BlockIdentifier: block3.BlockIdentifier, Transaction: first},
+		},
+		TotalCount: 2,
+		NextOffset: &offset,
+	}, response)
+
+	for _, request := range []*types.SearchTransactionsRequest{
+		{AccountIdentifier: accountC, Limit: &limit, Offset: &offset},
+		{AccountIdentifier: accountA, MaxBlock: &maxBlock},
+	} {
+		_, err = i.SearchTransactions(ctx, request)
+		assert.Equal(t, &bitcoin.HistoryNotIndexedError{Start: 3}, err)
+	}
+
+	mockClient.AssertExpectations(t)
+	i.CloseDatabase(ctx)
+}
+
+func TestIndexer_CoinMaturity(t *testing.T) {
+	// Create Indexer
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	// Coinbase outputs created at height h can
+	// be spent in blocks from height h+3.
+	params := *dogecoin.TestnetParams
+	params.CoinbaseMaturity = 3
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    dogecoin.TestnetNetwork,
+			Blockchain: dogecoin.Blockchain,
+		},
+		Params:                 &params,
+		Currency:               dogecoin.TestnetCurrency,
+		GenesisBlockIdentifier: dogecoin.TestnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	hash := func(s string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	}
+
+	account := &types.AccountIdentifier{Address: "nehiWbHwkjvD7drcqzbiJZwo5uCyQ7CCqf"}
+	output := func(txHash string) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        1,
+				NetworkIndex: &index0,
+			},
+			Status:  types.String(bitcoin.SuccessStatus),
+			Type:    bitcoin.OutputOpType,
+			Account: account,
+			Amount: &types.Amount{
+				Value:    "100000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: fmt.Sprintf("%s:%d", txHash, index0),
+				},
+				CoinAction: types.CoinCreated,
+			},
+		}
+	}
+	coin := func(txHash string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: fmt.Sprintf("%s:%d", txHash, index0),
+			},
+			Amount: &types.Amount{
+				Value:    "100000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		}
+	}
+
+	// Each block has a coinbase transaction paying
+	// the account, block 0 also has a regular one.
+	i.blockStorage.Initialize(i.workers)
+	blocks := []*types.Block{}
+	for index := int64(0); index < 3; index++ {
+		coinbaseHash := hash(fmt.Sprintf("coinbase %d", index))
+		block := &types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Hash: getBlockHash(index), Index: index},
+			ParentBlockIdentifier: &types.BlockIdentifier{Hash: getBlockHash(0), Index: 0},
+			Transactions: []*types.Transaction{
+				{
+					TransactionIdentifier: &types.TransactionIdentifier{Hash: coinbaseHash},
+					Operations: []*types.Operation{
+						{
+							OperationIdentifier: &types.OperationIdentifier{
+								Index:        0,
+								NetworkIndex: &index0,
+							},
+							Status: types.String(bitcoin.SuccessStatus),
+							Type:   bitcoin.CoinbaseOpType,
+						},
+						output(coinbaseHash),
+					},
+				},
+			},
+		}
+		if index > 0 {
+			block.ParentBlockIdentifier = blocks[index-1].BlockIdentifier
+		} else {
+			block.Transactions = append(block.Transactions, &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: hash("regular")},
+				Operations:            []*types.Operation{output(hash("regular"))},
+			})
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	coins := []*types.Coin{
+		coin(hash("coinbase 0")),
+		coin(hash("coinbase 1")),
+		coin(hash("regular")),
+		coin(hash("mempool")),
+	}
+
+	expected := [][]int64{
+		{3, 0, 0, 0}, // coinbase 1 is not indexed yet
+		{3, 4, 0, 0},
+		{0, 4, 0, 0},
+	}
+	for index, block := range blocks {
+		assert.NoError(t, i.BlockSeen(ctx, block))
+		assert.NoError(t, i.BlockAdded(ctx, block))
+
+		maturities, err := i.GetCoinMaturities(ctx, coins)
+		assert.NoError(t, err)
+		assert.Equal(t, expected[index], maturities)
+	}
+
+	// Coinbase outputs become immature again
+	// when the head block is orphaned.
+	assert.NoError(t, i.BlockRemoved(ctx, blocks[2].BlockIdentifier))
+	maturities, err := i.GetCoinMaturities(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4, 0, 0}, maturities)
+
+	// The coinbase transactions of blocks added before
+	// they were recorded are indexed again on startup.
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	assert.NoError(t, dbTx.Delete(ctx, getCoinbaseKey(hash("coinbase 1"))))
+	assert.NoError(t, dbTx.Commit(ctx))
+	maturities, err = i.GetCoinMaturities(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 0, 0, 0}, maturities)
+
+	assert.NoError(t, i.indexRecentCoinbases(ctx, blocks[1].BlockIdentifier))
+	maturities, err = i.GetCoinMaturities(ctx, coins)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4, 0, 0}, maturities)
+
+	mockClient.AssertExpectations(t)
+	i.CloseDatabase(ctx)
+}
@@ -0,0 +1,636 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/utils"
+
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// mempoolFrequency is how often the
+	// mempool of bitcoind is polled.
+	mempoolFrequency = 10 * time.Second
+
+	// maxMempoolAncestors is the maximum number of
+	// unconfirmed ancestors of a mempool transaction
+	// (the default -limitancestorcount of dogecoind).
+	maxMempoolAncestors = 25
+)
+
+// mempoolTracker keeps the transactions in the mempool of
+// bitcoind and an overlay of the coins they create and
+// spend, keyed by account address. The mempool is not
+// persisted: it is rebuilt from bitcoind on restart.
+type mempoolTracker struct {
+	mutex sync.RWMutex
+
+	// raw are the decoded transactions in the mempool.
+	// They are kept so each transaction is only fetched
+	// once, even if its inputs cannot be hydrated yet.
+	raw map[string]*bitcoin.Transaction
+
+	// transactions are the mempool transactions
+	// whose inputs are hydrated.
+	transactions map[string]*types.Transaction
+
+	// failed maps the transactions whose inputs could not
+	// be hydrated to the head block of the failed attempt,
+	// so they are only hydrated again once it changes.
+	failed map[string]string
+
+	// removed are the transactions included in blocks
+	// while an update is built, which are dropped from
+	// the update in case it was built before they were.
+	updating bool
+	removed  map[string]struct{}
+
+	created map[string]map[string]*types.Coin
+	spent   map[string]map[string]*types.Coin
+
+	// spenders maps the coins spent by mempool transactions,
+	// hydrated or not, to the transaction spending them.
+	spenders map[string]string
+}
+
+func newMempoolTracker() *mempoolTracker {
+	return &mempoolTracker{
+		raw:          map[string]*bitcoin.Transaction{},
+		transactions: map[string]*types.Transaction{},
+		failed:       map[string]string{},
+		removed:      map[string]struct{}{},
+		created:      map[string]map[string]*types.Coin{},
+		spent:        map[string]map[string]*types.Coin{},
+		spenders:     map[string]string{},
+	}
+}
+
+// addCoin adds a coin to the coins of an account.
+func addCoin(
+	coins map[string]map[string]*types.Coin,
+	account *types.AccountIdentifier,
+	coin *types.Coin,
+) {
+	address := account.Address
+	if _, ok := coins[address]; !ok {
+		coins[address] = map[string]*types.Coin{}
+	}
+
+	coins[address][coin.CoinIdentifier.Identifier] = coin
+}
+
+// coinOverlay returns the coins created and spent by
+// mempool transactions. Coins created and spent within
+// the mempool are in neither side of the overlay.
+func coinOverlay(
+	transactions map[string]*types.Transaction,
+) (map[string]map[string]*types.Coin, map[string]map[string]*types.Coin) {
+	created := map[string]map[string]*types.Coin{}
+	spent := map[string]map[string]*types.Coin{}
+	spentInMempool := map[string]struct{}{}
+	for _, tx := range transactions {
+		for _, op := range tx.Operations {
+			if op.CoinChange == nil || op.CoinChange.CoinAction != types.CoinSpent {
+				continue
+			}
+
+			spentInMempool[op.CoinChange.CoinIdentifier.Identifier] = struct{}{}
+		}
+	}
+
+	for _, tx := range transactions {
+		for _, op := range tx.Operations {
+			if op.CoinChange == nil {
+				continue
+			}
+
+			identifier := op.CoinChange.CoinIdentifier.Identifier
+			switch op.CoinChange.CoinAction {
+			case types.CoinCreated:
+				if _, ok := spentInMempool[identifier]; ok {
+					continue
+				}
+
+				addCoin(created, op.Account, &types.Coin{
+					CoinIdentifier: op.CoinChange.CoinIdentifier,
+					Amount:         op.Amount,
+				})
+			case types.CoinSpent:
+				if _, ok := transactions[bitcoin.TransactionHash(identifier)]; ok {
+					continue
+				}
+
+				amount, err := types.NegateValue(op.Amount.Value)
+				if err != nil {
+					continue
+				}
+
+				addCoin(spent, op.Account, &types.Coin{
+					CoinIdentifier: op.CoinChange.CoinIdentifier,
+					Amount: &types.Amount{
+						Value:    amount,
+						Currency: op.Amount.Currency,
+					},
+				})
+			}
+		}
+	}
+
+	return created, spent
+}
+
+// coinSpenders returns the hash of the transaction
+// spending each coin spent by mempool transactions.
+func coinSpenders(raw map[string]*bitcoin.Transaction) map[string]string {
+	spenders := map[string]string{}
+	for hash, tx := range raw {
+		for _, input := range tx.Inputs {
+			if len(input.TxHash) == 0 {
+				continue
+			}
+
+			spenders[bitcoin.CoinIdentifier(input.TxHash, input.Vout)] = hash
+		}
+	}
+
+	return spenders
+}
+
+// startUpdate starts recording the removed
+// transactions until stopUpdate is called.
+func (m *mempoolTracker) startUpdate() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.updating = true
+}
+
+// stopUpdate stops recording removed transactions.
+func (m *mempoolTracker) stopUpdate() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.updating = false
+	m.removed = map[string]struct{}{}
+}
+
+// update replaces the mempool transactions, without the
+// ones removed since startUpdate, and rebuilds the coin
+// overlay.
+func (m *mempoolTracker) update(
+	raw map[string]*bitcoin.Transaction,
+	transactions map[string]*types.Transaction,
+	failed map[string]string,
+) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for hash := range m.removed {
+		delete(raw, hash)
+		delete(transactions, hash)
+		delete(failed, hash)
+	}
+
+	m.raw = raw
+	m.transactions = transactions
+	m.failed = failed
+	m.created, m.spent = coinOverlay(transactions)
+	m.spenders = coinSpenders(raw)
+}
+
+// remove forgets mempool transactions
+// and rebuilds the coin overlay.
+func (m *mempoolTracker) remove(hashes []string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, hash := range hashes {
+		delete(m.raw, hash)
+		delete(m.transactions, hash)
+		delete(m.failed, hash)
+		if m.updating {
+			m.removed[hash] = struct{}{}
+		}
+	}
+
+	m.created, m.spent = coinOverlay(m.transactions)
+	m.spenders = coinSpenders(m.raw)
+}
+
+// snapshot returns copies of the mempool transactions
+// and of the transactions that could not be hydrated.
+func (m *mempoolTracker) snapshot() (
+	map[string]*bitcoin.Transaction,
+	map[string]*types.Transaction,
+	map[string]string,
+) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	raw := make(map[string]*bitcoin.Transaction, len(m.raw))
+	for hash, tx := range m.raw {
+		raw[hash] = tx
+	}
+
+	transactions := make(map[string]*types.Transaction, len(m.transactions))
+	for hash, tx := range m.transactions {
+		transactions[hash] = tx
+	}
+
+	failed := make(map[string]string, len(m.failed))
+	for hash, head := range m.failed {
+		failed[hash] = head
+	}
+
+	return raw, transactions, failed
+}
+
+// coins returns the coins created and spent
+// in the mempool by the provided accounts.
+func (m *mempoolTracker) coins(
+	accounts []*types.AccountIdentifier,
+) *bitcoin.MempoolCoins {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	mempoolCoins := &bitcoin.MempoolCoins{
+		Created: []*types.Coin{},
+		Spent:   []*types.Coin{},
+	}
+	for _, account := range accounts {
+		for _, coin := range m.created[account.Address] {
+			mempoolCoins.Created = append(mempoolCoins.Created, coin)
+		}
+
+		for _, coin := range m.spent[account.Address] {
+			mempoolCoins.Spent = append(mempoolCoins.Spent, coin)
+		}
+	}
+
+	return mempoolCoins
+}
+
+// spender returns the hash of the mempool transaction
+// spending a coin, or an empty string if there is none.
+func (m *mempoolTracker) spender(identifier string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.spenders[identifier]
+}
+
+// hydrateMempoolTransaction parses a mempool transaction, looking
+// up the coins it spends in the outputs of other hydrated mempool
+// transactions and in coin storage. It returns nil if a spent coin
+// cannot be found, like when the block that created it is not
+// indexed yet.
+func (i *Indexer) hydrateMempoolTransaction(
+	ctx context.Context,
+	tx *bitcoin.Transaction,
+	transactions map[string]*types.Transaction,
+) (*types.Transaction, error) {
+	coins := map[string]*types.AccountCoin{}
+	for _, input := range tx.Inputs {
+		identifier := bitcoin.CoinIdentifier(input.TxHash, input.Vout)
+		if parent, ok := transactions[input.TxHash]; ok {
+			for _, op := range parent.Operations {
+				if op.CoinChange == nil ||
+					op.CoinChange.CoinAction != types.CoinCreated ||
+					op.CoinChange.CoinIdentifier.Identifier != identifier {
+					continue
+				}
+
+				coins[identifier] = &types.AccountCoin{
+					Account: op.Account,
+					Coin: &types.Coin{
+						CoinIdentifier: op.CoinChange.CoinIdentifier,
+						Amount:         op.Amount,
+					},
+				}
+			}
+
+			continue
+		}
+
+		coin, owner, err := i.GetCoin(ctx, &types.CoinIdentifier{Identifier: identifier})
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get coin %s", err, identifier)
+		}
+
+		if coin == nil {
+			return nil, nil
+		}
+
+		coins[identifier] = &types.AccountCoin{
+			Account: owner,
+			Coin:    coin,
+		}
+	}
+
+	return i.client.ParseTransaction(ctx, tx, coins)
+}
+
+// updateMempool fetches the new transactions in the mempool
+// of bitcoind, hydrates them, and forgets the transactions
+// that left the mempool. The mempool is not updated while the
+// indexer is behind bitcoind, as the coins spent by mempool
+// transactions are not all indexed yet.
+func (i *Indexer) updateMempool(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "mempool")
+
+	head, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
+	if errors.Is(err, storageErrs.ErrHeadBlockNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	status, err := i.client.NetworkStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get network status", err)
+	}
+
+	if head.Index < status.CurrentBlockIdentifier.Index {
+		logger.Debugw(
+			"skipping mempool update while syncing",
+			"index", head.Index,
+			"node index", status.CurrentBlockIdentifier.Index,
+		)
+		return nil
+	}
+
+	i.mempool.startUpdate()
+	defer i.mempool.stopUpdate()
+
+	hashes, err := i.client.RawMempool(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get mempool", err)
+	}
+
+	knownRaw, knownTransactions, knownFailed := i.mempool.snapshot()
+	raw := make(map[string]*bitcoin.Transaction, len(hashes))
+	transactions := make(map[string]*types.Transaction, len(hashes))
+	for _, hash := range hashes {
+		if tx, ok := knownTransactions[hash]; ok {
+			transactions[hash] = tx
+		}
+
+		if tx, ok := knownRaw[hash]; ok {
+			raw[hash] = tx
+			continue
+		}
+
+		// The transaction may have left the mempool
+		// since it was listed.
+		tx, err := i.client.GetRawTransaction(ctx, hash)
+		if err != nil {
+			logger.Debugw("unable to get mempool transaction", "hash", hash, "error", err)
+			continue
+		}
+
+		if inBlock(tx) {
+			logger.Debugw("mempool transaction is confirmed", "hash", hash, "block", tx.BlockHash)
+			continue
+		}
+
+		raw[hash] = tx
+	}
+
+	// Transactions spending the outputs of other mempool
+	// transactions can only be hydrated after their parents,
+	// which may be listed after them. Transactions that could
+	// not be hydrated at the same head are only retried once
+	// one of their parents is hydrated.
+	hydrated := map[string]struct{}{}
+	retry := func(tx *bitcoin.Transaction) bool {
+		if knownFailed[tx.Hash] != head.Hash {
+			return true
+		}
+
+		for _, input := range tx.Inputs {
+			if _, ok := hydrated[input.TxHash]; ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for progress := true; progress; {
+		progress = false
+		for hash, tx := range raw {
+			if _, ok := transactions[hash]; ok || !retry(tx) {
+				continue
+			}
+
+			parsed, err := i.hydrateMempoolTransaction(ctx, tx, transactions)
+			if err != nil {
+				return err
+			}
+
+			if parsed != nil {
+				transactions[hash] = parsed
+				hydrated[hash] = struct{}{}
+				progress = true
+			}
+		}
+	}
+
+	failed := map[string]string{}
+	for hash := range raw {
+		if _, ok := transactions[hash]; !ok {
+			failed[hash] = head.Hash
+		}
+	}
+
+	i.mempool.update(raw, transactions, failed)
+	return nil
+}
+
+// removeMempoolTransactions forgets the mempool
+// transactions included in a block, which bitcoind
+// removes from its mempool.
+func (i *Indexer) removeMempoolTransactions(block *types.Block) {
+	hashes := make([]string, len(block.Transactions))
+	for j, tx := range block.Transactions {
+		hashes[j] = tx.TransactionIdentifier.Hash
+	}
+
+	i.mempool.remove(hashes)
+}
+
+// GetMempoolCoins returns the coins created and spent by
+// the accounts in transactions that are in the mempool.
+func (i *Indexer) GetMempoolCoins(
+	ctx context.Context,
+	accounts []*types.AccountIdentifier,
+) (*bitcoin.MempoolCoins, error) {
+	return i.mempool.coins(accounts), nil
+}
+
+// GetMempoolSpender returns the hash of the transaction in the
+// mempool spending a coin, or an empty string if no mempool
+// transaction spends it.
+func (i *Indexer) GetMempoolSpender(
+	ctx context.Context,
+	coinIdentifier *types.CoinIdentifier,
+) (string, error) {
+	return i.mempool.spender(coinIdentifier.Identifier), nil
+}
+
+// inBlock returns whether a transaction returned by getrawtransaction
+// is included in a block, which bitcoind returns with a transaction
+// index or before the block is indexed.
+func inBlock(tx *bitcoin.Transaction) bool {
+	return len(tx.BlockHash) > 0 || tx.Confirmations > 0
+}
+
+// confirmedTransaction returns the *bitcoin.ConfirmedTransactionError
+// of a transaction included in a block. The height of the block
+// is derived from the confirmations and the tip of bitcoind.
+func (i *Indexer) confirmedTransaction(
+	ctx context.Context,
+	tx *bitcoin.Transaction,
+) error {
+	status, err := i.client.NetworkStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get network status", err)
+	}
+
+	return &bitcoin.ConfirmedTransactionError{
+		Hash: tx.Hash,
+		Block: &types.BlockIdentifier{
+			Hash:  tx.BlockHash,
+			Index: status.CurrentBlockIdentifier.Index - tx.Confirmations + 1,
+		},
+		Confirmations: tx.Confirmations,
+	}
+}
+
+// fetchMempoolTransaction fetches a mempool transaction from
+// bitcoind and hydrates it. Parents that are in the mempool but
+// not in transactions are fetched and added to transactions.
+func (i *Indexer) fetchMempoolTransaction(
+	ctx context.Context,
+	hash string,
+	transactions map[string]*types.Transaction,
+	depth int,
+) (*types.Transaction, error) {
+	tx, err := i.client.GetRawTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if inBlock(tx) {
+		return nil, i.confirmedTransaction(ctx, tx)
+	}
+
+	for _, input := range tx.Inputs {
+		if _, ok := transactions[input.TxHash]; ok {
+			continue
+		}
+
+		identifier := bitcoin.CoinIdentifier(input.TxHash, input.Vout)
+		coin, _, err := i.GetCoin(ctx, &types.CoinIdentifier{Identifier: identifier})
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get coin %s", err, identifier)
+		}
+
+		if coin != nil {
+			continue
+		}
+
+		if depth == 0 {
+			return nil, fmt.Errorf("%s has more than %d mempool ancestors", hash, maxMempoolAncestors)
+		}
+
+		parent, err := i.fetchMempoolTransaction(ctx, input.TxHash, transactions, depth-1)
+		var confirmedErr *bitcoin.ConfirmedTransactionError
+		if errors.As(err, &confirmedErr) {
+			// The transaction itself may still be in the mempool.
+			return nil, fmt.Errorf(
+				"parent %s of %s is in block %s, which is not indexed",
+				input.TxHash,
+				hash,
+				confirmedErr.Block.Hash,
+			)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get parent %s of %s", err, input.TxHash, hash)
+		}
+
+		transactions[input.TxHash] = parent
+	}
+
+	parsed, err := i.hydrateMempoolTransaction(ctx, tx, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed == nil {
+		return nil, fmt.Errorf("unable to find the coins spent by %s", hash)
+	}
+
+	return parsed, nil
+}
+
+// GetMempoolTransaction returns the mempool transaction with
+// the provided hash. Transactions that are not tracked yet
+// are fetched from bitcoind and hydrated with the coins in
+// coin storage and the outputs of their mempool parents.
+// Transactions already included in a block are not in the
+// mempool and return a *bitcoin.ConfirmedTransactionError.
+func (i *Indexer) GetMempoolTransaction(
+	ctx context.Context,
+	hash string,
+) (*types.Transaction, error) {
+	_, transactions, _ := i.mempool.snapshot()
+	if tx, ok := transactions[hash]; ok {
+		return tx, nil
+	}
+
+	return i.fetchMempoolTransaction(ctx, hash, transactions, maxMempoolAncestors)
+}
+
+// TrackMempool polls the mempool of bitcoind
+// every mempoolFrequency.
+func (i *Indexer) TrackMempool(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "mempool")
+
+	tc := time.NewTicker(mempoolFrequency)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Warnw("exiting mempool tracker")
+			return ctx.Err()
+		case <-tc.C:
+			if err := i.updateMempool(ctx); err != nil {
+				logger.Warnw("unable to update mempool", "error", err)
+			}
+		}
+	}
+}
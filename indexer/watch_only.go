@@ -0,0 +1,465 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// watchOnlyNamespace is prepended to the
+	// key of each watch-only account.
+	watchOnlyNamespace = "watch"
+)
+
+func getWatchOnlyPrefix() []byte {
+	return []byte(fmt.Sprintf("%s/", watchOnlyNamespace))
+}
+
+func getWatchOnlyKey(extendedKey string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", watchOnlyNamespace, extendedKey))
+}
+
+// getWatchOnlyAccount returns the watch-only account of an
+// extended key or nil if it is not registered.
+func (i *Indexer) getWatchOnlyAccount(
+	ctx context.Context,
+	dbTx database.Transaction,
+	extendedKey string,
+) (*bitcoin.WatchOnlyAccount, error) {
+	exists, val, err := dbTx.Get(ctx, getWatchOnlyKey(extendedKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get watch-only account %s", err, extendedKey)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	var account bitcoin.WatchOnlyAccount
+	if err := i.database.Encoder().Decode("", val, &account, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode watch-only account %s", err, extendedKey)
+	}
+
+	return &account, nil
+}
+
+// setWatchOnlyAccount stores a watch-only account.
+func (i *Indexer) setWatchOnlyAccount(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *bitcoin.WatchOnlyAccount,
+) error {
+	encoded, err := i.database.Encoder().Encode("", account)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode watch-only account %s", err, account.ExtendedKey)
+	}
+
+	if err := dbTx.Set(ctx, getWatchOnlyKey(account.ExtendedKey), encoded, false); err != nil {
+		return fmt.Errorf("%w: unable to store watch-only account %s", err, account.ExtendedKey)
+	}
+
+	return nil
+}
+
+// getWatchOnlyAccounts returns all watch-only accounts.
+func (i *Indexer) getWatchOnlyAccounts(
+	ctx context.Context,
+	dbTx database.Transaction,
+) ([]*bitcoin.WatchOnlyAccount, error) {
+	accounts := []*bitcoin.WatchOnlyAccount{}
+	_, err := dbTx.Scan(
+		ctx,
+		getWatchOnlyPrefix(),
+		getWatchOnlyPrefix(),
+		func(k []byte, v []byte) error {
+			var account bitcoin.WatchOnlyAccount
+			// We should not reclaim memory during a scan!!
+			if err := i.database.Encoder().Decode("", v, &account, false); err != nil {
+				return fmt.Errorf("%w: unable to decode watch-only account %s", err, string(k))
+			}
+
+			accounts = append(accounts, &account)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan watch-only accounts", err)
+	}
+
+	return accounts, nil
+}
+
+// extendChain derives addresses on a chain of a watch-only
+// account until gapLimit addresses after the last used one
+// are unused. used reports whether a newly derived address
+// has seen activity.
+func (i *Indexer) extendChain(
+	accountKey *hdkeychain.ExtendedKey,
+	chain *bitcoin.WatchOnlyChain,
+	branch uint32,
+	gapLimit int64,
+	used func(string) (bool, error),
+) error {
+	for int64(len(chain.Addresses)) < chain.LastUsed+1+gapLimit {
+		index := uint32(len(chain.Addresses))
+		address, err := bitcoin.DeriveAddress(i.params, accountKey, branch, index)
+		if err != nil {
+			return fmt.Errorf("%w: unable to derive address %d/%d", err, branch, index)
+		}
+
+		chain.Addresses = append(chain.Addresses, address)
+
+		isUsed, err := used(address)
+		if err != nil {
+			return err
+		}
+
+		if isUsed {
+			chain.LastUsed = int64(index)
+		}
+	}
+
+	return nil
+}
+
+// extendAccount extends both chains of a watch-only account.
+func (i *Indexer) extendAccount(
+	account *bitcoin.WatchOnlyAccount,
+	used func(string) (bool, error),
+) error {
+	accountKey, err := bitcoin.ParseExtendedPublicKey(i.params, account.ExtendedKey)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse extended key %s", err, account.ExtendedKey)
+	}
+
+	if err := i.extendChain(
+		accountKey,
+		account.External,
+		bitcoin.ExternalChain,
+		account.GapLimit,
+		used,
+	); err != nil {
+		return err
+	}
+
+	return i.extendChain(
+		accountKey,
+		account.Internal,
+		bitcoin.InternalChain,
+		account.GapLimit,
+		used,
+	)
+}
+
+// RegisterWatchOnlyAccount starts watching the addresses derived
+// from the extended public key of a BIP44 account. Addresses
+// that already have a balance history are considered used.
+// Registering an account again only raises its gap limit.
+func (i *Indexer) RegisterWatchOnlyAccount(
+	ctx context.Context,
+	extendedKey string,
+	gapLimit int64,
+) (*bitcoin.WatchOnlyAccount, error) {
+	if _, err := bitcoin.ParseExtendedPublicKey(i.params, extendedKey); err != nil {
+		return nil, err
+	}
+
+	dbTx := i.database.WriteTransaction(ctx, blockSyncIdentifier, false)
+	defer dbTx.Discard(ctx)
+
+	account, err := i.getWatchOnlyAccount(ctx, dbTx, extendedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		account = &bitcoin.WatchOnlyAccount{
+			ExtendedKey: extendedKey,
+			External:    &bitcoin.WatchOnlyChain{LastUsed: -1},
+			Internal:    &bitcoin.WatchOnlyChain{LastUsed: -1},
+		}
+	}
+
+	if gapLimit > account.GapLimit {
+		account.GapLimit = gapLimit
+	}
+
+	// Without an indexed block, no address can be used.
+	head, err := i.blockStorage.GetHeadBlockIdentifierTransactional(ctx, dbTx)
+	if err != nil && !errors.Is(err, storageErrs.ErrHeadBlockNotFound) {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	used := func(address string) (bool, error) {
+		if head == nil {
+			return false, nil
+		}
+
+		_, err := i.balanceStorage.GetBalanceTransactional(
+			ctx,
+			dbTx,
+			&types.AccountIdentifier{Address: address},
+			i.currency,
+			head.Index,
+		)
+		if errors.Is(err, storageErrs.ErrAccountMissing) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: unable to get balance of %s", err, address)
+		}
+
+		return true, nil
+	}
+
+	if err := i.extendAccount(account, used); err != nil {
+		return nil, err
+	}
+
+	if err := i.setWatchOnlyAccount(ctx, dbTx, account); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit watch-only account", err)
+	}
+
+	return account, nil
+}
+
+// GetWatchOnlyAccount returns the watch-only account of an
+// extended key or nil if it is not registered.
+func (i *Indexer) GetWatchOnlyAccount(
+	ctx context.Context,
+	extendedKey string,
+) (*bitcoin.WatchOnlyAccount, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	return i.getWatchOnlyAccount(ctx, dbTx, extendedKey)
+}
+
+// watchOnlyAddresses returns the addresses derived
+// on both chains of a registered watch-only account.
+func (i *Indexer) watchOnlyAddresses(
+	ctx context.Context,
+	dbTx database.Transaction,
+	extendedKey string,
+) ([]string, error) {
+	account, err := i.getWatchOnlyAccount(ctx, dbTx, extendedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return nil, fmt.Errorf("extended key %s is not watched", extendedKey)
+	}
+
+	addresses := append([]string{}, account.External.Addresses...)
+	return append(addresses, account.Internal.Addresses...), nil
+}
+
+// GetWatchOnlyBalance returns the sum of the balances of the
+// addresses of a watch-only account at a particular
+// *types.PartialBlockIdentifier.
+func (i *Indexer) GetWatchOnlyBalance(
+	ctx context.Context,
+	extendedKey string,
+	currency *types.Currency,
+	blockIdentifier *types.PartialBlockIdentifier,
+) (*types.Amount, *types.BlockIdentifier, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	addresses, err := i.watchOnlyAddresses(ctx, dbTx, extendedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blockResponse, err := i.blockStorage.GetBlockLazyTransactional(
+		ctx,
+		blockIdentifier,
+		dbTx,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := new(big.Int)
+	for _, address := range addresses {
+		amount, err := i.balanceStorage.GetBalanceTransactional(
+			ctx,
+			dbTx,
+			&types.AccountIdentifier{Address: address},
+			currency,
+			blockResponse.Block.BlockIdentifier.Index,
+		)
+		if errors.Is(err, storageErrs.ErrAccountMissing) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to get balance of %s", err, address)
+		}
+
+		value, ok := new(big.Int).SetString(amount.Value, 10) // nolint:gomnd
+		if !ok {
+			return nil, nil, fmt.Errorf("unable to parse balance %s of %s", amount.Value, address)
+		}
+
+		total.Add(total, value)
+	}
+
+	return &types.Amount{
+		Value:    total.String(),
+		Currency: currency,
+	}, blockResponse.Block.BlockIdentifier, nil
+}
+
+// GetWatchOnlyCoins returns all unspent coins of
+// the addresses of a watch-only account.
+func (i *Indexer) GetWatchOnlyCoins(
+	ctx context.Context,
+	extendedKey string,
+) ([]*types.Coin, *types.BlockIdentifier, error) {
+	dbTx := i.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	addresses, err := i.watchOnlyAddresses(ctx, dbTx, extendedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coins := []*types.Coin{}
+	var block *types.BlockIdentifier
+	for _, address := range addresses {
+		addressCoins, head, err := i.coinStorage.GetCoinsTransactional(
+			ctx,
+			dbTx,
+			&types.AccountIdentifier{Address: address},
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to get coins of %s", err, address)
+		}
+
+		coins = append(coins, addressCoins...)
+		block = head
+	}
+
+	return coins, block, nil
+}
+
+// watchOnlyWorker is the modules.BlockWorker updating
+// watch-only accounts in the database transaction that
+// adds a block.
+type watchOnlyWorker struct {
+	i *Indexer
+}
+
+var _ modules.BlockWorker = (*watchOnlyWorker)(nil)
+
+// AddingBlock marks the addresses of watch-only accounts
+// receiving or spending coins in a block as used and derives
+// new addresses to keep the gap limit.
+func (w *watchOnlyWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.i.updateWatchOnlyAccounts(ctx, dbTx, block)
+}
+
+// RemovingBlock does nothing, addresses
+// stay derived if a block is orphaned.
+func (w *watchOnlyWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+func (i *Indexer) updateWatchOnlyAccounts(
+	ctx context.Context,
+	dbTx database.Transaction,
+	block *types.Block,
+) error {
+	accounts, err := i.getWatchOnlyAccounts(ctx, dbTx)
+	if err != nil {
+		return err
+	}
+
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	active := map[string]struct{}{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Account == nil || op.CoinChange == nil {
+				continue
+			}
+
+			active[op.Account.Address] = struct{}{}
+		}
+	}
+
+	used := func(address string) (bool, error) {
+		_, ok := active[address]
+		return ok, nil
+	}
+
+	for _, account := range accounts {
+		changed := false
+		for _, chain := range []*bitcoin.WatchOnlyChain{account.External, account.Internal} {
+			for index, address := range chain.Addresses {
+				if _, ok := active[address]; ok && int64(index) > chain.LastUsed {
+					chain.LastUsed = int64(index)
+					changed = true
+				}
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := i.extendAccount(account, used); err != nil {
+			return err
+		}
+
+		if err := i.setWatchOnlyAccount(ctx, dbTx, account); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -116,6 +116,14 @@ func startOnlineDependencies(
 		return i.Prune(ctx)
 	})
 
+	g.Go(func() error {
+		return i.Rebroadcast(ctx)
+	})
+
+	g.Go(func() error {
+		return i.TrackMempool(ctx)
+	})
+
 	return client, i, nil
 }
 
@@ -173,7 +181,7 @@ func main() {
 		bitcoin.OperationTypes,
 		services.HistoricalBalanceLookup,
 		[]*types.NetworkIdentifier{cfg.Network},
-		nil,
+		services.CallMethods,
 		services.MempoolCoins,
 	)
 	if err != nil {
@@ -49,6 +49,50 @@ func (_m *Client) GetRawBlock(_a0 context.Context, _a1 *types.PartialBlockIdenti
 	return r0, r1, r2
 }
 
+// GetRawBlockTransaction provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Client) GetRawBlockTransaction(_a0 context.Context, _a1 string, _a2 string) (string, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRawTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Client) GetRawTransaction(_a0 context.Context, _a1 string) (*bitcoin.Transaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *bitcoin.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) *bitcoin.Transaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NetworkStatus provides a mock function with given fields: _a0
 func (_m *Client) NetworkStatus(_a0 context.Context) (*types.NetworkStatusResponse, error) {
 	ret := _m.Called(_a0)
@@ -95,6 +139,29 @@ func (_m *Client) ParseBlock(_a0 context.Context, _a1 *bitcoin.Block, _a2 map[st
 	return r0, r1
 }
 
+// ParseTransaction provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Client) ParseTransaction(_a0 context.Context, _a1 *bitcoin.Transaction, _a2 map[string]*types.AccountCoin) (*types.Transaction, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, *bitcoin.Transaction, map[string]*types.AccountCoin) *types.Transaction); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *bitcoin.Transaction, map[string]*types.AccountCoin) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PruneBlockchain provides a mock function with given fields: _a0, _a1
 func (_m *Client) PruneBlockchain(_a0 context.Context, _a1 int64) (int64, error) {
 	ret := _m.Called(_a0, _a1)
@@ -115,3 +182,47 @@ func (_m *Client) PruneBlockchain(_a0 context.Context, _a1 int64) (int64, error)
 
 	return r0, r1
 }
+
+// RawMempool provides a mock function with given fields: _a0
+func (_m *Client) RawMempool(_a0 context.Context) ([]string, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendRawTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Client) SendRawTransaction(_a0 context.Context, _a1 string) (string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -15,6 +15,27 @@ type Client struct {
 	mock.Mock
 }
 
+// EstimateFee provides a mock function with given fields: _a0, _a1
+func (_m *Client) EstimateFee(_a0 context.Context, _a1 int64) (float64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(context.Context, int64) float64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPeers provides a mock function with given fields: _a0
 func (_m *Client) GetPeers(_a0 context.Context) ([]*types.Peer, error) {
 	ret := _m.Called(_a0)
@@ -38,6 +59,29 @@ func (_m *Client) GetPeers(_a0 context.Context) ([]*types.Peer, error) {
 	return r0, r1
 }
 
+// NetworkStatus provides a mock function with given fields: _a0
+func (_m *Client) NetworkStatus(_a0 context.Context) (*types.NetworkStatusResponse, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *types.NetworkStatusResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *types.NetworkStatusResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.NetworkStatusResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RawMempool provides a mock function with given fields: _a0
 func (_m *Client) RawMempool(_a0 context.Context) ([]string, error) {
 	ret := _m.Called(_a0)
@@ -9,6 +9,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	types "github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -17,6 +19,38 @@ type Indexer struct {
 	mock.Mock
 }
 
+// FindTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) FindTransaction(_a0 context.Context, _a1 *types.TransactionIdentifier) (*types.BlockIdentifier, *types.Transaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.BlockIdentifier
+	if rf, ok := ret.Get(0).(func(context.Context, *types.TransactionIdentifier) *types.BlockIdentifier); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.BlockIdentifier)
+		}
+	}
+
+	var r1 *types.Transaction
+	if rf, ok := ret.Get(1).(func(context.Context, *types.TransactionIdentifier) *types.Transaction); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.Transaction)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *types.TransactionIdentifier) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBalance provides a mock function with given fields: _a0, _a1, _a2, _a3
 func (_m *Indexer) GetBalance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.Currency, _a3 *types.PartialBlockIdentifier) (*types.Amount, *types.BlockIdentifier, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3)
@@ -95,6 +129,107 @@ func (_m *Indexer) GetBlockTransaction(_a0 context.Context, _a1 *types.BlockIden
 	return r0, r1
 }
 
+// GetCoin provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetCoin(_a0 context.Context, _a1 *types.CoinIdentifier) (*types.Coin, *types.AccountIdentifier, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.Coin
+	if rf, ok := ret.Get(0).(func(context.Context, *types.CoinIdentifier) *types.Coin); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Coin)
+		}
+	}
+
+	var r1 *types.AccountIdentifier
+	if rf, ok := ret.Get(1).(func(context.Context, *types.CoinIdentifier) *types.AccountIdentifier); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.AccountIdentifier)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *types.CoinIdentifier) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetCoinBlocks provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetCoinBlocks(_a0 context.Context, _a1 []*types.Coin) ([]*types.BlockIdentifier, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*types.BlockIdentifier
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.Coin) []*types.BlockIdentifier); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.BlockIdentifier)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []*types.Coin) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoinMaturities provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetCoinMaturities(_a0 context.Context, _a1 []*types.Coin) ([]int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []int64
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.Coin) []int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []*types.Coin) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoinReservations provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetCoinReservations(_a0 context.Context, _a1 []*types.Coin) ([]bool, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []bool
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.Coin) []bool); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bool)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []*types.Coin) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCoins provides a mock function with given fields: _a0, _a1
 func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) ([]*types.Coin, *types.BlockIdentifier, error) {
 	ret := _m.Called(_a0, _a1)
@@ -127,6 +262,96 @@ func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) (
 	return r0, r1, r2
 }
 
+// GetMempoolCoins provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetMempoolCoins(_a0 context.Context, _a1 []*types.AccountIdentifier) (*bitcoin.MempoolCoins, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *bitcoin.MempoolCoins
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.AccountIdentifier) *bitcoin.MempoolCoins); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.MempoolCoins)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []*types.AccountIdentifier) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMempoolSpender provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetMempoolSpender(_a0 context.Context, _a1 *types.CoinIdentifier) (string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, *types.CoinIdentifier) string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.CoinIdentifier) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMempoolTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetMempoolTransaction(_a0 context.Context, _a1 string) (*types.Transaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Transaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRawTransactions provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetRawTransactions(_a0 context.Context, _a1 []string) ([]string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetScriptPubKeys provides a mock function with given fields: _a0, _a1
 func (_m *Indexer) GetScriptPubKeys(_a0 context.Context, _a1 []*types.Coin) ([]*bitcoin.ScriptPubKey, error) {
 	ret := _m.Called(_a0, _a1)
@@ -149,3 +374,224 @@ func (_m *Indexer) GetScriptPubKeys(_a0 context.Context, _a1 []*types.Coin) ([]*
 
 	return r0, r1
 }
+
+// GetTrackedTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetTrackedTransaction(_a0 context.Context, _a1 string) (*bitcoin.TrackedTransaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *bitcoin.TrackedTransaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) *bitcoin.TrackedTransaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.TrackedTransaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrackedTransactions provides a mock function with given fields: _a0
+func (_m *Indexer) GetTrackedTransactions(_a0 context.Context) ([]*bitcoin.TrackedTransaction, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*bitcoin.TrackedTransaction
+	if rf, ok := ret.Get(0).(func(context.Context) []*bitcoin.TrackedTransaction); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bitcoin.TrackedTransaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWatchOnlyAccount provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetWatchOnlyAccount(_a0 context.Context, _a1 string) (*bitcoin.WatchOnlyAccount, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *bitcoin.WatchOnlyAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string) *bitcoin.WatchOnlyAccount); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.WatchOnlyAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWatchOnlyBalance provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Indexer) GetWatchOnlyBalance(_a0 context.Context, _a1 string, _a2 *types.Currency, _a3 *types.PartialBlockIdentifier) (*types.Amount, *types.BlockIdentifier, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 *types.Amount
+	if rf, ok := ret.Get(0).(func(context.Context, string, *types.Currency, *types.PartialBlockIdentifier) *types.Amount); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Amount)
+		}
+	}
+
+	var r1 *types.BlockIdentifier
+	if rf, ok := ret.Get(1).(func(context.Context, string, *types.Currency, *types.PartialBlockIdentifier) *types.BlockIdentifier); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.BlockIdentifier)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, *types.Currency, *types.PartialBlockIdentifier) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetWatchOnlyCoins provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetWatchOnlyCoins(_a0 context.Context, _a1 string) ([]*types.Coin, *types.BlockIdentifier, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*types.Coin
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*types.Coin); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Coin)
+		}
+	}
+
+	var r1 *types.BlockIdentifier
+	if rf, ok := ret.Get(1).(func(context.Context, string) *types.BlockIdentifier); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.BlockIdentifier)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RegisterWatchOnlyAccount provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Indexer) RegisterWatchOnlyAccount(_a0 context.Context, _a1 string, _a2 int64) (*bitcoin.WatchOnlyAccount, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *bitcoin.WatchOnlyAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *bitcoin.WatchOnlyAccount); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.WatchOnlyAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseCoins provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Indexer) ReleaseCoins(_a0 context.Context, _a1 string, _a2 []*types.Coin) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*types.Coin) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReserveCoins provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Indexer) ReserveCoins(_a0 context.Context, _a1 string, _a2 []*types.Coin, _a3 time.Duration) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*types.Coin, time.Duration) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchTransactions provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) SearchTransactions(_a0 context.Context, _a1 *types.SearchTransactionsRequest) (*types.SearchTransactionsResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.SearchTransactionsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *types.SearchTransactionsRequest) *types.SearchTransactionsResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.SearchTransactionsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.SearchTransactionsRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TrackTransaction provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Indexer) TrackTransaction(_a0 context.Context, _a1 string, _a2 string, _a3 []string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
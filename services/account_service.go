@@ -17,7 +17,10 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 
 	"github.com/coinbase/rosetta-sdk-go/server"
@@ -52,14 +55,35 @@ func (s *AccountAPIService) AccountBalance(
 
 	// TODO: filter balances by request currencies
 
+	watchOnly, rErr := s.isWatchOnly(ctx, request.AccountIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if request.AccountIdentifier.SubAccount != nil {
+		return s.subAccountBalance(ctx, request, watchOnly)
+	}
+
 	// If we are fetching a historical balance,
 	// use balance storage and don't return coins.
-	amount, block, err := s.i.GetBalance(
-		ctx,
-		request.AccountIdentifier,
-		s.config.Currency,
-		request.BlockIdentifier,
-	)
+	var amount *types.Amount
+	var block *types.BlockIdentifier
+	var err error
+	if watchOnly {
+		amount, block, err = s.i.GetWatchOnlyBalance(
+			ctx,
+			request.AccountIdentifier.Address,
+			s.config.Currency,
+			request.BlockIdentifier,
+		)
+	} else {
+		amount, block, err = s.i.GetBalance(
+			ctx,
+			request.AccountIdentifier,
+			s.config.Currency,
+			request.BlockIdentifier,
+		)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrUnableToGetBalance, err)
 	}
@@ -83,19 +107,238 @@ func (s *AccountAPIService) AccountCoins(
 
 	// TODO: filter coins by request currencies
 
-	// TODO: support include_mempool query
-	// https://github.com/rosetta-dogecoin/rosetta-dogecoin/issues/36#issuecomment-724992022
-	// Once mempoolcoins are supported also change the bool service/types.go:MempoolCoins to true
+	watchOnly, rErr := s.isWatchOnly(ctx, request.AccountIdentifier)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	coins, block, rErr := s.getCoins(ctx, request.AccountIdentifier, watchOnly)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	metadata := map[string]interface{}{}
+	if request.IncludeMempool {
+		var mempoolCoins []string
+		coins, mempoolCoins, rErr = s.mergeMempoolCoins(ctx, request.AccountIdentifier, watchOnly, coins)
+		if rErr != nil {
+			return nil, rErr
+		}
 
-	coins, block, err := s.i.GetCoins(ctx, request.AccountIdentifier)
+		metadata["mempool_coins"] = mempoolCoins
+	}
+
+	// Coinbase outputs cannot be spent before they reach
+	// coinbase maturity, so their maturity height is listed.
+	maturities, err := s.i.GetCoinMaturities(ctx, coins)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToGetCoins, err)
 	}
 
+	immatureCoins := map[string]int64{}
+	for i, coin := range coins {
+		if maturities[i] > 0 {
+			immatureCoins[coin.CoinIdentifier.Identifier] = maturities[i]
+		}
+	}
+
+	metadata["immature_coins"] = immatureCoins
+
 	result := &types.AccountCoinsResponse{
 		BlockIdentifier: block,
 		Coins:           coins,
 	}
 
+	// Coins reserved by transactions under construction
+	// are listed so clients can avoid spending them.
+	if s.config.CoinReservationTTL > 0 {
+		reserved, err := s.i.GetCoinReservations(ctx, coins)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetCoins, err)
+		}
+
+		reservedCoins := []string{}
+		for i, coin := range coins {
+			if reserved[i] {
+				reservedCoins = append(reservedCoins, coin.CoinIdentifier.Identifier)
+			}
+		}
+
+		metadata["reserved_coins"] = reservedCoins
+	}
+
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+
 	return result, nil
 }
+
+// getCoins returns the unspent coins of an account, or of
+// all addresses derived from a watch-only account.
+func (s *AccountAPIService) getCoins(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	watchOnly bool,
+) ([]*types.Coin, *types.BlockIdentifier, *types.Error) {
+	var coins []*types.Coin
+	var block *types.BlockIdentifier
+	var err error
+	if watchOnly {
+		coins, block, err = s.i.GetWatchOnlyCoins(ctx, account.Address)
+	} else {
+		coins, block, err = s.i.GetCoins(ctx, account)
+	}
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	return coins, block, nil
+}
+
+// subAccountBalance returns the balance of either the spendable
+// coins of an account or its immature coinbase outputs. Coinbase
+// maturity depends on the current block, so the balance of a
+// sub-account cannot be looked up at a historical block.
+func (s *AccountAPIService) subAccountBalance(
+	ctx context.Context,
+	request *types.AccountBalanceRequest,
+	watchOnly bool,
+) (*types.AccountBalanceResponse, *types.Error) {
+	subAccount := request.AccountIdentifier.SubAccount.Address
+	if subAccount != SpendableSubAccount && subAccount != ImmatureSubAccount {
+		return nil, wrapErr(
+			ErrInvalidSubAccount,
+			fmt.Errorf("sub-account must be %s or %s", SpendableSubAccount, ImmatureSubAccount),
+		)
+	}
+
+	if request.BlockIdentifier != nil {
+		return nil, wrapErr(
+			ErrInvalidSubAccount,
+			errors.New("sub-account balances are only available at the current block"),
+		)
+	}
+
+	coins, block, rErr := s.getCoins(
+		ctx,
+		&types.AccountIdentifier{Address: request.AccountIdentifier.Address},
+		watchOnly,
+	)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	maturities, err := s.i.GetCoinMaturities(ctx, coins)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBalance, err)
+	}
+
+	balance := "0"
+	for i, coin := range coins {
+		if (maturities[i] > 0) != (subAccount == ImmatureSubAccount) {
+			continue
+		}
+
+		balance, err = types.AddValues(balance, coin.Amount.Value)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetBalance, err)
+		}
+	}
+
+	return &types.AccountBalanceResponse{
+		BlockIdentifier: block,
+		Balances: []*types.Amount{
+			{
+				Value:    balance,
+				Currency: s.config.Currency,
+			},
+		},
+	}, nil
+}
+
+// mergeMempoolCoins removes the coins spent by mempool transactions
+// from the coins of an account and adds the coins they create. The
+// identifiers of the added coins are returned.
+func (s *AccountAPIService) mergeMempoolCoins(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	watchOnly bool,
+	coins []*types.Coin,
+) ([]*types.Coin, []string, *types.Error) {
+	accounts := []*types.AccountIdentifier{account}
+	if watchOnly {
+		watchOnlyAccount, err := s.i.GetWatchOnlyAccount(ctx, account.Address)
+		if err != nil {
+			return nil, nil, wrapErr(ErrUnableToWatchAccount, err)
+		}
+
+		accounts = []*types.AccountIdentifier{}
+		for _, chain := range []*bitcoin.WatchOnlyChain{
+			watchOnlyAccount.External,
+			watchOnlyAccount.Internal,
+		} {
+			for _, address := range chain.Addresses {
+				accounts = append(accounts, &types.AccountIdentifier{Address: address})
+			}
+		}
+	}
+
+	mempoolCoins, err := s.i.GetMempoolCoins(ctx, accounts)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	spent := map[string]struct{}{}
+	for _, coin := range mempoolCoins.Spent {
+		spent[coin.CoinIdentifier.Identifier] = struct{}{}
+	}
+
+	merged := []*types.Coin{}
+	existing := map[string]struct{}{}
+	for _, coin := range coins {
+		if _, ok := spent[coin.CoinIdentifier.Identifier]; ok {
+			continue
+		}
+
+		existing[coin.CoinIdentifier.Identifier] = struct{}{}
+		merged = append(merged, coin)
+	}
+
+	// A coin created by a transaction that was just
+	// included in a block may already be in coins.
+	added := []string{}
+	for _, coin := range mempoolCoins.Created {
+		if _, ok := existing[coin.CoinIdentifier.Identifier]; ok {
+			continue
+		}
+
+		added = append(added, coin.CoinIdentifier.Identifier)
+		merged = append(merged, coin)
+	}
+
+	return merged, added, nil
+}
+
+// isWatchOnly returns whether the address of an account is the
+// extended public key of a watch-only account, whose balance and
+// coins are aggregated over the addresses derived from it.
+func (s *AccountAPIService) isWatchOnly(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+) (bool, *types.Error) {
+	if _, err := bitcoin.ParseExtendedPublicKey(s.config.Params, account.Address); err != nil {
+		return false, nil
+	}
+
+	watchOnlyAccount, err := s.i.GetWatchOnlyAccount(ctx, account.Address)
+	if err != nil {
+		return false, wrapErr(ErrUnableToWatchAccount, err)
+	}
+
+	if watchOnlyAccount == nil {
+		return false, wrapErr(ErrWatchOnlyAccountNotFound, nil)
+	}
+
+	return true, nil
+}
@@ -18,7 +18,9 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
 	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
@@ -132,6 +134,112 @@ func TestAccountBalance_Online_Historical(t *testing.T) {
 	mockIndexer.AssertExpectations(t)
 }
 
+func TestAccountBalance_Online_SubAccounts(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Params:   dogecoin.MainnetParams,
+		Currency: dogecoin.MainnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewAccountAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+	block := &types.BlockIdentifier{
+		Index: 1000,
+		Hash:  "block 1000",
+	}
+	coins := []*types.Coin{
+		{
+			Amount: &types.Amount{
+				Value: "10",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 1",
+			},
+		},
+		{
+			Amount: &types.Amount{
+				Value: "15",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 2",
+			},
+		},
+		{
+			Amount: &types.Amount{
+				Value: "8",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 3",
+			},
+		},
+	}
+	mockIndexer.On("GetCoins", ctx, account).Return(coins, block, nil).Twice()
+	mockIndexer.On("GetCoinMaturities", ctx, coins).Return([]int64{0, 1200, 0}, nil).Twice()
+
+	tests := map[string]struct {
+		subAccount string
+		value      string
+	}{
+		"spendable": {
+			subAccount: SpendableSubAccount,
+			value:      "18",
+		},
+		"immature": {
+			subAccount: ImmatureSubAccount,
+			value:      "15",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+				AccountIdentifier: &types.AccountIdentifier{
+					Address:    account.Address,
+					SubAccount: &types.SubAccountIdentifier{Address: test.subAccount},
+				},
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, &types.AccountBalanceResponse{
+				BlockIdentifier: block,
+				Balances: []*types.Amount{
+					{
+						Value:    test.value,
+						Currency: dogecoin.MainnetCurrency,
+					},
+				},
+			}, bal)
+		})
+	}
+
+	// Test an unknown sub-account
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address:    account.Address,
+			SubAccount: &types.SubAccountIdentifier{Address: "locked"},
+		},
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrInvalidSubAccount.Code, err.Code)
+
+	// Test a historical sub-account balance
+	bal, err = servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address:    account.Address,
+			SubAccount: &types.SubAccountIdentifier{Address: SpendableSubAccount},
+		},
+		BlockIdentifier: &types.PartialBlockIdentifier{
+			Index: &block.Index,
+		},
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrInvalidSubAccount.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
 func TestAccountCoins_Online(t *testing.T) {
 	cfg := &configuration.Configuration{
 		Mode:     configuration.Online,
@@ -176,6 +284,63 @@ func TestAccountCoins_Online(t *testing.T) {
 		Hash:  "block 1000",
 	}
 	mockIndexer.On("GetCoins", ctx, account).Return(coins, block, nil).Once()
+	mockIndexer.On("GetCoinMaturities", ctx, coins).Return([]int64{0, 1240, 0}, nil).Once()
+
+	bal, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           coins,
+		Metadata: map[string]interface{}{
+			"immature_coins": map[string]int64{"coin 2": 1240},
+		},
+	}, bal)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestAccountCoins_Online_Reserved(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:               configuration.Online,
+		Currency:           dogecoin.MainnetCurrency,
+		CoinReservationTTL: 10 * time.Minute,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewAccountAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+
+	coins := []*types.Coin{
+		{
+			Amount: &types.Amount{
+				Value: "10",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 1",
+			},
+		},
+		{
+			Amount: &types.Amount{
+				Value: "15",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 2",
+			},
+		},
+	}
+	block := &types.BlockIdentifier{
+		Index: 1000,
+		Hash:  "block 1000",
+	}
+	mockIndexer.On("GetCoins", ctx, account).Return(coins, block, nil).Once()
+	mockIndexer.On("GetCoinMaturities", ctx, coins).Return([]int64{0, 0}, nil).Once()
+	mockIndexer.On("GetCoinReservations", ctx, coins).Return([]bool{false, true}, nil).Once()
 
 	bal, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
 		AccountIdentifier: account,
@@ -185,7 +350,241 @@ func TestAccountCoins_Online(t *testing.T) {
 	assert.Equal(t, &types.AccountCoinsResponse{
 		BlockIdentifier: block,
 		Coins:           coins,
+		Metadata: map[string]interface{}{
+			"immature_coins": map[string]int64{},
+			"reserved_coins": []string{"coin 2"},
+		},
 	}, bal)
 
 	mockIndexer.AssertExpectations(t)
 }
+
+func TestAccountCoins_Online_Mempool(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: dogecoin.MainnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewAccountAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+
+	coin1 := &types.Coin{
+		Amount: &types.Amount{
+			Value: "10",
+		},
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: "coin 1",
+		},
+	}
+	coin2 := &types.Coin{
+		Amount: &types.Amount{
+			Value: "15",
+		},
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: "coin 2",
+		},
+	}
+	coin3 := &types.Coin{
+		Amount: &types.Amount{
+			Value: "5",
+		},
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: "coin 3",
+		},
+	}
+	block := &types.BlockIdentifier{
+		Index: 1000,
+		Hash:  "block 1000",
+	}
+	mockIndexer.On("GetCoins", ctx, account).Return([]*types.Coin{coin1, coin2}, block, nil).Twice()
+	mockIndexer.On(
+		"GetMempoolCoins",
+		ctx,
+		[]*types.AccountIdentifier{account},
+	).Return(&bitcoin.MempoolCoins{
+		Created: []*types.Coin{coin1, coin3},
+		Spent:   []*types.Coin{coin2},
+	}, nil).Once()
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		[]*types.Coin{coin1, coin2},
+	).Return([]int64{0, 0}, nil).Once()
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		[]*types.Coin{coin1, coin3},
+	).Return([]int64{0, 0}, nil).Once()
+
+	// Without include_mempool, mempool coins are ignored
+	coins, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           []*types.Coin{coin1, coin2},
+		Metadata: map[string]interface{}{
+			"immature_coins": map[string]int64{},
+		},
+	}, coins)
+
+	coins, err = servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+		IncludeMempool:    true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           []*types.Coin{coin1, coin3},
+		Metadata: map[string]interface{}{
+			"immature_coins": map[string]int64{},
+			"mempool_coins":  []string{"coin 3"},
+		},
+	}, coins)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestAccount_WatchOnly(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Params:   dogecoin.MainnetParams,
+		Currency: dogecoin.MainnetCurrency,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewAccountAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	extendedKey := "dgub8rRgxK5Zh4vYtZ1Yvqn6KwaL41mvAKWCv63Kihbtu6NjyGdkFdumsFosc97Wvon148BUCfospeL3RWHBpJfyPBt2P2KU97o2PVvh5wNNuCf" // nolint
+	account := &types.AccountIdentifier{
+		Address: extendedKey,
+	}
+	watchOnlyAccount := &bitcoin.WatchOnlyAccount{
+		ExtendedKey: extendedKey,
+		GapLimit:    20,
+		External: &bitcoin.WatchOnlyChain{
+			Addresses: []string{"DDzmtB41R1dNRLst99jciLWhKVmYw1yEEY"},
+			LastUsed:  0,
+		},
+		Internal: &bitcoin.WatchOnlyChain{
+			Addresses: []string{"DLH7ySRbNFkUfSrBVxhZ1FRruNEE3rxKsS"},
+			LastUsed:  -1,
+		},
+	}
+	block := &types.BlockIdentifier{
+		Index: 1000,
+		Hash:  "block 1000",
+	}
+
+	// Test aggregated balance
+	amount := &types.Amount{
+		Value:    "25",
+		Currency: dogecoin.MainnetCurrency,
+	}
+	mockIndexer.On("GetWatchOnlyAccount", ctx, extendedKey).Return(watchOnlyAccount, nil).Times(4)
+	mockIndexer.On(
+		"GetWatchOnlyBalance",
+		ctx,
+		extendedKey,
+		dogecoin.MainnetCurrency,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(amount, block, nil).Once()
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.AccountBalanceResponse{
+		BlockIdentifier: block,
+		Balances: []*types.Amount{
+			amount,
+		},
+	}, bal)
+
+	// Test aggregated coins
+	coins := []*types.Coin{
+		{
+			Amount: &types.Amount{
+				Value: "10",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 1",
+			},
+		},
+		{
+			Amount: &types.Amount{
+				Value: "15",
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "coin 2",
+			},
+		},
+	}
+	mockIndexer.On("GetWatchOnlyCoins", ctx, extendedKey).Return(coins, block, nil).Once()
+	mockIndexer.On("GetCoinMaturities", ctx, coins).Return([]int64{0, 0}, nil).Once()
+	coinsResponse, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           coins,
+		Metadata: map[string]interface{}{
+			"immature_coins": map[string]int64{},
+		},
+	}, coinsResponse)
+
+	// Test aggregated mempool coins
+	mempoolCoin := &types.Coin{
+		Amount: &types.Amount{
+			Value: "5",
+		},
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: "coin 3",
+		},
+	}
+	mockIndexer.On("GetWatchOnlyCoins", ctx, extendedKey).Return(coins, block, nil).Once()
+	mockIndexer.On(
+		"GetMempoolCoins",
+		ctx,
+		[]*types.AccountIdentifier{
+			{Address: "DDzmtB41R1dNRLst99jciLWhKVmYw1yEEY"},
+			{Address: "DLH7ySRbNFkUfSrBVxhZ1FRruNEE3rxKsS"},
+		},
+	).Return(&bitcoin.MempoolCoins{
+		Created: []*types.Coin{mempoolCoin},
+		Spent:   []*types.Coin{coins[0]},
+	}, nil).Once()
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		[]*types.Coin{coins[1], mempoolCoin},
+	).Return([]int64{0, 0}, nil).Once()
+	coinsResponse, err = servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+		IncludeMempool:    true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           []*types.Coin{coins[1], mempoolCoin},
+		Metadata: map[string]interface{}{
+			"immature_coins": map[string]int64{},
+			"mempool_coins":  []string{"coin 3"},
+		},
+	}, coinsResponse)
+
+	// Test an extended key that is not registered
+	mockIndexer.On("GetWatchOnlyAccount", ctx, extendedKey).Return(nil, nil).Once()
+	bal, err = servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrWatchOnlyAccountNotFound.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// validateBatchOutputs ensures none of the outputs of a batched
+// transaction is below the dust limit once the OUTPUT operations
+// paying the same address are merged.
+func validateBatchOutputs(operations []*types.Operation) *types.Error {
+	amounts := map[string]*big.Int{}
+	addresses := []string{}
+	for _, operation := range operations {
+		if operation.Type != bitcoin.OutputOpType || operation.Account == nil || operation.Amount == nil {
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(operation.Amount.Value, 10) // nolint:gomnd
+		if !ok {
+			return wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("unable to parse amount %s", operation.Amount.Value),
+			)
+		}
+
+		address := operation.Account.Address
+		if _, ok := amounts[address]; !ok {
+			amounts[address] = new(big.Int)
+			addresses = append(addresses, address)
+		}
+
+		amounts[address].Add(amounts[address], amount)
+	}
+
+	dustLimit := big.NewInt(dogecoin.DustLimit)
+	for _, address := range addresses {
+		if amounts[address].Cmp(dustLimit) < 0 {
+			return wrapErr(
+				ErrDustOutput,
+				fmt.Errorf("output to %s of %s is below %d", address, amounts[address], dogecoin.DustLimit),
+			)
+		}
+	}
+
+	return nil
+}
+
+// bip69Order returns the order of the inputs and outputs
+// of tx sorted as described in BIP69. Inputs are sorted
+// by the hash and index of the outpoint they spend,
+// outputs by amount and then by script.
+func bip69Order(tx *wire.MsgTx) ([]int, []int) {
+	inputs := make([]int, len(tx.TxIn))
+	for i := range inputs {
+		inputs[i] = i
+	}
+
+	sort.SliceStable(inputs, func(i, j int) bool {
+		a := tx.TxIn[inputs[i]].PreviousOutPoint
+		b := tx.TxIn[inputs[j]].PreviousOutPoint
+		if a.Hash == b.Hash {
+			return a.Index < b.Index
+		}
+
+		// Hashes are compared in their displayed
+		// (reversed) byte order.
+		return a.Hash.String() < b.Hash.String()
+	})
+
+	outputs := make([]int, len(tx.TxOut))
+	for i := range outputs {
+		outputs[i] = i
+	}
+
+	sort.SliceStable(outputs, func(i, j int) bool {
+		a := tx.TxOut[outputs[i]]
+		b := tx.TxOut[outputs[j]]
+		if a.Value == b.Value {
+			return bytes.Compare(a.PkScript, b.PkScript) < 0
+		}
+
+		return a.Value < b.Value
+	})
+
+	return inputs, outputs
+}
+
+// remapOperations returns the operations parsed from a transaction
+// at the indexes of the operations it was constructed from. OUTPUT
+// operations merged into the same output are split again.
+func remapOperations(
+	ops []*types.Operation,
+	mappings []*operationMapping,
+) ([]*types.Operation, *types.Error) {
+	if len(mappings) == 0 {
+		return ops, nil
+	}
+
+	inputs := map[int64]*types.Operation{}
+	outputs := map[int64]*types.Operation{}
+	for _, op := range ops {
+		if op.Type == bitcoin.InputOpType {
+			inputs[*op.OperationIdentifier.NetworkIndex] = op
+		} else {
+			outputs[*op.OperationIdentifier.NetworkIndex] = op
+		}
+	}
+
+	remapped := make([]*types.Operation, len(mappings))
+	for _, mapping := range mappings {
+		parsed := outputs[mapping.NetworkIndex]
+		if mapping.Type == bitcoin.InputOpType {
+			parsed = inputs[mapping.NetworkIndex]
+		}
+
+		if parsed == nil || mapping.Index < 0 || mapping.Index >= int64(len(remapped)) {
+			return nil, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("operation %d does not match the transaction", mapping.Index),
+			)
+		}
+
+		op := *parsed
+		op.OperationIdentifier = &types.OperationIdentifier{
+			Index:        mapping.Index,
+			NetworkIndex: parsed.OperationIdentifier.NetworkIndex,
+		}
+		if len(mapping.Amount) > 0 {
+			op.Amount = &types.Amount{
+				Value:    mapping.Amount,
+				Currency: parsed.Amount.Currency,
+			}
+		}
+
+		remapped[mapping.Index] = &op
+	}
+
+	for i, op := range remapped {
+		if op == nil {
+			return nil, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("operation %d is missing", i),
+			)
+		}
+	}
+
+	return remapped, nil
+}
+
+// sortTransaction sorts the inputs and outputs of tx as described
+// in BIP69. The INPUT operations and the scripts and metadata of the
+// inputs are reordered with them and the mappings of the operations
+// are updated.
+func sortTransaction(
+	tx *wire.MsgTx,
+	inputs *parser.Match,
+	scriptPubKeys []*bitcoin.ScriptPubKey,
+	inputsMeta []*inputMetadata,
+	mappings []*operationMapping,
+) *types.Error {
+	// SIGHASH_SINGLE pairs an input with the output at the same
+	// index, which sorting would change.
+	for i, inputMeta := range inputsMeta {
+		hashType, err := parseSigHashType(inputMeta.SigHashType)
+		if err != nil {
+			return wrapErr(ErrUnclearIntent, fmt.Errorf("%w for utxo %d", err, i))
+		}
+
+		if hashType&sigHashMask == txscript.SigHashSingle {
+			return wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("utxo %d uses SIGHASH_SINGLE, which cannot be sorted with BIP69", i),
+			)
+		}
+	}
+
+	inputOrder, outputOrder := bip69Order(tx)
+
+	txIn := make([]*wire.TxIn, len(tx.TxIn))
+	operations := make([]*types.Operation, len(inputs.Operations))
+	amounts := make([]*big.Int, len(inputs.Amounts))
+	sortedScriptPubKeys := make([]*bitcoin.ScriptPubKey, len(scriptPubKeys))
+	sortedInputsMeta := make([]*inputMetadata, len(inputsMeta))
+	inputPositions := make([]int64, len(inputOrder))
+	for position, i := range inputOrder {
+		txIn[position] = tx.TxIn[i]
+		operations[position] = inputs.Operations[i]
+		amounts[position] = inputs.Amounts[i]
+		sortedScriptPubKeys[position] = scriptPubKeys[i]
+		sortedInputsMeta[position] = inputsMeta[i]
+		inputPositions[i] = int64(position)
+	}
+
+	txOut := make([]*wire.TxOut, len(tx.TxOut))
+	outputPositions := make([]int64, len(outputOrder))
+	for position, i := range outputOrder {
+		txOut[position] = tx.TxOut[i]
+		outputPositions[i] = int64(position)
+	}
+
+	tx.TxIn = txIn
+	tx.TxOut = txOut
+	copy(inputs.Operations, operations)
+	copy(inputs.Amounts, amounts)
+	copy(scriptPubKeys, sortedScriptPubKeys)
+	copy(inputsMeta, sortedInputsMeta)
+
+	for _, mapping := range mappings {
+		if mapping.Type == bitcoin.InputOpType {
+			mapping.NetworkIndex = inputPositions[mapping.NetworkIndex]
+		} else {
+			mapping.NetworkIndex = outputPositions[mapping.NetworkIndex]
+		}
+	}
+
+	return nil
+}
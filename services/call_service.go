@@ -0,0 +1,560 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// CallAPIService implements the server.CallAPIServicer interface.
+type CallAPIService struct {
+	config       *configuration.Configuration
+	client       Client
+	i            Indexer
+	feeEstimator FeeEstimator
+}
+
+// NewCallAPIService creates a new instance of a CallAPIService.
+func NewCallAPIService(
+	config *configuration.Configuration,
+	client Client,
+	i Indexer,
+) server.CallAPIServicer {
+	return &CallAPIService{
+		config:       config,
+		client:       client,
+		i:            i,
+		feeEstimator: NewFeeEstimator(config, client, i),
+	}
+}
+
+// Call implements the /call endpoint.
+func (s *CallAPIService) Call(
+	ctx context.Context,
+	request *types.CallRequest,
+) (*types.CallResponse, *types.Error) {
+	switch request.Method {
+	case GetTrackedTransactionsMethod:
+		return s.getTrackedTransactions(ctx, request.Parameters)
+	case ValidateAddressMethod:
+		return s.validateAddress(request.Parameters)
+	case DecodeScriptMethod:
+		return s.decodeScript(request.Parameters)
+	case DecodeRawTransactionMethod:
+		return s.decodeRawTransaction(request.Parameters)
+	case EstimateFeeMethod:
+		return s.estimateFee(ctx, request.Parameters)
+	case GetTxStatusMethod:
+		return s.getTxStatus(ctx, request.Parameters)
+	case GetTransactionMethod:
+		return s.getTransaction(ctx, request.Parameters)
+	case WatchExtendedKeyMethod:
+		return s.watchExtendedKey(ctx, request.Parameters)
+	case MessageSigningPayloadMethod:
+		return s.messageSigningPayload(request.Parameters)
+	case VerifyMessageMethod:
+		return s.verifyMessage(request.Parameters)
+	default:
+		return nil, wrapErr(
+			ErrUnimplemented,
+			fmt.Errorf("method %s is not supported", request.Method),
+		)
+	}
+}
+
+// getTrackedTransactions returns the transactions submitted through
+// ConstructionSubmit that are tracked for rebroadcast, or only the
+// one with the provided hash.
+func (s *CallAPIService) getTrackedTransactions(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	var params trackedTransactionsParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	var trackedTransactions []*bitcoin.TrackedTransaction
+	if len(params.Hash) > 0 {
+		tracked, err := s.i.GetTrackedTransaction(ctx, params.Hash)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetTrackedTransactions, err)
+		}
+
+		if tracked == nil {
+			return nil, wrapErr(
+				ErrTransactionNotFound,
+				fmt.Errorf("transaction %s is not tracked", params.Hash),
+			)
+		}
+
+		trackedTransactions = []*bitcoin.TrackedTransaction{tracked}
+	} else {
+		var err error
+		trackedTransactions, err = s.i.GetTrackedTransactions(ctx)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetTrackedTransactions, err)
+		}
+	}
+
+	return callResult(&trackedTransactionsResult{
+		Transactions: trackedTransactions,
+	}, false)
+}
+
+// callResult marshals the result of a /call method.
+func callResult(result interface{}, idempotent bool) (*types.CallResponse, *types.Error) {
+	marshaled, err := types.MarshalMap(result)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.CallResponse{
+		Result:     marshaled,
+		Idempotent: idempotent,
+	}, nil
+}
+
+// validateAddress checks that an address is a P2PKH or P2SH
+// address of the network. Invalid addresses are not an error,
+// the reason they are invalid is returned in the result.
+func (s *CallAPIService) validateAddress(
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	var params validateAddressParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	result := &validateAddressResult{Address: params.Address}
+	addr, err := btcutil.DecodeAddress(params.Address, s.config.Params)
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case !addr.IsForNet(s.config.Params):
+		result.Error = fmt.Sprintf("address is not for %s", s.config.Params.Name)
+	case !isBase58Address(addr):
+		// Raw public keys are not addresses, and Dogecoin
+		// has no segregated witness addresses.
+		result.Error = "only P2PKH and P2SH addresses are supported"
+	default:
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			result.Error = err.Error()
+			break
+		}
+
+		result.Valid = true
+		result.Type = txscript.GetScriptClass(script).String()
+		result.ScriptPubKey = hex.EncodeToString(script)
+	}
+
+	return callResult(result, true)
+}
+
+// isBase58Address returns whether addr is a pay-to-pubkey-hash
+// or pay-to-script-hash address, the only address types of Dogecoin.
+func isBase58Address(addr btcutil.Address) bool {
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeScript decodes a hex encoded script like the
+// `decodescript` RPC of bitcoind.
+func (s *CallAPIService) decodeScript(
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	var params decodeScriptParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	script, err := hex.DecodeString(params.Script)
+	if err != nil {
+		return nil, wrapErr(
+			ErrInvalidCallParameters,
+			fmt.Errorf("%w unable to decode script", err),
+		)
+	}
+
+	p2sh, err := btcutil.NewAddressScriptHash(script, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	scriptPubKey := bitcoin.DecodeScriptPubKey(s.config.Params, script)
+	return callResult(&decodeScriptResult{
+		ASM:          scriptPubKey.ASM,
+		Hex:          scriptPubKey.Hex,
+		RequiredSigs: scriptPubKey.RequiredSigs,
+		Type:         scriptPubKey.Type,
+		Addresses:    scriptPubKey.Addresses,
+		P2SH:         p2sh.EncodeAddress(),
+	}, true)
+}
+
+// decodeRawTransaction decodes a hex encoded transaction like
+// the `decoderawtransaction` RPC of bitcoind.
+func (s *CallAPIService) decodeRawTransaction(
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	var params decodeRawTransactionParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	serializedTx, err := hex.DecodeString(params.Transaction)
+	if err != nil {
+		return nil, wrapErr(
+			ErrInvalidCallParameters,
+			fmt.Errorf("%w unable to decode transaction", err),
+		)
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		return nil, wrapErr(
+			ErrInvalidCallParameters,
+			fmt.Errorf("%w unable to deserialize transaction", err),
+		)
+	}
+
+	transaction, err := bitcoin.DecodeTransaction(s.config.Params, &tx)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return callResult(transaction, true)
+}
+
+// estimateFee returns the fee rate suggested by the configured
+// FeeEstimator, never below the minimum relay fee rate. Only the
+// static fee estimator is available offline.
+func (s *CallAPIService) estimateFee(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	if s.config.Mode != configuration.Online &&
+		s.config.FeeEstimator != configuration.StaticFeeEstimator {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	var params estimateFeeParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	if params.ConfTarget < 0 {
+		return nil, wrapErr(
+			ErrInvalidCallParameters,
+			fmt.Errorf("conf_target %d is negative", params.ConfTarget),
+		)
+	}
+
+	if params.ConfTarget == 0 {
+		params.ConfTarget = defaultConfirmationTarget
+	}
+
+	feeRate, err := s.feeEstimator.FeeRate(ctx, params.ConfTarget)
+	if err != nil {
+		return nil, wrapErr(ErrCouldNotGetFeeRate, err)
+	}
+
+	if feeRate < dogecoin.MinRelayFeeRate {
+		feeRate = dogecoin.MinRelayFeeRate
+	}
+
+	return callResult(&estimateFeeResult{
+		ConfTarget: params.ConfTarget,
+		FeeRate:    feeRate,
+	}, false)
+}
+
+// getTxStatus returns whether a transaction is included in an
+// indexed block, in the mempool or, if it was submitted through
+// ConstructionSubmit, conflicted.
+func (s *CallAPIService) getTxStatus(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	var params txStatusParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	if err := validateTransactionHash(params.Hash); err != nil {
+		return nil, err
+	}
+
+	tracked, err := s.i.GetTrackedTransaction(ctx, params.Hash)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetTrackedTransactions, err)
+	}
+
+	result := &txStatusResult{
+		Hash:    params.Hash,
+		Status:  TxStatusUnknown,
+		Tracked: tracked,
+	}
+
+	blockIdentifier, _, err := s.i.FindTransaction(
+		ctx,
+		&types.TransactionIdentifier{Hash: params.Hash},
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToFindTransaction, err)
+	}
+
+	if blockIdentifier != nil {
+		head, err := s.i.GetBlockLazy(ctx, nil)
+		if err != nil {
+			return nil, wrapErr(ErrNotReady, err)
+		}
+
+		result.Status = TxStatusConfirmed
+		result.BlockIdentifier = blockIdentifier
+		result.Confirmations = head.Block.BlockIdentifier.Index - blockIdentifier.Index + 1
+
+		return callResult(result, false)
+	}
+
+	mempool, err := s.client.RawMempool(ctx)
+	if err != nil {
+		return nil, rpcErr(err)
+	}
+
+	for _, hash := range mempool {
+		if hash == params.Hash {
+			result.Status = TxStatusMempool
+			return callResult(result, false)
+		}
+	}
+
+	if tracked != nil {
+		switch tracked.Status {
+		case bitcoin.TrackedConflicted:
+			result.Status = TxStatusConflicted
+		case bitcoin.TrackedFailed:
+			result.Status = TxStatusFailed
+		}
+	}
+
+	return callResult(result, false)
+}
+
+// validateTransactionHash returns an error if
+// hash is not a hex encoded transaction hash.
+func validateTransactionHash(hash string) *types.Error {
+	if _, err := chainhash.NewHashFromStr(hash); err != nil ||
+		len(hash) != bitcoin.TransactionHashLength {
+		return wrapErr(
+			ErrInvalidCallParameters,
+			fmt.Errorf("%s is not a transaction hash", hash),
+		)
+	}
+
+	return nil
+}
+
+// getTransaction returns a transaction included in a block, with
+// its block and number of confirmations, or in the mempool, so
+// transactions can be looked up by hash alone.
+func (s *CallAPIService) getTransaction(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	var params getTransactionParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	if err := validateTransactionHash(params.Hash); err != nil {
+		return nil, err
+	}
+
+	blockIdentifier, transaction, err := s.i.FindTransaction(
+		ctx,
+		&types.TransactionIdentifier{Hash: params.Hash},
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToFindTransaction, err)
+	}
+
+	if blockIdentifier != nil {
+		head, err := s.i.GetBlockLazy(ctx, nil)
+		if err != nil {
+			return nil, wrapErr(ErrNotReady, err)
+		}
+
+		return callResult(&getTransactionResult{
+			Transaction:     transaction,
+			BlockIdentifier: blockIdentifier,
+			Confirmations:   head.Block.BlockIdentifier.Index - blockIdentifier.Index + 1,
+		}, false)
+	}
+
+	// Transactions that are neither indexed nor
+	// in the mempool are not found by bitcoind.
+	transaction, err = s.i.GetMempoolTransaction(ctx, params.Hash)
+	if err != nil {
+		// While the indexer is behind dogecoind, transactions
+		// in blocks it has not reached yet are returned with
+		// their block but without their operations.
+		var confirmedErr *bitcoin.ConfirmedTransactionError
+		if errors.As(err, &confirmedErr) {
+			return callResult(&getTransactionResult{
+				BlockIdentifier: confirmedErr.Block,
+				Confirmations:   confirmedErr.Confirmations,
+			}, false)
+		}
+
+		var rpcError *bitcoin.RPCError
+		if !errors.As(err, &rpcError) {
+			return nil, wrapErr(ErrUnableToFindTransaction, err)
+		}
+
+		if rpcError.Code == bitcoin.RPCInvalidAddressOrKeyErrCode {
+			return nil, wrapErr(
+				ErrTransactionNotFound,
+				fmt.Errorf("%w: transaction %s is neither indexed nor in the mempool", err, params.Hash),
+			)
+		}
+
+		return nil, rpcErr(err)
+	}
+
+	return callResult(&getTransactionResult{
+		Transaction: transaction,
+		InMempool:   true,
+	}, false)
+}
+
+// watchExtendedKey registers the extended public key of a
+// watch-only account and returns the addresses derived from it.
+func (s *CallAPIService) watchExtendedKey(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	var params watchExtendedKeyParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	if _, err := bitcoin.ParseExtendedPublicKey(s.config.Params, params.ExtendedKey); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	if params.GapLimit == 0 {
+		params.GapLimit = defaultGapLimit
+	}
+
+	if params.GapLimit < 1 || params.GapLimit > maxGapLimit {
+		return nil, wrapErr(
+			ErrInvalidCallParameters,
+			fmt.Errorf("gap_limit %d must be between 1 and %d", params.GapLimit, maxGapLimit),
+		)
+	}
+
+	account, err := s.i.RegisterWatchOnlyAccount(ctx, params.ExtendedKey, params.GapLimit)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToWatchAccount, err)
+	}
+
+	return callResult(account, false)
+}
+
+// messageSigningPayload returns the payload to sign to sign
+// a message with a P2PKH address like the signmessage RPC.
+func (s *CallAPIService) messageSigningPayload(
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	var params messageSigningPayloadParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	payload, err := messageSigningPayload(s.config.Params, params.Address, params.Message)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	return callResult(&messageSigningPayloadResult{
+		SigningPayload: payload,
+	}, true)
+}
+
+// verifyMessage checks the signature of a message signed by
+// a P2PKH address like the verifymessage RPC. Invalid
+// signatures are not an error, the reason they are invalid
+// is returned in the result.
+func (s *CallAPIService) verifyMessage(
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	var params verifyMessageParameters
+	if err := types.UnmarshalMap(parameters, &params); err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	address, err := parseMessageAddress(s.config.Params, params.Address)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidCallParameters, err)
+	}
+
+	result := &verifyMessageResult{}
+	pubKey, err := verifyMessage(address, params.Message, params.Signature)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Valid = true
+		result.PublicKey = hex.EncodeToString(pubKey)
+	}
+
+	return callResult(result, true)
+}
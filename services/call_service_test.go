@@ -0,0 +1,999 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallTrackedTransactions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	tracked := []*bitcoin.TrackedTransaction{
+		{
+			Hash:        "tx1",
+			Transaction: "01000000",
+			Inputs:      []string{"tx0:0"},
+			Status:      bitcoin.TrackedPending,
+			Broadcasts:  2,
+			LastError:   "rejected",
+		},
+		{
+			Hash:        "tx2",
+			Transaction: "01000000",
+			Inputs:      []string{"tx0:1"},
+			Status:      bitcoin.TrackedConfirmed,
+			Block: &types.BlockIdentifier{
+				Hash:  "block 1",
+				Index: 1,
+			},
+			Broadcasts: 1,
+		},
+	}
+
+	// Test all tracked transactions
+	mockIndexer.On("GetTrackedTransactions", ctx).Return(tracked, nil).Once()
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTrackedTransactionsMethod,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &trackedTransactionsResult{
+			Transactions: tracked,
+		}),
+	}, response)
+
+	// Test a tracked transaction
+	mockIndexer.On("GetTrackedTransaction", ctx, "tx2").Return(tracked[1], nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTrackedTransactionsMethod,
+		Parameters: map[string]interface{}{
+			"hash": "tx2",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &trackedTransactionsResult{
+			Transactions: tracked[1:],
+		}),
+	}, response)
+
+	// Test a transaction that is not tracked
+	mockIndexer.On("GetTrackedTransaction", ctx, "tx3").Return(nil, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTrackedTransactionsMethod,
+		Parameters: map[string]interface{}{
+			"hash": "tx3",
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	// Test invalid parameters
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTrackedTransactionsMethod,
+		Parameters: map[string]interface{}{
+			"hash": 1,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCallTrackedTransactions_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewCallAPIService(cfg, nil, mockIndexer)
+
+	response, err := servicer.Call(context.Background(), &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTrackedTransactionsMethod,
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCallValidateAddress(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:   configuration.Offline,
+		Params: dogecoin.TestnetParams,
+	}
+	servicer := NewCallAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	// Test a valid address
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            ValidateAddressMethod,
+		Parameters: map[string]interface{}{
+			"address": "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &validateAddressResult{
+			Address:      "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+			Valid:        true,
+			Type:         "pubkeyhash",
+			ScriptPubKey: "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+		}),
+		Idempotent: true,
+	}, response)
+
+	// Test an address of another network
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            ValidateAddressMethod,
+		Parameters: map[string]interface{}{
+			"address": "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, false, response.Result["is_valid"])
+	assert.NotEmpty(t, response.Result["error"])
+
+	// Test a public key, which btcutil decodes as an address
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            ValidateAddressMethod,
+		Parameters: map[string]interface{}{
+			"address": "0362ea463b406fe7133eee91c82f927f8815cd4ef0e3ffadb2f1501185ccb8b679",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, false, response.Result["is_valid"])
+	assert.Equal(t, "only P2PKH and P2SH addresses are supported", response.Result["error"])
+
+	// Test a segregated witness address
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            ValidateAddressMethod,
+		Parameters: map[string]interface{}{
+			"address": "tdge1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqxmdmm6",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, false, response.Result["is_valid"])
+	assert.Equal(t, "only P2PKH and P2SH addresses are supported", response.Result["error"])
+
+	// Test an invalid address
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            ValidateAddressMethod,
+		Parameters: map[string]interface{}{
+			"address": "not an address",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, false, response.Result["is_valid"])
+	assert.NotEmpty(t, response.Result["error"])
+}
+
+func TestCallDecodeScript(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:   configuration.Offline,
+		Params: dogecoin.TestnetParams,
+	}
+	servicer := NewCallAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	// Test a P2PKH script
+	script := "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac"
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            DecodeScriptMethod,
+		Parameters: map[string]interface{}{
+			"script": script,
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, response.Idempotent)
+	assert.Equal(t, script, response.Result["hex"])
+	assert.Equal(t, "pubkeyhash", response.Result["type"])
+	assert.Equal(t, int64(1), response.Result["reqSigs"])
+	assert.Len(t, response.Result["addresses"], 1)
+	assert.NotEmpty(t, response.Result["p2sh"])
+
+	// Test invalid hex
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            DecodeScriptMethod,
+		Parameters: map[string]interface{}{
+			"script": "zz",
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+}
+
+func TestCallDecodeRawTransaction(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:   configuration.Offline,
+		Params: dogecoin.TestnetParams,
+	}
+	servicer := NewCallAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	// Test a signed transaction
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            DecodeRawTransactionMethod,
+		Parameters: map[string]interface{}{
+			"transaction": "01000000017f9cf50b02dd5258f80cd5c3437302e027dd1336172a20cdc80305c5a55741b1010000006b4830450221009d2820aa8e79fb95fd2604d3d4e0d3e37a2a215f90e553c181d4f8d36f973041022036b84a5a93310e784dcfb6b762d4aa5b2a1be6253c540b2f43a5a1efbf8c9b8401210362ea463b406fe7133eee91c82f927f8815cd4ef0e3ffadb2f1501185ccb8b679ffffffff0278bfe938000000001976a91461263b081bf62c04642bf0f90fffbb94248f7c2688ac6869a902000000001976a914a60e695fe410bc4878d8192de88853fd397c27a388ac00000000", // nolint
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, response.Idempotent)
+
+	var transaction bitcoin.Transaction
+	assert.NoError(t, types.UnmarshalMap(response.Result, &transaction))
+	assert.Len(t, transaction.Inputs, 1)
+	assert.Len(t, transaction.Outputs, 2)
+	assert.Equal(t, "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f", transaction.Inputs[0].TxHash)
+	assert.Equal(t, int64(1), transaction.Inputs[0].Vout)
+	assert.Equal(t, 9.54843, transaction.Outputs[0].Value)
+	assert.Equal(t, "pubkeyhash", transaction.Outputs[0].ScriptPubKey.Type)
+
+	// Test an invalid transaction
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            DecodeRawTransactionMethod,
+		Parameters: map[string]interface{}{
+			"transaction": "0100",
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+}
+
+func TestCallEstimateFee(t *testing.T) {
+	ctx := context.Background()
+
+	// Test the static fee estimator offline
+	servicer := NewCallAPIService(&configuration.Configuration{
+		Mode:          configuration.Offline,
+		FeeEstimator:  configuration.StaticFeeEstimator,
+		StaticFeeRate: 2,
+	}, nil, nil)
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            EstimateFeeMethod,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &estimateFeeResult{
+			ConfTarget: defaultConfirmationTarget,
+			FeeRate:    2,
+		}),
+	}, response)
+
+	// Test estimatesmartfee offline
+	servicer = NewCallAPIService(&configuration.Configuration{
+		Mode: configuration.Offline,
+	}, nil, nil)
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            EstimateFeeMethod,
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	// Test estimatesmartfee below the minimum relay fee rate
+	mockClient := &mocks.Client{}
+	servicer = NewCallAPIService(&configuration.Configuration{
+		Mode: configuration.Online,
+	}, mockClient, nil)
+	mockClient.On("SuggestedFeeRate", ctx, int64(6)).Return(0.001, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            EstimateFeeMethod,
+		Parameters: map[string]interface{}{
+			"conf_target": 6,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &estimateFeeResult{
+			ConfTarget: 6,
+			FeeRate:    dogecoin.MinRelayFeeRate,
+		}),
+	}, response)
+
+	// Test a negative confirmation target
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            EstimateFeeMethod,
+		Parameters: map[string]interface{}{
+			"conf_target": -1,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestCallTxStatus(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	confirmed := "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	mempool := "c14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	conflicted := "d14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	unknown := "e14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+
+	// Test a confirmed transaction
+	block := &types.BlockIdentifier{Hash: "block 10", Index: 10}
+	mockIndexer.On("GetTrackedTransaction", ctx, confirmed).Return(nil, nil).Once()
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: confirmed},
+	).Return(block, &types.Transaction{}, nil).Once()
+	mockIndexer.On("GetBlockLazy", ctx, (*types.PartialBlockIdentifier)(nil)).Return(
+		&types.BlockResponse{
+			Block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{Hash: "block 15", Index: 15},
+			},
+		},
+		nil,
+	).Once()
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": confirmed,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &txStatusResult{
+			Hash:            confirmed,
+			Status:          TxStatusConfirmed,
+			BlockIdentifier: block,
+			Confirmations:   6,
+		}),
+	}, response)
+
+	// Test a transaction in the mempool
+	tracked := &bitcoin.TrackedTransaction{
+		Hash:   mempool,
+		Status: bitcoin.TrackedPending,
+	}
+	mockIndexer.On("GetTrackedTransaction", ctx, mempool).Return(tracked, nil).Once()
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: mempool},
+	).Return(nil, nil, nil).Once()
+	mockClient.On("RawMempool", ctx).Return([]string{mempool}, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": mempool,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &txStatusResult{
+			Hash:    mempool,
+			Status:  TxStatusMempool,
+			Tracked: tracked,
+		}),
+	}, response)
+
+	// Test a conflicted transaction
+	tracked = &bitcoin.TrackedTransaction{
+		Hash:                   conflicted,
+		Status:                 bitcoin.TrackedConflicted,
+		ConflictingTransaction: confirmed,
+	}
+	mockIndexer.On("GetTrackedTransaction", ctx, conflicted).Return(tracked, nil).Once()
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: conflicted},
+	).Return(nil, nil, nil).Once()
+	mockClient.On("RawMempool", ctx).Return([]string{mempool}, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": conflicted,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &txStatusResult{
+			Hash:    conflicted,
+			Status:  TxStatusConflicted,
+			Tracked: tracked,
+		}),
+	}, response)
+
+	// Test a failed transaction
+	tracked = &bitcoin.TrackedTransaction{
+		Hash:      conflicted,
+		Status:    bitcoin.TrackedFailed,
+		LastError: "66: min relay fee not met",
+	}
+	mockIndexer.On("GetTrackedTransaction", ctx, conflicted).Return(tracked, nil).Once()
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: conflicted},
+	).Return(nil, nil, nil).Once()
+	mockClient.On("RawMempool", ctx).Return([]string{mempool}, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": conflicted,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &txStatusResult{
+			Hash:    conflicted,
+			Status:  TxStatusFailed,
+			Tracked: tracked,
+		}),
+	}, response)
+
+	// Test an unknown transaction
+	mockIndexer.On("GetTrackedTransaction", ctx, unknown).Return(nil, nil).Once()
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: unknown},
+	).Return(nil, nil, nil).Once()
+	mockClient.On("RawMempool", ctx).Return([]string{mempool}, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": unknown,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &txStatusResult{
+			Hash:   unknown,
+			Status: TxStatusUnknown,
+		}),
+	}, response)
+
+	// Test a database error
+	mockIndexer.On("GetTrackedTransaction", ctx, unknown).Return(nil, nil).Once()
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: unknown},
+	).Return(nil, nil, errors.New("unable to read transaction")).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": unknown,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnableToFindTransaction.Code, err.Code)
+	assert.True(t, err.Retriable)
+
+	// Test an invalid hash
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTxStatusMethod,
+		Parameters: map[string]interface{}{
+			"hash": "tx1",
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCallGetTransaction(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewCallAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	confirmed := "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	mempool := "c14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	unhydrated := "d14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	unknown := "e14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+	unindexed := "f14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+
+	transaction := func(hash string) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                bitcoin.OutputOpType,
+					Status:              types.String(bitcoin.SuccessStatus),
+					Account:             &types.AccountIdentifier{Address: "address1"},
+					Amount: &types.Amount{
+						Value:    "100000000",
+						Currency: dogecoin.TestnetCurrency,
+					},
+				},
+			},
+		}
+	}
+
+	// Test a confirmed transaction
+	block := &types.BlockIdentifier{Hash: "block 10", Index: 10}
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: confirmed},
+	).Return(block, transaction(confirmed), nil).Once()
+	mockIndexer.On("GetBlockLazy", ctx, (*types.PartialBlockIdentifier)(nil)).Return(
+		&types.BlockResponse{
+			Block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{Hash: "block 15", Index: 15},
+			},
+		},
+		nil,
+	).Once()
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": confirmed,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &getTransactionResult{
+			Transaction:     transaction(confirmed),
+			BlockIdentifier: block,
+			Confirmations:   6,
+		}),
+	}, response)
+
+	// Test a transaction in the mempool
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: mempool},
+	).Return(nil, nil, nil).Once()
+	mockIndexer.On("GetMempoolTransaction", ctx, mempool).Return(transaction(mempool), nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": mempool,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &getTransactionResult{
+			Transaction: transaction(mempool),
+			InMempool:   true,
+		}),
+	}, response)
+
+	// Test a transaction in a block that is not indexed yet
+	unindexedBlock := &types.BlockIdentifier{Hash: "block 16", Index: 16}
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: unindexed},
+	).Return(nil, nil, nil).Once()
+	mockIndexer.On("GetMempoolTransaction", ctx, unindexed).Return(
+		nil,
+		&bitcoin.ConfirmedTransactionError{
+			Hash:          unindexed,
+			Block:         unindexedBlock,
+			Confirmations: 2,
+		},
+	).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": unindexed,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, &getTransactionResult{
+			BlockIdentifier: unindexedBlock,
+			Confirmations:   2,
+		}),
+	}, response)
+	assert.Equal(t, false, response.Result["in_mempool"])
+
+	// Test a mempool transaction that cannot be hydrated
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: unhydrated},
+	).Return(nil, nil, nil).Once()
+	mockIndexer.On("GetMempoolTransaction", ctx, unhydrated).Return(
+		nil,
+		errors.New("unable to find the coins spent by the transaction"),
+	).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": unhydrated,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnableToFindTransaction.Code, err.Code)
+	assert.True(t, err.Retriable)
+
+	// Test an unknown transaction
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: unknown},
+	).Return(nil, nil, nil).Once()
+	mockIndexer.On("GetMempoolTransaction", ctx, unknown).Return(
+		nil,
+		&bitcoin.RPCError{
+			Code:    bitcoin.RPCInvalidAddressOrKeyErrCode,
+			Message: "No such mempool or blockchain transaction",
+		},
+	).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": unknown,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	// Test a database error
+	mockIndexer.On(
+		"FindTransaction",
+		ctx,
+		&types.TransactionIdentifier{Hash: confirmed},
+	).Return(nil, nil, errors.New("unable to read transaction")).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": confirmed,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnableToFindTransaction.Code, err.Code)
+	assert.True(t, err.Retriable)
+
+	// Test an invalid hash
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": "tx1",
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+
+	// Test offline
+	offline := NewCallAPIService(
+		&configuration.Configuration{Mode: configuration.Offline},
+		mockClient,
+		mockIndexer,
+	)
+	response, err = offline.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            GetTransactionMethod,
+		Parameters: map[string]interface{}{
+			"hash": confirmed,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCallWatchExtendedKey(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:   configuration.Online,
+		Params: dogecoin.TestnetParams,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewCallAPIService(cfg, nil, mockIndexer)
+	ctx := context.Background()
+
+	extendedKey := "tpubDDW4jVEAkwNoHumzePCtQ5FcxXVc8RG8ACszXP1HD1WThkZ19sAoyaNeiXswjTtAKM14zjo8rdhxadti7zuNSfJBMuG68oxQ3Bi1wgo88fD" // nolint
+	account := &bitcoin.WatchOnlyAccount{
+		ExtendedKey: extendedKey,
+		GapLimit:    1,
+		External: &bitcoin.WatchOnlyChain{
+			Addresses: []string{"nehiWbHwkjvD7drcqzbiJZwo5uCyQ7CCqf"},
+			LastUsed:  -1,
+		},
+		Internal: &bitcoin.WatchOnlyChain{
+			Addresses: []string{"nWCbFveJs7vMcFGPCxxBw2jSG4zarSySrk"},
+			LastUsed:  -1,
+		},
+	}
+
+	// Test registering with the default gap limit
+	mockIndexer.On("RegisterWatchOnlyAccount", ctx, extendedKey, defaultGapLimit).Return(account, nil).Once()
+	response, err := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            WatchExtendedKeyMethod,
+		Parameters: map[string]interface{}{
+			"extended_public_key": extendedKey,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, account),
+	}, response)
+
+	// Test registering with a gap limit
+	mockIndexer.On("RegisterWatchOnlyAccount", ctx, extendedKey, int64(1)).Return(account, nil).Once()
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            WatchExtendedKeyMethod,
+		Parameters: map[string]interface{}{
+			"extended_public_key": extendedKey,
+			"gap_limit":           1,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.CallResponse{
+		Result: forceMarshalMap(t, account),
+	}, response)
+
+	// Test a gap limit that is too large
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            WatchExtendedKeyMethod,
+		Parameters: map[string]interface{}{
+			"extended_public_key": extendedKey,
+			"gap_limit":           maxGapLimit + 1,
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+
+	// Test an extended key of another network
+	response, err = servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            WatchExtendedKeyMethod,
+		Parameters: map[string]interface{}{
+			"extended_public_key": "dgub8rRgxK5Zh4vYtZ1Yvqn6KwaL41mvAKWCv63Kihbtu6NjyGdkFdumsFosc97Wvon148BUCfospeL3RWHBpJfyPBt2P2KU97o2PVvh5wNNuCf", // nolint
+		},
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrInvalidCallParameters.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCallSignMessage(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:   configuration.Offline,
+		Params: dogecoin.MainnetParams,
+	}
+	servicer := NewCallAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	privKey, pubKey := btcec.PrivKeyFromBytes(
+		btcec.S256(),
+		forceHexDecode(t, "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d"),
+	)
+	compressed, err := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		cfg.Params,
+	)
+	assert.NoError(t, err)
+	uncompressed, err := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeUncompressed()),
+		cfg.Params,
+	)
+	assert.NoError(t, err)
+	message := "proof of reserves"
+
+	// Test the signing payload
+	response, rErr := servicer.Call(ctx, &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            MessageSigningPayloadMethod,
+		Parameters: map[string]interface{}{
+			"address": compressed.EncodeAddress(),
+			"message": message,
+		},
+	})
+	assert.Nil(t, rErr)
+	var payloadResult messageSigningPayloadResult
+	assert.NoError(t, types.UnmarshalMap(response.Result, &payloadResult))
+	payload := payloadResult.SigningPayload
+	assert.Equal(t, compressed.EncodeAddress(), payload.AccountIdentifier.Address)
+	assert.Equal(t, types.EcdsaRecovery, payload.SignatureType)
+	assert.Equal(
+		t,
+		"5736d2a73ceed4e7625fc21746bf9968887b4ec74929af7e3564081d2e8d3314",
+		hex.EncodeToString(payload.Bytes),
+	)
+
+	compactSig, err := btcec.SignCompact(btcec.S256(), privKey, payload.Bytes, true)
+	assert.NoError(t, err)
+	uncompressedSig, err := btcec.SignCompact(btcec.S256(), privKey, payload.Bytes, false)
+	assert.NoError(t, err)
+
+	// ecdsa_recovery signatures are r, s and the recovery id
+	recoverySig := append(append([]byte{}, compactSig[1:]...), compactSig[0]-27-4)
+
+	tests := map[string]struct {
+		address   string
+		message   string
+		signature string
+		valid     bool
+		publicKey []byte
+	}{
+		"compact signature": {
+			address:   compressed.EncodeAddress(),
+			message:   message,
+			signature: base64.StdEncoding.EncodeToString(compactSig),
+			valid:     true,
+			publicKey: pubKey.SerializeCompressed(),
+		},
+		"ecdsa_recovery signature": {
+			address:   compressed.EncodeAddress(),
+			message:   message,
+			signature: hex.EncodeToString(recoverySig),
+			valid:     true,
+			publicKey: pubKey.SerializeCompressed(),
+		},
+		"uncompressed key": {
+			address:   uncompressed.EncodeAddress(),
+			message:   message,
+			signature: base64.StdEncoding.EncodeToString(uncompressedSig),
+			valid:     true,
+			publicKey: pubKey.SerializeUncompressed(),
+		},
+		"ecdsa_recovery signature of uncompressed key": {
+			address:   uncompressed.EncodeAddress(),
+			message:   message,
+			signature: hex.EncodeToString(recoverySig),
+			valid:     true,
+			publicKey: pubKey.SerializeUncompressed(),
+		},
+		"compressed signature of uncompressed key": {
+			address:   uncompressed.EncodeAddress(),
+			message:   message,
+			signature: base64.StdEncoding.EncodeToString(compactSig),
+		},
+		"other message": {
+			address:   compressed.EncodeAddress(),
+			message:   "proof of liabilities",
+			signature: base64.StdEncoding.EncodeToString(compactSig),
+		},
+		"other address": {
+			address:   "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L",
+			message:   message,
+			signature: base64.StdEncoding.EncodeToString(compactSig),
+		},
+		"invalid signature": {
+			address:   compressed.EncodeAddress(),
+			message:   message,
+			signature: "signature",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, rErr := servicer.Call(ctx, &types.CallRequest{
+				NetworkIdentifier: networkIdentifier,
+				Method:            VerifyMessageMethod,
+				Parameters: map[string]interface{}{
+					"address":   test.address,
+					"message":   test.message,
+					"signature": test.signature,
+				},
+			})
+			assert.Nil(t, rErr)
+			assert.True(t, response.Idempotent)
+
+			var result verifyMessageResult
+			assert.NoError(t, types.UnmarshalMap(response.Result, &result))
+			assert.Equal(t, test.valid, result.Valid)
+			if test.valid {
+				assert.Equal(t, hex.EncodeToString(test.publicKey), result.PublicKey)
+				assert.Empty(t, result.Error)
+			} else {
+				assert.NotEmpty(t, result.Error)
+			}
+		})
+	}
+
+	// Test a P2SH address
+	p2sh, err := btcutil.NewAddressScriptHash([]byte{txscript.OP_TRUE}, cfg.Params)
+	assert.NoError(t, err)
+	for _, method := range []string{MessageSigningPayloadMethod, VerifyMessageMethod} {
+		response, rErr = servicer.Call(ctx, &types.CallRequest{
+			NetworkIdentifier: networkIdentifier,
+			Method:            method,
+			Parameters: map[string]interface{}{
+				"address":   p2sh.EncodeAddress(),
+				"message":   message,
+				"signature": base64.StdEncoding.EncodeToString(compactSig),
+			},
+		})
+		assert.Nil(t, response)
+		assert.Equal(t, ErrInvalidCallParameters.Code, rErr.Code)
+	}
+}
+
+func TestCallUnknownMethod(t *testing.T) {
+	servicer := NewCallAPIService(&configuration.Configuration{}, nil, nil)
+
+	response, err := servicer.Call(context.Background(), &types.CallRequest{
+		NetworkIdentifier: networkIdentifier,
+		Method:            "getblock",
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, ErrUnimplemented.Code, err.Code)
+}
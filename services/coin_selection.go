@@ -0,0 +1,287 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// CoinSelection is the strategy used to select the coins
+// funding a transaction in ConstructionPreprocess.
+type CoinSelection string
+
+const (
+	// LargestFirstCoinSelection spends the coins
+	// with the largest value first.
+	LargestFirstCoinSelection CoinSelection = "largest_first"
+
+	// BranchAndBoundCoinSelection searches for a set of
+	// coins that does not need a change output and falls
+	// back to LargestFirstCoinSelection.
+	BranchAndBoundCoinSelection CoinSelection = "branch_and_bound"
+
+	// OldestFirstCoinSelection spends the coins
+	// created in the oldest blocks first.
+	OldestFirstCoinSelection CoinSelection = "oldest_first"
+
+	// branchAndBoundMaxTries is the maximum number of
+	// branches explored by the BranchAndBoundSelector.
+	branchAndBoundMaxTries = 100000
+)
+
+var (
+	// errInsufficientFunds is returned by a CoinSelector when
+	// the candidates cannot cover the target.
+	errInsufficientFunds = errors.New("insufficient funds")
+)
+
+// CoinCandidate is a coin that can be selected
+// to fund a transaction.
+type CoinCandidate struct {
+	Coin *types.Coin
+
+	// Value is the value of the coin in koinu.
+	Value int64
+
+	// Fee is the fee in koinu required
+	// to spend the coin as an input.
+	Fee int64
+
+	// Height is the index of the block
+	// that created the coin.
+	Height int64
+}
+
+// EffectiveValue is the value of the coin
+// minus the fee required to spend it.
+func (c *CoinCandidate) EffectiveValue() int64 {
+	return c.Value - c.Fee
+}
+
+// CoinSelector selects the coins to spend so that the sum of their
+// effective values covers target. Selections leaving at least
+// costOfChange over target can pay for a change output.
+type CoinSelector interface {
+	SelectCoins(
+		candidates []*CoinCandidate,
+		target int64,
+		costOfChange int64,
+	) ([]*CoinCandidate, error)
+}
+
+// NewCoinSelector returns the CoinSelector
+// implementing the provided strategy.
+func NewCoinSelector(strategy CoinSelection) (CoinSelector, error) {
+	switch strategy {
+	case LargestFirstCoinSelection, "":
+		return &LargestFirstSelector{}, nil
+	case BranchAndBoundCoinSelection:
+		return &BranchAndBoundSelector{MaxTries: branchAndBoundMaxTries}, nil
+	case OldestFirstCoinSelection:
+		return &OldestFirstSelector{}, nil
+	default:
+		return nil, fmt.Errorf("%s is not a valid coin selection", strategy)
+	}
+}
+
+// accumulateCoins selects candidates in order until there is enough
+// to pay for a change output, or all candidates if only target
+// can be covered.
+func accumulateCoins(
+	candidates []*CoinCandidate,
+	target int64,
+	costOfChange int64,
+) ([]*CoinCandidate, error) {
+	selected := []*CoinCandidate{}
+	total := int64(0)
+	for _, candidate := range candidates {
+		// Skip coins that cost more to
+		// spend than they are worth.
+		if candidate.EffectiveValue() <= 0 {
+			continue
+		}
+
+		selected = append(selected, candidate)
+		total += candidate.EffectiveValue()
+		if total >= target+costOfChange {
+			return selected, nil
+		}
+	}
+
+	if total < target {
+		return nil, fmt.Errorf(
+			"%w: need %d, have %d",
+			errInsufficientFunds,
+			target,
+			total,
+		)
+	}
+
+	return selected, nil
+}
+
+// LargestFirstSelector spends the coins
+// with the largest value first.
+type LargestFirstSelector struct{}
+
+// SelectCoins implements the CoinSelector interface.
+func (l *LargestFirstSelector) SelectCoins(
+	candidates []*CoinCandidate,
+	target int64,
+	costOfChange int64,
+) ([]*CoinCandidate, error) {
+	sorted := make([]*CoinCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Value > sorted[j].Value
+	})
+
+	return accumulateCoins(sorted, target, costOfChange)
+}
+
+// OldestFirstSelector spends the coins
+// created in the oldest blocks first.
+type OldestFirstSelector struct{}
+
+// SelectCoins implements the CoinSelector interface.
+func (o *OldestFirstSelector) SelectCoins(
+	candidates []*CoinCandidate,
+	target int64,
+	costOfChange int64,
+) ([]*CoinCandidate, error) {
+	sorted := make([]*CoinCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Height == sorted[j].Height {
+			return sorted[i].Value > sorted[j].Value
+		}
+
+		return sorted[i].Height < sorted[j].Height
+	})
+
+	return accumulateCoins(sorted, target, costOfChange)
+}
+
+// BranchAndBoundSelector searches for a set of coins whose effective
+// value is between target and target+costOfChange, so no change
+// output is needed. It falls back to the LargestFirstSelector
+// when no such set is found within MaxTries.
+type BranchAndBoundSelector struct {
+	MaxTries int
+}
+
+// SelectCoins implements the CoinSelector interface.
+func (b *BranchAndBoundSelector) SelectCoins(
+	candidates []*CoinCandidate,
+	target int64,
+	costOfChange int64,
+) ([]*CoinCandidate, error) {
+	sorted := []*CoinCandidate{}
+	available := int64(0)
+	for _, candidate := range candidates {
+		if candidate.EffectiveValue() > 0 {
+			sorted = append(sorted, candidate)
+			available += candidate.EffectiveValue()
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EffectiveValue() > sorted[j].EffectiveValue()
+	})
+
+	if available >= target {
+		if selection := b.search(sorted, available, target, costOfChange); selection != nil {
+			return selection, nil
+		}
+	}
+
+	return (&LargestFirstSelector{}).SelectCoins(candidates, target, costOfChange)
+}
+
+// search performs a depth first search over the inclusion and
+// omission of each candidate (sorted by descending effective
+// value) and returns the selection with the least waste.
+func (b *BranchAndBoundSelector) search(
+	sorted []*CoinCandidate,
+	available int64,
+	target int64,
+	costOfChange int64,
+) []*CoinCandidate {
+	var best []bool
+	bestWaste := int64(-1)
+
+	selected := make([]bool, len(sorted))
+	total := int64(0)
+	depth := 0
+	for tries := 0; tries < b.MaxTries; tries++ {
+		backtrack := false
+		switch {
+		case total+available < target, total > target+costOfChange:
+			backtrack = true
+		case total >= target:
+			if waste := total - target; bestWaste == -1 || waste < bestWaste {
+				best = make([]bool, len(selected))
+				copy(best, selected[:depth])
+				bestWaste = waste
+			}
+
+			backtrack = true
+		case depth == len(sorted):
+			backtrack = true
+		}
+
+		if backtrack {
+			// Walk back to the last included candidate
+			// and try omitting it instead.
+			for depth > 0 && !selected[depth-1] {
+				depth--
+				available += sorted[depth].EffectiveValue()
+			}
+
+			if depth == 0 {
+				break
+			}
+
+			depth--
+			selected[depth] = false
+			total -= sorted[depth].EffectiveValue()
+			depth++
+			continue
+		}
+
+		// Include the candidate at depth.
+		available -= sorted[depth].EffectiveValue()
+		selected[depth] = true
+		total += sorted[depth].EffectiveValue()
+		depth++
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	selection := []*CoinCandidate{}
+	for i, include := range best {
+		if include {
+			selection = append(selection, sorted[i])
+		}
+	}
+
+	return selection
+}
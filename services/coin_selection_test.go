@@ -0,0 +1,155 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func coinCandidates(values ...int64) []*CoinCandidate {
+	candidates := make([]*CoinCandidate, len(values))
+	for i, value := range values {
+		candidates[i] = &CoinCandidate{
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: fmt.Sprintf("coin %d", i),
+				},
+			},
+			Value:  value,
+			Fee:    10,
+			Height: int64(len(values) - i),
+		}
+	}
+
+	return candidates
+}
+
+func candidateValues(candidates []*CoinCandidate) []int64 {
+	values := make([]int64, len(candidates))
+	for i, candidate := range candidates {
+		values[i] = candidate.Value
+	}
+
+	return values
+}
+
+func TestNewCoinSelector(t *testing.T) {
+	selector, err := NewCoinSelector("")
+	assert.NoError(t, err)
+	assert.Equal(t, &LargestFirstSelector{}, selector)
+
+	selector, err = NewCoinSelector(BranchAndBoundCoinSelection)
+	assert.NoError(t, err)
+	assert.Equal(t, &BranchAndBoundSelector{MaxTries: branchAndBoundMaxTries}, selector)
+
+	selector, err = NewCoinSelector(OldestFirstCoinSelection)
+	assert.NoError(t, err)
+	assert.Equal(t, &OldestFirstSelector{}, selector)
+
+	selector, err = NewCoinSelector("random")
+	assert.Nil(t, selector)
+	assert.EqualError(t, err, "random is not a valid coin selection")
+}
+
+func TestCoinSelectors(t *testing.T) {
+	tests := map[string]struct {
+		selector     CoinSelector
+		candidates   []*CoinCandidate
+		target       int64
+		costOfChange int64
+
+		selected []int64
+		err      error
+	}{
+		"largest first": {
+			selector:     &LargestFirstSelector{},
+			candidates:   coinCandidates(100, 500, 300, 200),
+			target:       600,
+			costOfChange: 50,
+			selected:     []int64{500, 300},
+		},
+		"largest first without change": {
+			selector:     &LargestFirstSelector{},
+			candidates:   coinCandidates(100, 500),
+			target:       570,
+			costOfChange: 50,
+			selected:     []int64{500, 100},
+		},
+		"largest first skips uneconomical coins": {
+			selector:     &LargestFirstSelector{},
+			candidates:   coinCandidates(5, 500),
+			target:       480,
+			costOfChange: 50,
+			selected:     []int64{500},
+		},
+		"largest first insufficient funds": {
+			selector:     &LargestFirstSelector{},
+			candidates:   coinCandidates(100, 500),
+			target:       600,
+			costOfChange: 50,
+			err:          errInsufficientFunds,
+		},
+		"oldest first": {
+			selector:     &OldestFirstSelector{},
+			candidates:   coinCandidates(100, 500, 300, 200),
+			target:       400,
+			costOfChange: 50,
+			selected:     []int64{200, 300},
+		},
+		"branch and bound exact match": {
+			selector:     &BranchAndBoundSelector{MaxTries: branchAndBoundMaxTries},
+			candidates:   coinCandidates(100, 500, 300, 200),
+			target:       480,
+			costOfChange: 5,
+			selected:     []int64{300, 200},
+		},
+		"branch and bound falls back to largest first": {
+			selector:     &BranchAndBoundSelector{MaxTries: branchAndBoundMaxTries},
+			candidates:   coinCandidates(100, 500, 300, 200),
+			target:       555,
+			costOfChange: 5,
+			selected:     []int64{500, 300},
+		},
+		"branch and bound insufficient funds": {
+			selector:     &BranchAndBoundSelector{MaxTries: branchAndBoundMaxTries},
+			candidates:   coinCandidates(100, 500),
+			target:       600,
+			costOfChange: 5,
+			err:          errInsufficientFunds,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selected, err := test.selector.SelectCoins(
+				test.candidates,
+				test.target,
+				test.costOfChange,
+			)
+			if test.err != nil {
+				assert.True(t, errors.Is(err, test.err))
+				assert.Nil(t, selected)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.selected, candidateValues(selected))
+			}
+		})
+	}
+}
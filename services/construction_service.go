@@ -18,21 +18,25 @@ package services
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"strconv"
 
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/utils"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -46,13 +50,27 @@ const (
 	// defaultConfirmationTarget is the number of blocks we would
 	// like our transaction to be included by.
 	defaultConfirmationTarget = int64(2) // nolint:gomnd
+
+	// reservationIDSize is the number of random
+	// bytes in a coin reservation identifier.
+	reservationIDSize = 16 // nolint:gomnd
+
+	// sigHashMask is the mask of the base sighash type
+	// without SIGHASH_ANYONECANPAY.
+	sigHashMask = 0x1f // nolint:gomnd
+
+	// ecdsaSignatureSize is the size of an
+	// ecdsa signature in the form R || S.
+	ecdsaSignatureSize = 64 // nolint:gomnd
 )
 
 // ConstructionAPIService implements the server.ConstructionAPIServicer interface.
 type ConstructionAPIService struct {
-	config *configuration.Configuration
-	client Client
-	i      Indexer
+	config       *configuration.Configuration
+	client       Client
+	i            Indexer
+	feeEstimator FeeEstimator
+	policy       *PolicyChecker
 }
 
 // NewConstructionAPIService creates a new instance of a ConstructionAPIService.
@@ -62,9 +80,11 @@ func NewConstructionAPIService(
 	i Indexer,
 ) server.ConstructionAPIServicer {
 	return &ConstructionAPIService{
-		config: config,
-		client: client,
-		i:      i,
+		config:       config,
+		client:       client,
+		i:            i,
+		feeEstimator: NewFeeEstimator(config, client, i),
+		policy:       NewPolicyChecker(client, i),
 	}
 }
 
@@ -73,7 +93,20 @@ func (s *ConstructionAPIService) ConstructionDerive(
 	ctx context.Context,
 	request *types.ConstructionDeriveRequest,
 ) (*types.ConstructionDeriveResponse, *types.Error) {
-	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+	var metadata deriveMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	if len(metadata.PublicKeys) > 0 {
+		return s.deriveMultisig(request.PublicKey, &metadata)
+	}
+
+	if len(metadata.ExtendedKey) > 0 {
+		return s.deriveExtendedKey(request.PublicKey, &metadata)
+	}
+
+	addr, err := btcutil.NewAddressPubKeyHash(
 		btcutil.Hash160(request.PublicKey.Bytes),
 		s.config.Params,
 	)
@@ -88,40 +121,419 @@ func (s *ConstructionAPIService) ConstructionDerive(
 	}, nil
 }
 
-// estimateSize returns the estimated size of a transaction in vBytes.
+// deriveMultisig derives the P2SH address of an m-of-n multisig
+// redeem script. The public key provided in the request must be
+// one of the public keys in the metadata.
+func (s *ConstructionAPIService) deriveMultisig(
+	publicKey *types.PublicKey,
+	metadata *deriveMetadata,
+) (*types.ConstructionDeriveResponse, *types.Error) {
+	if metadata.Threshold < 1 || metadata.Threshold > len(metadata.PublicKeys) {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf(
+			"threshold %d must be between 1 and %d",
+			metadata.Threshold,
+			len(metadata.PublicKeys),
+		))
+	}
+
+	found := false
+	pubKeys := make([]*btcutil.AddressPubKey, len(metadata.PublicKeys))
+	for i, key := range metadata.PublicKeys {
+		keyBytes, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDerive, fmt.Errorf("%w unable to decode public key %s", err, key))
+		}
+
+		pubKey, err := btcutil.NewAddressPubKey(keyBytes, s.config.Params)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDerive, fmt.Errorf("%w unable to parse public key %s", err, key))
+		}
+
+		if bytes.Equal(keyBytes, publicKey.Bytes) {
+			found = true
+		}
+
+		pubKeys[i] = pubKey
+	}
+
+	if !found {
+		return nil, wrapErr(
+			ErrUnableToDerive,
+			errors.New("public key is not one of the multisig public keys"),
+		)
+	}
+
+	redeemScript, err := txscript.MultiSigScript(pubKeys, metadata.Threshold)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf("%w unable to build redeem script", err))
+	}
+
+	addr, err := btcutil.NewAddressScriptHash(redeemScript, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	responseMetadata, err := types.MarshalMap(&deriveResponseMetadata{
+		RedeemScript: hex.EncodeToString(redeemScript),
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: addr.EncodeAddress(),
+		},
+		Metadata: responseMetadata,
+	}, nil
+}
+
+// deriveExtendedKey derives the P2PKH address at a BIP44 path
+// (m/44'/coin_type'/account'/change/index) of the extended public
+// key of an account. The public key provided in the request must
+// be the public key of the extended key.
+func (s *ConstructionAPIService) deriveExtendedKey(
+	publicKey *types.PublicKey,
+	metadata *deriveMetadata,
+) (*types.ConstructionDeriveResponse, *types.Error) {
+	accountKey, err := bitcoin.ParseExtendedPublicKey(s.config.Params, metadata.ExtendedKey)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	accountPubKey, err := accountKey.ECPubKey()
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	if !bytes.Equal(accountPubKey.SerializeCompressed(), publicKey.Bytes) {
+		return nil, wrapErr(
+			ErrUnableToDerive,
+			errors.New("public key is not the public key of the extended key"),
+		)
+	}
+
+	path, err := bitcoin.ParseDerivationPath(metadata.Path)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	key, err := bitcoin.DeriveBIP44Key(s.config.Params, accountKey, path)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	addr, err := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		s.config.Params,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	responseMetadata, err := types.MarshalMap(&deriveExtendedKeyResponseMetadata{
+		PublicKey: hex.EncodeToString(pubKey.SerializeCompressed()),
+		Path:      metadata.Path,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: addr.EncodeAddress(),
+		},
+		Metadata: responseMetadata,
+	}, nil
+}
+
+// estimateSize returns the estimated size of a transaction in bytes,
+// excluding the size of its inputs. Inputs are added in
+// ConstructionMetadata once their scriptPubKeys are known.
 func (s *ConstructionAPIService) estimateSize(operations []*types.Operation) float64 {
-	size := bitcoin.TransactionOverhead
+	inputs := 0
+	outputs := 0
+	size := dogecoin.TransactionOverhead
 	for _, operation := range operations {
 		switch operation.Type {
 		case bitcoin.InputOpType:
-			size += bitcoin.InputSize
+			inputs++
+		case bitcoin.DataOpType:
+			outputs++
+			size += dogecoin.OutputOverhead
+			script, rErr := dataScript(operation)
+			if rErr != nil {
+				// OP_RETURN OP_PUSHDATA1 <data>
+				size += 1 + 2 + dogecoin.MaxDataCarrierSize
+				continue
+			}
+
+			size += wire.VarIntSerializeSize(uint64(len(script))) + len(script)
 		case bitcoin.OutputOpType:
-			size += bitcoin.OutputOverhead
+			outputs++
+			size += dogecoin.OutputOverhead
 			addr, err := btcutil.DecodeAddress(operation.Account.Address, s.config.Params)
 			if err != nil {
-				size += bitcoin.P2PKHScriptPubkeySize
+				size += 1 + dogecoin.P2PKHScriptPubkeySize
 				continue
 			}
 
 			script, err := txscript.PayToAddrScript(addr)
 			if err != nil {
-				size += bitcoin.P2PKHScriptPubkeySize
+				size += 1 + dogecoin.P2PKHScriptPubkeySize
 				continue
 			}
 
-			size += len(script)
+			size += wire.VarIntSerializeSize(uint64(len(script))) + len(script)
 		}
 	}
 
+	size += wire.VarIntSerializeSize(uint64(inputs))
+	size += wire.VarIntSerializeSize(uint64(outputs))
+
 	return float64(size)
 }
 
+// pushDataSize returns the size of the opcode
+// needed to push data of the provided length.
+func pushDataSize(length int) int {
+	switch {
+	case length < txscript.OP_PUSHDATA1:
+		return 1
+	case length <= math.MaxUint8:
+		return 2 // nolint:gomnd
+	default:
+		return 3 // nolint:gomnd
+	}
+}
+
+// estimateInputSize returns the estimated size in bytes of a
+// signed input spending the provided scriptPubKey.
+func (s *ConstructionAPIService) estimateInputSize(
+	scriptPubKey *bitcoin.ScriptPubKey,
+	metadata *inputMetadata,
+) (int, *types.Error) {
+	script, err := hex.DecodeString(scriptPubKey.Hex)
+	if err != nil {
+		return -1, wrapErr(ErrUnableToDecodeScriptPubKey, err)
+	}
+
+	var sigScriptSize int
+	switch class := txscript.GetScriptClass(script); class {
+	case txscript.PubKeyTy:
+		sigScriptSize = dogecoin.SignaturePushSize
+	case txscript.PubKeyHashTy:
+		sigScriptSize = dogecoin.SignaturePushSize + dogecoin.CompressedPubKeyPushSize
+		if metadata.UncompressedPublicKey {
+			sigScriptSize = dogecoin.SignaturePushSize + dogecoin.UncompressedPubKeyPushSize
+		}
+	case txscript.ScriptHashTy:
+		redeemScript, signers, err := s.parseRedeemScript(script, metadata)
+		if err != nil {
+			return -1, wrapErr(ErrInvalidRedeemScript, err)
+		}
+
+		// OP_0 <sigs...> <redeemScript>
+		sigScriptSize = 1 + len(signers)*dogecoin.SignaturePushSize +
+			pushDataSize(len(redeemScript)) + len(redeemScript)
+	case txscript.WitnessV0PubKeyHashTy:
+		return bitcoin.InputSize, nil
+	default:
+		return -1, wrapErr(
+			ErrUnsupportedScriptType,
+			fmt.Errorf("unable to estimate size of %s input", class),
+		)
+	}
+
+	return dogecoin.InputOverhead + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize, nil
+}
+
+// countDustOutputs returns the number of outputs
+// below the Dogecoin soft dust limit.
+func countDustOutputs(operations []*types.Operation) int64 {
+	dustLimit := big.NewInt(dogecoin.DustLimit)
+	count := int64(0)
+	for _, operation := range operations {
+		if operation.Type != bitcoin.OutputOpType || operation.Amount == nil {
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(operation.Amount.Value, 10) // nolint:gomnd
+		if ok && amount.Cmp(dustLimit) < 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// dataScript returns the OP_RETURN script
+// carrying the payload of a DATA operation.
+func dataScript(operation *types.Operation) ([]byte, *types.Error) {
+	var metadata dataMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrInvalidData, err)
+	}
+
+	var data []byte
+	switch {
+	case len(metadata.Data) > 0 && len(metadata.Text) > 0:
+		return nil, wrapErr(ErrInvalidData, errors.New("data and text cannot both be provided"))
+	case len(metadata.Data) > 0:
+		var err error
+		data, err = hex.DecodeString(metadata.Data)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidData, fmt.Errorf("%w unable to decode data", err))
+		}
+	case len(metadata.Text) > 0:
+		data = []byte(metadata.Text)
+	default:
+		return nil, wrapErr(ErrInvalidData, errors.New("data or text must be provided"))
+	}
+
+	if len(data) > dogecoin.MaxDataCarrierSize {
+		return nil, wrapErr(ErrInvalidData, fmt.Errorf(
+			"%d bytes of data exceed the standard limit of %d bytes",
+			len(data),
+			dogecoin.MaxDataCarrierSize,
+		))
+	}
+
+	script, err := txscript.NullDataScript(data)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidData, err)
+	}
+
+	return script, nil
+}
+
+// validateDataOperations ensures the DATA operations of an
+// intent can be built into a standard transaction, which
+// has at most one OP_RETURN output.
+func validateDataOperations(operations []*types.Operation) *types.Error {
+	count := 0
+	for _, operation := range operations {
+		if operation.Type != bitcoin.DataOpType {
+			continue
+		}
+
+		count++
+		if count > 1 {
+			return wrapErr(ErrInvalidData, errors.New("only one DATA operation is standard"))
+		}
+
+		if operation.Account != nil || operation.Amount != nil {
+			return wrapErr(
+				ErrInvalidData,
+				errors.New("DATA operations cannot have an account or amount"),
+			)
+		}
+
+		if _, rErr := dataScript(operation); rErr != nil {
+			return rErr
+		}
+	}
+
+	return nil
+}
+
+// validateSignatureType ensures the signature type requested
+// in the signing payloads is supported by ConstructionCombine.
+func validateSignatureType(signatureType types.SignatureType) *types.Error {
+	switch signatureType {
+	case "", types.Ecdsa, types.EcdsaRecovery:
+		return nil
+	default:
+		return wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("%s signatures are not supported", signatureType),
+		)
+	}
+}
+
+// sigHashTypes are the sighash types that can be set in the
+// metadata of an INPUT operation, named as in the sighashtype
+// argument of signrawtransaction.
+var sigHashTypes = map[string]txscript.SigHashType{
+	"ALL":                 txscript.SigHashAll,
+	"NONE":                txscript.SigHashNone,
+	"SINGLE":              txscript.SigHashSingle,
+	"ALL|ANYONECANPAY":    txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+	"NONE|ANYONECANPAY":   txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+	"SINGLE|ANYONECANPAY": txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+}
+
+// parseSigHashType returns the sighash type named in the
+// metadata of an input, SIGHASH_ALL when none is set.
+func parseSigHashType(name string) (txscript.SigHashType, error) {
+	if len(name) == 0 {
+		return txscript.SigHashAll, nil
+	}
+
+	hashType, ok := sigHashTypes[name]
+	if !ok {
+		return 0, fmt.Errorf("%s is not a valid sighash type", name)
+	}
+
+	return hashType, nil
+}
+
+// sigHashTypeName returns the name of a sighash type
+// in the metadata of an input.
+func sigHashTypeName(hashType txscript.SigHashType) string {
+	for name, t := range sigHashTypes {
+		if t == hashType {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%#x", uint32(hashType))
+}
+
+// inputSigHashType returns the sighash type of
+// an input of an unsigned transaction.
+func inputSigHashType(unsigned *unsignedTransaction, i int) txscript.SigHashType {
+	if len(unsigned.SigHashTypes) == 0 {
+		return txscript.SigHashAll
+	}
+
+	return unsigned.SigHashTypes[i]
+}
+
 // ConstructionPreprocess implements the /construction/preprocess
 // endpoint.
 func (s *ConstructionAPIService) ConstructionPreprocess(
 	ctx context.Context,
 	request *types.ConstructionPreprocessRequest,
 ) (*types.ConstructionPreprocessResponse, *types.Error) {
+	var metadata preprocessMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	if rErr := validateDataOperations(request.Operations); rErr != nil {
+		return nil, rErr
+	}
+
+	if rErr := validateSignatureType(metadata.SignatureType); rErr != nil {
+		return nil, rErr
+	}
+
+	if metadata.Batch {
+		if rErr := validateBatchOutputs(request.Operations); rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	if metadata.Sender != nil {
+		return s.preprocessCoinSelection(ctx, request, &metadata)
+	}
+
 	descriptions := &parser.Descriptions{
 		OperationDescriptions: []*parser.OperationDescription{
 			{
@@ -146,6 +558,8 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	}
 
 	coins := make([]*types.Coin, len(matches[0].Operations))
+	inputs := make([]*inputMetadata, len(matches[0].Operations))
+	hasInputMetadata := false
 	for i, input := range matches[0].Operations {
 		if input.CoinChange == nil {
 			return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
@@ -155,12 +569,300 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 			CoinIdentifier: input.CoinChange.CoinIdentifier,
 			Amount:         input.Amount,
 		}
+
+		// Input metadata is needed to estimate the size
+		// of P2SH and uncompressed P2PKH inputs.
+		var inputMeta inputMetadata
+		if err := types.UnmarshalMap(input.Metadata, &inputMeta); err != nil {
+			return nil, wrapErr(ErrUnclearIntent, err)
+		}
+
+		if _, err := parseSigHashType(inputMeta.SigHashType); err != nil {
+			return nil, wrapErr(ErrUnclearIntent, err)
+		}
+
+		inputs[i] = &inputMeta
+		hasInputMetadata = hasInputMetadata || len(input.Metadata) > 0
+	}
+
+	if !hasInputMetadata {
+		inputs = nil
+	}
+
+	var inputAddresses []string
+	if len(metadata.ScriptPubKeys) > 0 {
+		inputAddresses = make([]string, len(matches[0].Operations))
+		for i, input := range matches[0].Operations {
+			inputAddresses[i] = input.Account.Address
+		}
+	}
+
+	options, err := types.MarshalMap(&preprocessOptions{
+		Coins:          coins,
+		Inputs:         inputs,
+		EstimatedSize:  s.estimateSize(request.Operations),
+		DustOutputs:    countDustOutputs(request.Operations),
+		FeeMultiplier:  request.SuggestedFeeMultiplier,
+		LockTime:       metadata.LockTime,
+		SignatureType:  metadata.SignatureType,
+		PSBT:           metadata.PSBT,
+		Batch:          metadata.Batch,
+		BIP69:          metadata.BIP69,
+		FeeRate:        metadata.FeeRate,
+		ScriptPubKeys:  metadata.ScriptPubKeys,
+		InputAddresses: inputAddresses,
+
+		PreviousTransactions: metadata.PreviousTransactions,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPreprocessResponse{
+		Options: options,
+	}, nil
+}
+
+// suggestedFeeRate returns the fee rate in koinu per byte provided
+// by the caller or suggested by the FeeEstimator, adjusted by the
+// fee multiplier and never below the minimum relay fee rate.
+func (s *ConstructionAPIService) suggestedFeeRate(
+	ctx context.Context,
+	feeRate *float64,
+	feeMultiplier *float64,
+) (float64, *types.Error) {
+	var feePerKB float64
+	if feeRate != nil {
+		if *feeRate <= 0 {
+			return -1, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("fee rate %f must be positive", *feeRate),
+			)
+		}
+
+		feePerKB = *feeRate
+	} else {
+		var err error
+		feePerKB, err = s.feeEstimator.FeeRate(ctx, defaultConfirmationTarget)
+		if err != nil {
+			return -1, wrapErr(ErrCouldNotGetFeeRate, err)
+		}
+	}
+	if feeMultiplier != nil {
+		feePerKB *= *feeMultiplier
+	}
+	if feePerKB < dogecoin.MinRelayFeeRate {
+		feePerKB = dogecoin.MinRelayFeeRate
+	}
+
+	return (feePerKB * float64(dogecoin.SatoshisInBitcoin)) / bytesInKb, nil
+}
+
+// outputSize returns the size in bytes of
+// an output paying to the provided address.
+func (s *ConstructionAPIService) outputSize(address string) (int, *types.Error) {
+	addr, err := btcutil.DecodeAddress(address, s.config.Params)
+	if err != nil {
+		return -1, wrapErr(
+			ErrUnableToDecodeAddress,
+			fmt.Errorf("%w unable to decode address %s", err, address),
+		)
+	}
+
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return -1, wrapErr(
+			ErrUnableToDecodeAddress,
+			fmt.Errorf("%w unable to construct payToAddrScript", err),
+		)
+	}
+
+	return dogecoin.OutputOverhead + wire.VarIntSerializeSize(uint64(len(script))) + len(script), nil
+}
+
+// preprocessCoinSelection selects the coins of the sender that
+// fund the outputs of the intent. The selected inputs and the
+// change output are added to the transaction in
+// ConstructionPayloads.
+func (s *ConstructionAPIService) preprocessCoinSelection(
+	ctx context.Context,
+	request *types.ConstructionPreprocessRequest,
+	metadata *preprocessMetadata,
+) (*types.ConstructionPreprocessResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	if len(metadata.ScriptPubKeys) > 0 {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			errors.New("script_pub_keys cannot be provided when coins are selected automatically"),
+		)
+	}
+
+	selector, err := NewCoinSelector(metadata.CoinSelection)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	descriptions := &parser.Descriptions{
+		OperationDescriptions: []*parser.OperationDescription{
+			{
+				Type: bitcoin.OutputOpType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     parser.PositiveAmountSign,
+					Currency: s.config.Currency,
+				},
+				AllowRepeats: true,
+			},
+			{
+				Type:     bitcoin.DataOpType,
+				Optional: true,
+			},
+		},
+		ErrUnmatched: true,
+	}
+
+	matches, err := parser.MatchOperations(descriptions, request.Operations)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	outputAmount := int64(0)
+	for _, amount := range matches[0].Amounts {
+		outputAmount += amount.Int64()
+	}
+
+	changeAddress := metadata.ChangeAddress
+	if len(changeAddress) == 0 {
+		changeAddress = metadata.Sender.Address
+	}
+
+	changeSize, rErr := s.outputSize(changeAddress)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	koinuPerB, rErr := s.suggestedFeeRate(ctx, metadata.FeeRate, request.SuggestedFeeMultiplier)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	coins, _, err := s.i.GetCoins(ctx, metadata.Sender)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	coins, rErr = s.unreservedCoins(ctx, coins)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	coins, rErr = s.matureCoins(ctx, coins)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	// Coins are spent, so their amounts are negated
+	// like the amounts of INPUT operations.
+	spends := make([]*types.Coin, len(coins))
+	for i, coin := range coins {
+		spends[i] = &types.Coin{
+			CoinIdentifier: coin.CoinIdentifier,
+			Amount: &types.Amount{
+				Value:    "-" + coin.Amount.Value,
+				Currency: coin.Amount.Currency,
+			},
+		}
+	}
+
+	scripts, err := s.i.GetScriptPubKeys(ctx, spends)
+	if err != nil {
+		return nil, wrapErr(ErrScriptPubKeysMissing, err)
 	}
 
+	blocks := make([]*types.BlockIdentifier, len(coins))
+	if metadata.CoinSelection == OldestFirstCoinSelection {
+		blocks, err = s.i.GetCoinBlocks(ctx, coins)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToGetCoins, err)
+		}
+	}
+
+	inputMeta := metadata.InputMetadata
+	if inputMeta == nil {
+		inputMeta = &inputMetadata{}
+	}
+
+	candidates := make([]*CoinCandidate, len(coins))
+	for i, coin := range coins {
+		value, err := strconv.ParseInt(coin.Amount.Value, 10, 64) // nolint:gomnd
+		if err != nil {
+			return nil, wrapErr(ErrInvalidCoin, err)
+		}
+
+		inputSize, rErr := s.estimateInputSize(scripts[i], inputMeta)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		candidates[i] = &CoinCandidate{
+			Coin:  spends[i],
+			Value: value,
+			Fee:   int64(math.Ceil(float64(inputSize) * koinuPerB)),
+		}
+		if blocks[i] != nil {
+			candidates[i].Height = blocks[i].Index
+		}
+	}
+
+	estimatedSize := s.estimateSize(request.Operations)
+	dustOutputs := countDustOutputs(request.Operations)
+	target := outputAmount + int64(math.Ceil(estimatedSize*koinuPerB)) + dustOutputs*dogecoin.DustLimit
+	costOfChange := int64(math.Ceil(float64(changeSize)*koinuPerB)) + dogecoin.DustLimit
+	selected, err := selector.SelectCoins(candidates, target, costOfChange)
+	if err != nil {
+		return nil, wrapErr(ErrInsufficientFunds, err)
+	}
+
+	selectedCoins := make([]*types.Coin, len(selected))
+	for i, candidate := range selected {
+		selectedCoins[i] = candidate.Coin
+	}
+
+	var inputs []*inputMetadata
+	if metadata.InputMetadata != nil {
+		inputs = make([]*inputMetadata, len(selected))
+		for i := range selected {
+			inputs[i] = metadata.InputMetadata
+		}
+	}
+
+	// estimateSize counted no inputs, so the
+	// input count is adjusted here.
+	estimatedSize += float64(wire.VarIntSerializeSize(uint64(len(selected))) - wire.VarIntSerializeSize(0))
+
 	options, err := types.MarshalMap(&preprocessOptions{
-		Coins:         coins,
-		EstimatedSize: s.estimateSize(request.Operations),
+		Coins:         selectedCoins,
+		Inputs:        inputs,
+		EstimatedSize: estimatedSize,
+		DustOutputs:   dustOutputs,
 		FeeMultiplier: request.SuggestedFeeMultiplier,
+		LockTime:      metadata.LockTime,
+		SignatureType: metadata.SignatureType,
+		PSBT:          metadata.PSBT,
+		Batch:         metadata.Batch,
+		BIP69:         metadata.BIP69,
+		Sender:        metadata.Sender,
+		ChangeAddress: changeAddress,
+		OutputAmount:  strconv.FormatInt(outputAmount, 10), // nolint:gomnd
+		FeeRate:       metadata.FeeRate,
+
+		PreviousTransactions: metadata.PreviousTransactions,
 	})
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
@@ -171,55 +873,451 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	}, nil
 }
 
+// unreservedCoins filters out the coins reserved by
+// transactions under construction.
+func (s *ConstructionAPIService) unreservedCoins(
+	ctx context.Context,
+	coins []*types.Coin,
+) ([]*types.Coin, *types.Error) {
+	if s.config.CoinReservationTTL <= 0 {
+		return coins, nil
+	}
+
+	reserved, err := s.i.GetCoinReservations(ctx, coins)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	unreserved := []*types.Coin{}
+	for i, coin := range coins {
+		if !reserved[i] {
+			unreserved = append(unreserved, coin)
+		}
+	}
+
+	return unreserved, nil
+}
+
+// matureCoins filters out the coinbase outputs
+// that have not reached coinbase maturity.
+func (s *ConstructionAPIService) matureCoins(
+	ctx context.Context,
+	coins []*types.Coin,
+) ([]*types.Coin, *types.Error) {
+	maturities, err := s.i.GetCoinMaturities(ctx, coins)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	mature := []*types.Coin{}
+	for i, coin := range coins {
+		if maturities[i] == 0 {
+			mature = append(mature, coin)
+		}
+	}
+
+	return mature, nil
+}
+
+// checkCoinMaturity returns ErrImmatureCoin if one of the coins
+// spent by a transaction under construction is a coinbase output
+// that cannot be spent in the next block.
+func (s *ConstructionAPIService) checkCoinMaturity(
+	ctx context.Context,
+	coins []*types.Coin,
+) *types.Error {
+	maturities, err := s.i.GetCoinMaturities(ctx, coins)
+	if err != nil {
+		return wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	for i, coin := range coins {
+		if maturities[i] > 0 {
+			return wrapErr(
+				ErrImmatureCoin,
+				fmt.Errorf(
+					"%s cannot be spent before block %d",
+					coin.CoinIdentifier.Identifier,
+					maturities[i],
+				),
+			)
+		}
+	}
+
+	return nil
+}
+
+// reserveCoins reserves the coins spent by a transaction under
+// construction, so they are not selected by another transaction
+// until the reservation expires or the coins are spent.
+func (s *ConstructionAPIService) reserveCoins(
+	ctx context.Context,
+	reservationID string,
+	coins []*types.Coin,
+) *types.Error {
+	if err := s.i.ReserveCoins(ctx, reservationID, coins, s.config.CoinReservationTTL); err != nil {
+		return wrapErr(ErrUnableToReserveCoins, err)
+	}
+
+	return nil
+}
+
+// releaseCoins releases the coins reserved under reservationID by
+// a transaction that could not be submitted. The transaction
+// is not broadcast, so failing to release its coins (they are
+// released once the reservation expires) is only logged.
+func (s *ConstructionAPIService) releaseCoins(
+	ctx context.Context,
+	reservationID string,
+	coins []*types.Coin,
+) {
+	if len(reservationID) == 0 {
+		return
+	}
+
+	if err := s.i.ReleaseCoins(ctx, reservationID, coins); err != nil {
+		utils.ExtractLogger(ctx, "construction").Warnw(
+			"unable to release coins",
+			"error", err,
+		)
+	}
+}
+
+// changeAmount returns the amount of the change output of a transaction
+// with automatically selected coins, or 0 if the remainder is too small
+// to be worth a change output, along with the fee actually paid.
+func (s *ConstructionAPIService) changeAmount(
+	options *preprocessOptions,
+	koinuPerB float64,
+	fee int64,
+) (int64, int64, *types.Error) {
+	inputAmount := int64(0)
+	for _, coin := range options.Coins {
+		value, err := strconv.ParseInt(coin.Amount.Value, 10, 64) // nolint:gomnd
+		if err != nil {
+			return -1, -1, wrapErr(ErrInvalidCoin, err)
+		}
+
+		inputAmount -= value
+	}
+
+	outputAmount, err := strconv.ParseInt(options.OutputAmount, 10, 64) // nolint:gomnd
+	if err != nil {
+		return -1, -1, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	remainder := inputAmount - outputAmount - fee
+	if remainder < 0 {
+		return -1, -1, wrapErr(
+			ErrInsufficientFunds,
+			fmt.Errorf("need %d more to cover the fee", -remainder),
+		)
+	}
+
+	changeSize, rErr := s.outputSize(options.ChangeAddress)
+	if rErr != nil {
+		return -1, -1, rErr
+	}
+
+	// Whatever is not returned as change is paid as fee.
+	change := remainder - int64(koinuPerB*float64(changeSize))
+	if change < dogecoin.DustLimit {
+		return 0, inputAmount - outputAmount, nil
+	}
+
+	return change, inputAmount - outputAmount - change, nil
+}
+
 // ConstructionMetadata implements the /construction/metadata endpoint.
 func (s *ConstructionAPIService) ConstructionMetadata(
 	ctx context.Context,
 	request *types.ConstructionMetadataRequest,
 ) (*types.ConstructionMetadataResponse, *types.Error) {
-	if s.config.Mode != configuration.Online {
-		return nil, wrapErr(ErrUnavailableOffline, nil)
-	}
-
 	var options preprocessOptions
 	if err := types.UnmarshalMap(request.Options, &options); err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	// Determine feePerKB and ensure it is not below the minimum fee
-	// relay rate.
-	feePerKB, err := s.client.SuggestedFeeRate(ctx, defaultConfirmationTarget)
+	// Offline, the scriptPubKeys of the inputs and
+	// the fee rate must be provided by the caller.
+	if s.config.Mode != configuration.Online &&
+		(len(options.ScriptPubKeys) == 0 || options.FeeRate == nil) {
+		return nil, wrapErr(
+			ErrUnavailableOffline,
+			errors.New("script_pub_keys and fee_rate must be provided offline"),
+		)
+	}
+
+	// Offline, a PSBT can only be built from the
+	// previous transactions provided by the caller.
+	if s.config.Mode != configuration.Online &&
+		options.PSBT && len(options.PreviousTransactions) == 0 {
+		return nil, wrapErr(
+			ErrUnavailableOffline,
+			errors.New("previous_transactions must be provided offline to build a psbt"),
+		)
+	}
+
+	// Immature coins can only be detected online,
+	// where the blocks that created them are indexed.
+	if s.config.Mode == configuration.Online {
+		if rErr := s.checkCoinMaturity(ctx, options.Coins); rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	var scripts []*bitcoin.ScriptPubKey
+	if len(options.ScriptPubKeys) > 0 {
+		var rErr *types.Error
+		scripts, rErr = s.providedScriptPubKeys(&options)
+		if rErr != nil {
+			return nil, rErr
+		}
+	} else {
+		var err error
+		scripts, err = s.i.GetScriptPubKeys(ctx, options.Coins)
+		if err != nil {
+			return nil, wrapErr(ErrScriptPubKeysMissing, err)
+		}
+	}
+
+	// Add the size of each input now that
+	// its scriptPubKey is known.
+	estimatedSize := options.EstimatedSize
+	for i, script := range scripts {
+		inputMeta := &inputMetadata{}
+		if i < len(options.Inputs) && options.Inputs[i] != nil {
+			inputMeta = options.Inputs[i]
+		}
+
+		inputSize, rErr := s.estimateInputSize(script, inputMeta)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		estimatedSize += float64(inputSize)
+	}
+
+	// Determine the fee rate and ensure it is not
+	// below the minimum fee relay rate.
+	koinuPerB, rErr := s.suggestedFeeRate(ctx, options.FeeRate, options.FeeMultiplier)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	// Calculated the estimated fee in koinu, every output
+	// below the dust limit adds the dust limit to the fee.
+	estimatedFee := int64(koinuPerB*estimatedSize) + options.DustOutputs*dogecoin.DustLimit
+	constructionMeta := &constructionMetadata{
+		ScriptPubKeys: scripts,
+		LockTime:      options.LockTime,
+		SignatureType: options.SignatureType,
+		PSBT:          options.PSBT,
+		Batch:         options.Batch,
+		BIP69:         options.BIP69,
+	}
+	if options.PSBT {
+		constructionMeta.PreviousTransactions, rErr = s.previousTransactions(ctx, &options)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+	if options.Sender != nil {
+		var change int64
+		change, estimatedFee, rErr = s.changeAmount(&options, koinuPerB, estimatedFee)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		constructionMeta.Sender = options.Sender
+		constructionMeta.Coins = options.Coins
+		constructionMeta.Inputs = options.Inputs
+		if change > 0 {
+			constructionMeta.ChangeAddress = options.ChangeAddress
+			constructionMeta.ChangeAmount = strconv.FormatInt(change, 10) // nolint:gomnd
+		}
+	}
+
+	if s.config.Mode == configuration.Online && s.config.CoinReservationTTL > 0 {
+		reservationID := make([]byte, reservationIDSize)
+		if _, err := rand.Read(reservationID); err != nil {
+			return nil, wrapErr(ErrUnableToReserveCoins, err)
+		}
+
+		constructionMeta.ReservationID = hex.EncodeToString(reservationID)
+		if rErr := s.reserveCoins(ctx, constructionMeta.ReservationID, options.Coins); rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	suggestedFee := &types.Amount{
+		Value:    fmt.Sprintf("%d", estimatedFee),
+		Currency: s.config.Currency,
+	}
+
+	metadata, err := types.MarshalMap(constructionMeta)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionMetadataResponse{
+		Metadata:     metadata,
+		SuggestedFee: []*types.Amount{suggestedFee},
+	}, nil
+}
+
+// previousTransactions returns the hex encoded transactions creating
+// the coins spent by a PSBT, which legacy inputs carry in full. They
+// are looked up in the indexer unless provided by the caller.
+func (s *ConstructionAPIService) previousTransactions(
+	ctx context.Context,
+	options *preprocessOptions,
+) ([]string, *types.Error) {
+	if len(options.PreviousTransactions) > 0 {
+		return options.PreviousTransactions, nil
+	}
+
+	hashes := []string{}
+	seen := map[string]struct{}{}
+	for _, coin := range options.Coins {
+		hash, _, err := bitcoin.ParseCoinIdentifier(coin.CoinIdentifier)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidCoin, err)
+		}
+
+		if _, ok := seen[hash.String()]; ok {
+			continue
+		}
+
+		seen[hash.String()] = struct{}{}
+		hashes = append(hashes, hash.String())
+	}
+
+	transactions, err := s.i.GetRawTransactions(ctx, hashes)
 	if err != nil {
-		return nil, wrapErr(ErrCouldNotGetFeeRate, err)
+		return nil, wrapErr(ErrUnableToFindTransaction, err)
 	}
-	if options.FeeMultiplier != nil {
-		feePerKB *= *options.FeeMultiplier
+
+	return transactions, nil
+}
+
+// providedScriptPubKeys returns the scriptPubKeys of the inputs
+// provided by the caller, decoded like the ones stored by the
+// indexer. Each must pay the address of its INPUT operation.
+func (s *ConstructionAPIService) providedScriptPubKeys(
+	options *preprocessOptions,
+) ([]*bitcoin.ScriptPubKey, *types.Error) {
+	if len(options.ScriptPubKeys) != len(options.Coins) ||
+		len(options.InputAddresses) != len(options.Coins) {
+		return nil, wrapErr(ErrInvalidScriptPubKeys, fmt.Errorf(
+			"expected %d script_pub_keys and input addresses, got %d and %d",
+			len(options.Coins),
+			len(options.ScriptPubKeys),
+			len(options.InputAddresses),
+		))
+	}
+
+	scripts := make([]*bitcoin.ScriptPubKey, len(options.ScriptPubKeys))
+	for i, scriptPubKey := range options.ScriptPubKeys {
+		if scriptPubKey == nil {
+			return nil, wrapErr(ErrInvalidScriptPubKeys, fmt.Errorf("script_pub_key %d is missing", i))
+		}
+
+		script, err := hex.DecodeString(scriptPubKey.Hex)
+		if err != nil {
+			return nil, wrapErr(
+				ErrInvalidScriptPubKeys,
+				fmt.Errorf("%w unable to decode script_pub_key %d", err, i),
+			)
+		}
+
+		_, addr, err := bitcoin.ParseSingleAddress(s.config.Params, script)
+		if err != nil {
+			return nil, wrapErr(
+				ErrInvalidScriptPubKeys,
+				fmt.Errorf("%w unable to parse address of script_pub_key %d", err, i),
+			)
+		}
+
+		if addr.EncodeAddress() != options.InputAddresses[i] {
+			return nil, wrapErr(ErrInvalidScriptPubKeys, fmt.Errorf(
+				"script_pub_key %d pays %s, not %s",
+				i,
+				addr.EncodeAddress(),
+				options.InputAddresses[i],
+			))
+		}
+
+		scripts[i] = bitcoin.DecodeScriptPubKey(s.config.Params, script)
 	}
-	if feePerKB < bitcoin.MinFeeRate {
-		feePerKB = bitcoin.MinFeeRate
+
+	return scripts, nil
+}
+
+// selectedOperations adds the INPUT operations of automatically
+// selected coins and the change OUTPUT operation to the operations
+// of an intent that only contains outputs.
+func selectedOperations(
+	operations []*types.Operation,
+	metadata *constructionMetadata,
+) ([]*types.Operation, *types.Error) {
+	if metadata.Sender == nil {
+		return operations, nil
 	}
 
-	// Calculated the estimated fee in Satoshis
-	satoshisPerB := (feePerKB * float64(dogecoin.SatoshisInBitcoin)) / bytesInKb
-	estimatedFee := satoshisPerB * options.EstimatedSize
-	suggestedFee := &types.Amount{
-		Value:    fmt.Sprintf("%d", int64(estimatedFee)),
-		Currency: s.config.Currency,
+	for _, operation := range operations {
+		if operation.Type == bitcoin.InputOpType {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				errors.New("inputs cannot be provided when coins are selected automatically"),
+			)
+		}
 	}
 
-	scripts, err := s.i.GetScriptPubKeys(ctx, options.Coins)
-	if err != nil {
-		return nil, wrapErr(ErrScriptPubKeysMissing, err)
+	selected := make([]*types.Operation, len(operations))
+	copy(selected, operations)
+	for i, coin := range metadata.Coins {
+		var opMetadata map[string]interface{}
+		if i < len(metadata.Inputs) && metadata.Inputs[i] != nil {
+			var err error
+			opMetadata, err = types.MarshalMap(metadata.Inputs[i])
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
+		}
+
+		selected = append(selected, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: int64(len(selected)),
+			},
+			Type:    bitcoin.InputOpType,
+			Account: metadata.Sender,
+			Amount:  coin.Amount,
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+			Metadata: opMetadata,
+		})
 	}
 
-	metadata, err := types.MarshalMap(&constructionMetadata{ScriptPubKeys: scripts})
-	if err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	if len(metadata.ChangeAmount) > 0 {
+		selected = append(selected, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: int64(len(selected)),
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: metadata.ChangeAddress,
+			},
+			Amount: &types.Amount{
+				Value:    metadata.ChangeAmount,
+				Currency: metadata.Coins[0].Amount.Currency,
+			},
+		})
 	}
 
-	return &types.ConstructionMetadataResponse{
-		Metadata:     metadata,
-		SuggestedFee: []*types.Amount{suggestedFee},
-	}, nil
+	return selected, nil
 }
 
 // ConstructionPayloads implements the /construction/payloads endpoint.
@@ -254,17 +1352,79 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 				},
 				AllowRepeats: true,
 			},
+			{
+				Type: bitcoin.DataOpType,
+				Account: &parser.AccountDescription{
+					Exists: false,
+				},
+				Amount: &parser.AmountDescription{
+					Exists: false,
+				},
+				Optional: true,
+			},
 		},
 		ErrUnmatched: true,
 	}
 
-	matches, err := parser.MatchOperations(descriptions, request.Operations)
+	var metadata constructionMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	operations, rErr := selectedOperations(request.Operations, &metadata)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	matches, err := parser.MatchOperations(descriptions, operations)
 	if err != nil {
 		return nil, wrapErr(ErrUnclearIntent, err)
 	}
 
+	// Renew the reservation made in ConstructionMetadata
+	// while the transaction is signed and broadcast.
+	if len(metadata.ReservationID) > 0 && s.config.Mode == configuration.Online {
+		coins := make([]*types.Coin, len(matches[0].Operations))
+		for i, input := range matches[0].Operations {
+			if input.CoinChange == nil {
+				return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
+			}
+
+			coins[i] = &types.Coin{
+				CoinIdentifier: input.CoinChange.CoinIdentifier,
+				Amount:         input.Amount,
+			}
+		}
+
+		if rErr := s.reserveCoins(ctx, metadata.ReservationID, coins); rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	if rErr := validateSignatureType(metadata.SignatureType); rErr != nil {
+		return nil, rErr
+	}
+
+	signatureType := metadata.SignatureType
+	if len(signatureType) == 0 {
+		signatureType = types.Ecdsa
+	}
+
+	// Any lock time is valid: below txscript.LockTimeThreshold
+	// it is a height, otherwise a unix timestamp. Inputs must
+	// not all be final for it to be enforced, so inputs without
+	// a sequence default to the highest non-final sequence
+	// when locked.
+	defaultSequence := uint32(wire.MaxTxInSequenceNum)
+	if metadata.LockTime > 0 {
+		defaultSequence = wire.MaxTxInSequenceNum - 1
+	}
+
 	tx := wire.NewMsgTx(wire.TxVersion)
-	for _, input := range matches[0].Operations {
+	tx.LockTime = metadata.LockTime
+	inputsMeta := make([]*inputMetadata, len(matches[0].Operations))
+	final := true
+	for i, input := range matches[0].Operations {
 		if input.CoinChange == nil {
 			return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
 		}
@@ -274,17 +1434,63 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 			return nil, wrapErr(ErrInvalidCoin, err)
 		}
 
+		var inputMeta inputMetadata
+		if err := types.UnmarshalMap(input.Metadata, &inputMeta); err != nil {
+			return nil, wrapErr(ErrUnclearIntent, fmt.Errorf("%w unable to parse input metadata", err))
+		}
+		inputsMeta[i] = &inputMeta
+
+		sequence := defaultSequence
+		if inputMeta.Sequence != nil {
+			sequence = *inputMeta.Sequence
+		}
+		final = final && sequence == wire.MaxTxInSequenceNum
+
 		tx.AddTxIn(&wire.TxIn{
 			PreviousOutPoint: wire.OutPoint{
 				Hash:  *transactionHash,
 				Index: index,
 			},
 			SignatureScript: nil,
-			Sequence:        wire.MaxTxInSequenceNum,
+			Sequence:        sequence,
 		})
 	}
 
+	if metadata.LockTime > 0 && final {
+		return nil, wrapErr(
+			ErrInvalidLockTime,
+			errors.New("lock time is not enforced when all inputs are final"),
+		)
+	}
+
+	var mappings []*operationMapping
+	batched := metadata.Batch || metadata.BIP69
+	if batched {
+		mappings = make([]*operationMapping, 0, len(operations))
+		for i, input := range matches[0].Operations {
+			mappings = append(mappings, &operationMapping{
+				Index:        input.OperationIdentifier.Index,
+				Type:         bitcoin.InputOpType,
+				NetworkIndex: int64(i),
+			})
+		}
+	}
+
+	// Outputs paying the same address are merged
+	// when batching.
+	outputIndexes := map[string]int{}
 	for i, output := range matches[1].Operations {
+		if outputIndex, ok := outputIndexes[output.Account.Address]; ok && metadata.Batch {
+			tx.TxOut[outputIndex].Value += matches[1].Amounts[i].Int64()
+			mappings = append(mappings, &operationMapping{
+				Index:        output.OperationIdentifier.Index,
+				Type:         bitcoin.OutputOpType,
+				NetworkIndex: int64(outputIndex),
+				Amount:       matches[1].Amounts[i].String(),
+			})
+			continue
+		}
+
 		addr, err := btcutil.DecodeAddress(output.Account.Address, s.config.Params)
 		if err != nil {
 			return nil, wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
@@ -303,22 +1509,69 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 			)
 		}
 
+		if batched {
+			mappings = append(mappings, &operationMapping{
+				Index:        output.OperationIdentifier.Index,
+				Type:         bitcoin.OutputOpType,
+				NetworkIndex: int64(len(tx.TxOut)),
+				Amount:       matches[1].Amounts[i].String(),
+			})
+		}
+
+		outputIndexes[output.Account.Address] = len(tx.TxOut)
 		tx.AddTxOut(&wire.TxOut{
 			Value:    matches[1].Amounts[i].Int64(),
 			PkScript: pkScript,
 		})
 	}
 
+	if metadata.Batch {
+		for i, output := range tx.TxOut {
+			if output.Value < dogecoin.DustLimit {
+				return nil, wrapErr(
+					ErrDustOutput,
+					fmt.Errorf("output %d of %d is below %d", i, output.Value, dogecoin.DustLimit),
+				)
+			}
+		}
+	}
+
+	// The OP_RETURN output is provably unspendable,
+	// so it does not carry any value.
+	if matches[2] != nil {
+		pkScript, rErr := dataScript(matches[2].Operations[0])
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		if batched {
+			mappings = append(mappings, &operationMapping{
+				Index:        matches[2].Operations[0].OperationIdentifier.Index,
+				Type:         bitcoin.DataOpType,
+				NetworkIndex: int64(len(tx.TxOut)),
+			})
+		}
+
+		tx.AddTxOut(&wire.TxOut{
+			Value:    0,
+			PkScript: pkScript,
+		})
+	}
+
+	if metadata.BIP69 {
+		if rErr := sortTransaction(tx, matches[0], metadata.ScriptPubKeys, inputsMeta, mappings); rErr != nil {
+			return nil, rErr
+		}
+	}
+
 	// Create Signing Payloads (must be done after entire tx is constructed
 	// or hash will not be correct).
 	inputAmounts := make([]string, len(tx.TxIn))
 	inputAddresses := make([]string, len(tx.TxIn))
-	payloads := make([]*types.SigningPayload, len(tx.TxIn))
-	var metadata constructionMetadata
-	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
-	}
-
+	redeemScripts := make([]string, len(tx.TxIn))
+	hashTypes := make([]txscript.SigHashType, len(tx.TxIn))
+	defaultHashTypes := true
+	payloads := []*types.SigningPayload{}
 	for i := range tx.TxIn {
 		address := matches[0].Operations[i].Account.Address
 		script, err := hex.DecodeString(metadata.ScriptPubKeys[i].Hex)
@@ -326,6 +1579,23 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
 		}
 
+		hashType, err := parseSigHashType(inputsMeta[i].SigHashType)
+		if err != nil {
+			return nil, wrapErr(ErrUnclearIntent, fmt.Errorf("%w for utxo %d", err, i))
+		}
+
+		// SIGHASH_SINGLE signs the output with the same index
+		// as the input, which must exist.
+		if hashType&sigHashMask == txscript.SigHashSingle && i >= len(tx.TxOut) {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				fmt.Errorf("utxo %d has no matching output for SIGHASH_SINGLE", i),
+			)
+		}
+
+		hashTypes[i] = hashType
+		defaultHashTypes = defaultHashTypes && hashType == txscript.SigHashAll
+
 		class, _, err := bitcoin.ParseSingleAddress(s.config.Params, script)
 		if err != nil {
 			return nil, wrapErr(
@@ -339,11 +1609,59 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		absAmount := new(big.Int).Abs(matches[0].Amounts[i]).Int64()
 
 		switch class {
+		case txscript.PubKeyHashTy:
+			hash, err := txscript.CalcSignatureHash(
+				script,
+				hashType,
+				tx,
+				i,
+			)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
+			}
+
+			payloads = append(payloads, &types.SigningPayload{
+				AccountIdentifier: &types.AccountIdentifier{
+					Address: address,
+				},
+				Bytes:         hash,
+				SignatureType: signatureType,
+			})
+		case txscript.ScriptHashTy:
+			redeemScript, signers, err := s.parseRedeemScript(script, inputsMeta[i])
+			if err != nil {
+				return nil, wrapErr(
+					ErrInvalidRedeemScript,
+					fmt.Errorf("%w for utxo %d", err, i),
+				)
+			}
+
+			hash, err := txscript.CalcSignatureHash(
+				redeemScript,
+				hashType,
+				tx,
+				i,
+			)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
+			}
+
+			for _, signer := range signers {
+				payloads = append(payloads, &types.SigningPayload{
+					AccountIdentifier: &types.AccountIdentifier{
+						Address: signer.AddressPubKeyHash().EncodeAddress(),
+					},
+					Bytes:         hash,
+					SignatureType: signatureType,
+				})
+			}
+
+			redeemScripts[i] = hex.EncodeToString(redeemScript)
 		case txscript.WitnessV0PubKeyHashTy:
 			hash, err := txscript.CalcWitnessSigHash(
 				script,
 				txscript.NewTxSigHashes(tx),
-				txscript.SigHashAll,
+				hashType,
 				tx,
 				i,
 				absAmount,
@@ -352,13 +1670,13 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
 			}
 
-			payloads[i] = &types.SigningPayload{
+			payloads = append(payloads, &types.SigningPayload{
 				AccountIdentifier: &types.AccountIdentifier{
 					Address: address,
 				},
 				Bytes:         hash,
-				SignatureType: types.Ecdsa,
-			}
+				SignatureType: signatureType,
+			})
 		default:
 			return nil, wrapErr(
 				ErrUnsupportedScriptType,
@@ -372,12 +1690,35 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	rawTx, err := json.Marshal(&unsignedTransaction{
+	unsigned := &unsignedTransaction{
 		Transaction:    hex.EncodeToString(buf.Bytes()),
 		ScriptPubKeys:  metadata.ScriptPubKeys,
 		InputAmounts:   inputAmounts,
 		InputAddresses: inputAddresses,
-	})
+		RedeemScripts:  redeemScripts,
+		ReservationID:  metadata.ReservationID,
+	}
+	if !defaultHashTypes {
+		unsigned.SigHashTypes = hashTypes
+	}
+
+	if batched {
+		unsigned.Operations = mappings
+	}
+
+	if metadata.PSBT {
+		packet, err := encodePSBT(tx, unsigned, metadata.PreviousTransactions)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		return &types.ConstructionPayloadsResponse{
+			UnsignedTransaction: packet,
+			Payloads:            payloads,
+		}, nil
+	}
+
+	rawTx, err := json.Marshal(unsigned)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
@@ -388,24 +1729,254 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 	}, nil
 }
 
-func normalizeSignature(signature []byte) []byte {
-	sig := btcec.Signature{ // signature is in form of R || S
-		R: new(big.Int).SetBytes(signature[:32]),
-		S: new(big.Int).SetBytes(signature[32:64]),
+// parseRedeemScript decodes the redeem script of a P2SH input, ensures
+// it hashes to the provided scriptPubKey, and returns it along with
+// the public keys expected to sign it (in redeem script order).
+func (s *ConstructionAPIService) parseRedeemScript(
+	scriptPubKey []byte,
+	metadata *inputMetadata,
+) ([]byte, []*btcutil.AddressPubKey, error) {
+	if len(metadata.RedeemScript) == 0 {
+		return nil, nil, errors.New("redeem script is missing")
+	}
+
+	redeemScript, err := hex.DecodeString(metadata.RedeemScript)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w unable to decode redeem script", err)
+	}
+
+	addr, err := btcutil.NewAddressScriptHash(redeemScript, s.config.Params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w unable to hash redeem script", err)
+	}
+
+	expectedScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w unable to construct payToAddrScript", err)
+	}
+
+	if !bytes.Equal(expectedScript, scriptPubKey) {
+		return nil, nil, errors.New("redeem script does not match scriptPubKey")
+	}
+
+	pubKeys, nRequired, err := bitcoin.ParseMultisigScript(s.config.Params, redeemScript)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(metadata.Signers) == 0 {
+		return redeemScript, pubKeys[:nRequired], nil
+	}
+
+	if len(metadata.Signers) != nRequired {
+		return nil, nil, fmt.Errorf(
+			"expected %d signers, got %d",
+			nRequired,
+			len(metadata.Signers),
+		)
+	}
+
+	signers := []*btcutil.AddressPubKey{}
+	for _, pubKey := range pubKeys {
+		for _, signer := range metadata.Signers {
+			if hex.EncodeToString(pubKey.ScriptAddress()) == signer {
+				signers = append(signers, pubKey)
+				break
+			}
+		}
+	}
+
+	if len(signers) != nRequired {
+		return nil, nil, errors.New("signers must be distinct public keys of the redeem script")
 	}
 
-	return append(sig.Serialize(), byte(txscript.SigHashAll))
+	return redeemScript, signers, nil
 }
 
-// ConstructionCombine implements the /construction/combine
-// endpoint.
-func (s *ConstructionAPIService) ConstructionCombine(
-	ctx context.Context,
-	request *types.ConstructionCombineRequest,
-) (*types.ConstructionCombineResponse, *types.Error) {
-	decodedTx, err := hex.DecodeString(request.UnsignedTransaction)
+// parseSignature returns the DER encoding of a signature
+// provided to ConstructionCombine. ecdsa signatures
+// are 64 bytes (R || S), ecdsa_recovery signatures are 65 bytes
+// (R || S || V) and both may be DER encoded instead. Signatures
+// must verify against their signing payload and are serialized
+// with a low S value, as required by the standardness rules.
+func parseSignature(signature *types.Signature) ([]byte, error) {
+	var compactSize int
+	switch signature.SignatureType {
+	case types.Ecdsa:
+		compactSize = ecdsaSignatureSize
+	case types.EcdsaRecovery:
+		compactSize = ecdsaSignatureSize + 1
+	default:
+		return nil, fmt.Errorf("%s signatures are not supported", signature.SignatureType)
+	}
+
+	var sig *btcec.Signature
+	if len(signature.Bytes) == compactSize {
+		sig = &btcec.Signature{
+			R: new(big.Int).SetBytes(signature.Bytes[:32]),
+			S: new(big.Int).SetBytes(signature.Bytes[32:64]),
+		}
+
+		n := btcec.S256().N
+		if sig.R.Sign() != 1 || sig.R.Cmp(n) >= 0 || sig.S.Sign() != 1 || sig.S.Cmp(n) >= 0 {
+			return nil, errors.New("signature values are out of range")
+		}
+	} else {
+		der := signature.Bytes
+		if len(der) > 0 {
+			// The DER encoding may be followed by the sighash type.
+			if parsed, err := btcec.ParseDERSignature(der[:len(der)-1], btcec.S256()); err == nil {
+				sig = parsed
+			}
+		}
+
+		if sig == nil {
+			parsed, err := btcec.ParseDERSignature(der, btcec.S256())
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%w: expected %d bytes or a DER encoding, got %d bytes",
+					err,
+					compactSize,
+					len(der),
+				)
+			}
+
+			sig = parsed
+		}
+	}
+
+	pubKey, err := btcec.ParsePubKey(signature.PublicKey.Bytes, btcec.S256())
 	if err != nil {
-		return nil, wrapErr(
+		return nil, fmt.Errorf("%w unable to parse public key", err)
+	}
+
+	if !sig.Verify(signature.SigningPayload.Bytes, pubKey) {
+		return nil, errors.New("signature does not verify against the signing payload")
+	}
+
+	// Serialize always encodes the low S value.
+	return sig.Serialize(), nil
+}
+
+// multisigSignatureScript assembles the OP_0 <sigs...> <redeemScript>
+// signature script of a P2SH multisig input from the next signatures,
+// whose encodings are provided in encoded, and returns the number of
+// signatures consumed.
+func (s *ConstructionAPIService) multisigSignatureScript(
+	redeemScript []byte,
+	signatures []*types.Signature,
+	encoded [][]byte,
+) ([]byte, int, error) {
+	pubKeys, nRequired, err := bitcoin.ParseMultisigScript(s.config.Params, redeemScript)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if len(signatures) < nRequired {
+		return nil, -1, fmt.Errorf("expected %d signatures, got %d", nRequired, len(signatures))
+	}
+
+	// OP_CHECKMULTISIG requires signatures to be in the
+	// same order as the public keys in the redeem script.
+	ordered := make([][]byte, len(pubKeys))
+	for i, signature := range signatures[:nRequired] {
+		position := -1
+		for j, pubKey := range pubKeys {
+			if bytes.Equal(pubKey.ScriptAddress(), signature.PublicKey.Bytes) {
+				position = j
+				break
+			}
+		}
+
+		if position == -1 {
+			return nil, -1, fmt.Errorf(
+				"public key %x is not part of the redeem script",
+				signature.PublicKey.Bytes,
+			)
+		}
+
+		if ordered[position] != nil {
+			return nil, -1, fmt.Errorf("duplicate signature for public key %x", signature.PublicKey.Bytes)
+		}
+
+		ordered[position] = encoded[i]
+	}
+
+	builder := txscript.NewScriptBuilder().AddOp(txscript.OP_0)
+	for _, sig := range ordered {
+		if sig != nil {
+			builder.AddData(sig)
+		}
+	}
+
+	sigScript, err := builder.AddData(redeemScript).Script()
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return sigScript, nRequired, nil
+}
+
+// verifyScripts runs the script engine for every input of tx
+// against the scripts and amounts of the outputs it spends.
+// The returned error names the first input that fails.
+func verifyScripts(tx *wire.MsgTx, pkScripts [][]byte, amounts []string) *types.Error {
+	hashCache := txscript.NewTxSigHashes(tx)
+	for i := range tx.TxIn {
+		amount, ok := new(big.Int).SetString(amounts[i], 10) // nolint:gomnd
+		if !ok {
+			return wrapErr(
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("unable to parse amount of input %d", i),
+			)
+		}
+
+		vm, err := txscript.NewEngine(
+			pkScripts[i],
+			tx,
+			i,
+			txscript.StandardVerifyFlags,
+			nil,
+			hashCache,
+			new(big.Int).Abs(amount).Int64(),
+		)
+		if err == nil {
+			err = vm.Execute()
+		}
+
+		if err != nil {
+			rErr := wrapErr(
+				ErrScriptVerificationFailed,
+				fmt.Errorf("%w: input %d", err, i),
+			)
+			rErr.Details["input_index"] = i
+
+			return rErr
+		}
+	}
+
+	return nil
+}
+
+// decodeUnsignedTransaction decodes the unsigned transaction returned
+// from ConstructionPayloads, either a hex encoded unsignedTransaction
+// or a base64 encoded PSBT. The returned packet is nil unless the
+// transaction is a PSBT.
+func (s *ConstructionAPIService) decodeUnsignedTransaction(
+	raw string,
+) (*unsignedTransaction, *wire.MsgTx, *psbt.Packet, *types.Error) {
+	if isPSBT(raw) {
+		packet, unsigned, err := decodePSBT(s.config.Params, raw)
+		if err != nil {
+			return nil, nil, nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		return unsigned, packet.UnsignedTx, packet, nil
+	}
+
+	decodedTx, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, nil, nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
 			fmt.Errorf("%w transaction cannot be decoded", err),
 		)
@@ -413,7 +1984,7 @@ func (s *ConstructionAPIService) ConstructionCombine(
 
 	var unsigned unsignedTransaction
 	if err := json.Unmarshal(decodedTx, &unsigned); err != nil {
-		return nil, wrapErr(
+		return nil, nil, nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
 			fmt.Errorf("%w unable to unmarshal bitcoin transaction", err),
 		)
@@ -421,7 +1992,7 @@ func (s *ConstructionAPIService) ConstructionCombine(
 
 	decodedCoreTx, err := hex.DecodeString(unsigned.Transaction)
 	if err != nil {
-		return nil, wrapErr(
+		return nil, nil, nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
 			fmt.Errorf("%w transaction cannot be decoded", err),
 		)
@@ -429,13 +2000,68 @@ func (s *ConstructionAPIService) ConstructionCombine(
 
 	var tx wire.MsgTx
 	if err := tx.Deserialize(bytes.NewReader(decodedCoreTx)); err != nil {
-		return nil, wrapErr(
+		return nil, nil, nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
 			fmt.Errorf("%w unable to deserialize tx", err),
 		)
 	}
 
-	for i := range tx.TxIn {
+	return &unsigned, &tx, nil, nil
+}
+
+// signTransaction adds the signatures provided to ConstructionCombine
+// to tx and verifies the scripts of its inputs. When tx is decoded
+// from a PSBT, finalized inputs are kept as they are and the partial
+// signatures of the other inputs are used before the signatures
+// provided.
+func (s *ConstructionAPIService) signTransaction(
+	unsigned *unsignedTransaction,
+	tx *wire.MsgTx,
+	packet *psbt.Packet,
+	signatures []*types.Signature,
+) (*signedTransaction, *types.Error) {
+	// Signatures are validated and encoded up front, so
+	// malformed signatures are reported as such.
+	encoded := make([][]byte, len(signatures))
+	for i, signature := range signatures {
+		sig, err := parseSignature(signature)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidSignature, fmt.Errorf("%w: signature %d", err, i))
+		}
+
+		encoded[i] = sig
+	}
+
+	// Signatures are provided in the same order as the signing
+	// payloads, so each input consumes as many signatures
+	// as it has payloads.
+	next := 0
+	for i := range tx.TxIn {
+		var inputSignatures []*types.Signature
+		var inputEncoded [][]byte
+		if packet != nil {
+			input := &packet.Inputs[i]
+			if len(input.FinalScriptSig) > 0 || len(input.FinalScriptWitness) > 0 {
+				witness, err := parseWitness(input.FinalScriptWitness)
+				if err != nil {
+					return nil, wrapErr(
+						ErrUnableToParseIntermediateResult,
+						fmt.Errorf("%w unable to parse final witness of input %d", err, i),
+					)
+				}
+
+				tx.TxIn[i].SignatureScript = input.FinalScriptSig
+				tx.TxIn[i].Witness = witness
+				continue
+			}
+
+			var err error
+			inputSignatures, inputEncoded, err = psbtSignatures(input, inputSigHashType(unsigned, i))
+			if err != nil {
+				return nil, wrapErr(ErrInvalidSignature, fmt.Errorf("%w: input %d", err, i))
+			}
+		}
+
 		decodedScript, err := hex.DecodeString(unsigned.ScriptPubKeys[i].Hex)
 		if err != nil {
 			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
@@ -449,18 +2075,98 @@ func (s *ConstructionAPIService) ConstructionCombine(
 			)
 		}
 
-		pkData := request.Signatures[i].PublicKey.Bytes
-		fullsig := normalizeSignature(request.Signatures[i].Bytes)
+		// Partial signatures of a PSBT come before the
+		// signatures provided for the same input.
+		hashType := inputSigHashType(unsigned, i)
+		available := append(inputSignatures, signatures[next:]...)
+		availableEncoded := appendSigHashType(append(inputEncoded, encoded[next:]...), hashType)
 
+		var consumed int
 		switch class {
+		case txscript.PubKeyHashTy:
+			if len(available) == 0 {
+				return nil, wrapErr(
+					ErrUnexpectedSignatureCount,
+					fmt.Errorf("missing signature for input %d", i),
+				)
+			}
+
+			sigScript, err := txscript.NewScriptBuilder().
+				AddData(availableEncoded[0]).
+				AddData(available[0].PublicKey.Bytes).
+				Script()
+			if err != nil {
+				return nil, wrapErr(
+					ErrUnableToBuildSignatureScript,
+					fmt.Errorf("%w unable to build signature script for input %d", err, i),
+				)
+			}
+
+			tx.TxIn[i].SignatureScript = sigScript
+			consumed = 1
+		case txscript.ScriptHashTy:
+			redeemScript, err := hex.DecodeString(unsigned.RedeemScripts[i])
+			if err != nil {
+				return nil, wrapErr(
+					ErrInvalidRedeemScript,
+					fmt.Errorf("%w unable to decode redeem script for input %d", err, i),
+				)
+			}
+
+			sigScript, n, err := s.multisigSignatureScript(
+				redeemScript,
+				available,
+				availableEncoded,
+			)
+			if err != nil {
+				return nil, wrapErr(
+					ErrUnableToBuildSignatureScript,
+					fmt.Errorf("%w unable to build signature script for input %d", err, i),
+				)
+			}
+
+			tx.TxIn[i].SignatureScript = sigScript
+			consumed = n
 		case txscript.WitnessV0PubKeyHashTy:
-			tx.TxIn[i].Witness = wire.TxWitness{fullsig, pkData}
+			if len(available) == 0 {
+				return nil, wrapErr(
+					ErrUnexpectedSignatureCount,
+					fmt.Errorf("missing signature for input %d", i),
+				)
+			}
+
+			tx.TxIn[i].Witness = wire.TxWitness{availableEncoded[0], available[0].PublicKey.Bytes}
+			consumed = 1
 		default:
 			return nil, wrapErr(
 				ErrUnsupportedScriptType,
 				fmt.Errorf("unupported script type: %s", class),
 			)
 		}
+
+		if consumed > len(inputSignatures) {
+			next += consumed - len(inputSignatures)
+		}
+	}
+
+	if next < len(signatures) {
+		return nil, wrapErr(
+			ErrUnexpectedSignatureCount,
+			fmt.Errorf("%d unused signatures", len(signatures)-next),
+		)
+	}
+
+	pkScripts := make([][]byte, len(tx.TxIn))
+	for i := range tx.TxIn {
+		var err error
+		pkScripts[i], err = hex.DecodeString(unsigned.ScriptPubKeys[i].Hex)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
+		}
+	}
+
+	if rErr := verifyScripts(tx, pkScripts, unsigned.InputAmounts); rErr != nil {
+		return nil, rErr
 	}
 
 	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
@@ -468,10 +2174,67 @@ func (s *ConstructionAPIService) ConstructionCombine(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w serialize tx", err))
 	}
 
-	rawTx, err := json.Marshal(&signedTransaction{
-		Transaction:  hex.EncodeToString(buf.Bytes()),
-		InputAmounts: unsigned.InputAmounts,
-	})
+	return &signedTransaction{
+		Transaction:   hex.EncodeToString(buf.Bytes()),
+		InputAmounts:  unsigned.InputAmounts,
+		Operations:    unsigned.Operations,
+		ReservationID: unsigned.ReservationID,
+	}, nil
+}
+
+// appendSigHashType returns the DER encoded signatures
+// followed by the sighash type they are signed with.
+func appendSigHashType(signatures [][]byte, hashType txscript.SigHashType) [][]byte {
+	encoded := make([][]byte, len(signatures))
+	for i, signature := range signatures {
+		encoded[i] = append(append([]byte{}, signature...), byte(hashType))
+	}
+
+	return encoded
+}
+
+// parseWitness decodes the serialized witness of a finalized
+// PSBT input.
+func parseWitness(serialized []byte) (wire.TxWitness, error) {
+	if len(serialized) == 0 {
+		return nil, nil
+	}
+
+	r := bytes.NewReader(serialized)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, txscript.MaxScriptSize, "witness item")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return witness, nil
+}
+
+// ConstructionCombine implements the /construction/combine
+// endpoint. The unsigned transaction may be a fully or
+// partially signed PSBT, which is finalized.
+func (s *ConstructionAPIService) ConstructionCombine(
+	ctx context.Context,
+	request *types.ConstructionCombineRequest,
+) (*types.ConstructionCombineResponse, *types.Error) {
+	unsigned, tx, packet, rErr := s.decodeUnsignedTransaction(request.UnsignedTransaction)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	signed, rErr := s.signTransaction(unsigned, tx, packet, request.Signatures)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	rawTx, err := json.Marshal(signed)
 	if err != nil {
 		return nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
@@ -531,40 +2294,18 @@ func (s *ConstructionAPIService) ConstructionHash(
 func (s *ConstructionAPIService) parseUnsignedTransaction(
 	request *types.ConstructionParseRequest,
 ) (*types.ConstructionParseResponse, *types.Error) {
-	decodedTx, err := hex.DecodeString(request.Transaction)
-	if err != nil {
-		return nil, wrapErr(
-			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w transaction cannot be decoded", err),
-		)
-	}
-
-	var unsigned unsignedTransaction
-	if err := json.Unmarshal(decodedTx, &unsigned); err != nil {
-		return nil, wrapErr(
-			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w unable to unmarshal bitcoin transaction", err),
-		)
-	}
-
-	decodedCoreTx, err := hex.DecodeString(unsigned.Transaction)
-	if err != nil {
-		return nil, wrapErr(
-			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w transaction cannot be decoded", err),
-		)
-	}
-
-	var tx wire.MsgTx
-	if err := tx.Deserialize(bytes.NewReader(decodedCoreTx)); err != nil {
-		return nil, wrapErr(
-			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w unable to deserialize tx", err),
-		)
+	unsigned, tx, _, rErr := s.decodeUnsignedTransaction(request.Transaction)
+	if rErr != nil {
+		return nil, rErr
 	}
 
 	ops := []*types.Operation{}
 	for i, input := range tx.TxIn {
+		metadata, rErr := parsedInputMetadata(input, inputSigHashType(unsigned, i))
+		if rErr != nil {
+			return nil, rErr
+		}
+
 		networkIndex := int64(i)
 		ops = append(ops, &types.Operation{
 			OperationIdentifier: &types.OperationIdentifier{
@@ -589,11 +2330,130 @@ func (s *ConstructionAPIService) parseUnsignedTransaction(
 					),
 				},
 			},
+			Metadata: metadata,
 		})
 	}
 
+	ops, rErr = s.parseOutputOperations(tx, ops)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	ops, rErr = remapOperations(ops, unsigned.Operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	metadata, rErr := transactionMetadata(tx)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 metadata,
+	}, nil
+}
+
+// parsedInputMetadata returns the metadata of an INPUT operation
+// with a non-final sequence or a sighash type other than
+// SIGHASH_ALL, or nil if there is neither.
+func parsedInputMetadata(
+	input *wire.TxIn,
+	hashType txscript.SigHashType,
+) (map[string]interface{}, *types.Error) {
+	var inputMeta inputMetadata
+	if input.Sequence != wire.MaxTxInSequenceNum {
+		sequence := input.Sequence
+		inputMeta.Sequence = &sequence
+	}
+
+	if hashType != txscript.SigHashAll {
+		inputMeta.SigHashType = sigHashTypeName(hashType)
+	}
+
+	if inputMeta.Sequence == nil && len(inputMeta.SigHashType) == 0 {
+		return nil, nil
+	}
+
+	metadata, err := types.MarshalMap(&inputMeta)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return metadata, nil
+}
+
+// signatureHashType returns the sighash type of the first
+// signature in the signature script or witness of an input.
+func signatureHashType(input *wire.TxIn) txscript.SigHashType {
+	pushes := [][]byte(input.Witness)
+	if len(pushes) == 0 {
+		data, err := txscript.PushedData(input.SignatureScript)
+		if err != nil {
+			return txscript.SigHashAll
+		}
+
+		pushes = data
+	}
+
+	for _, push := range pushes {
+		if len(push) > 0 {
+			return txscript.SigHashType(push[len(push)-1])
+		}
+	}
+
+	return txscript.SigHashAll
+}
+
+// transactionMetadata returns the metadata of a parsed
+// transaction, or nil if the transaction is not locked.
+func transactionMetadata(tx *wire.MsgTx) (map[string]interface{}, *types.Error) {
+	if tx.LockTime == 0 {
+		return nil, nil
+	}
+
+	metadata, err := types.MarshalMap(&parseMetadata{LockTime: tx.LockTime})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return metadata, nil
+}
+
+// parseOutputOperations appends the OUTPUT and
+// DATA operations of the outputs of tx to ops.
+func (s *ConstructionAPIService) parseOutputOperations(
+	tx *wire.MsgTx,
+	ops []*types.Operation,
+) ([]*types.Operation, *types.Error) {
 	for i, output := range tx.TxOut {
 		networkIndex := int64(i)
+		if txscript.GetScriptClass(output.PkScript) == txscript.NullDataTy {
+			pushes, err := txscript.PushedData(output.PkScript)
+			if err != nil {
+				return nil, wrapErr(ErrInvalidData, err)
+			}
+
+			metadata, err := types.MarshalMap(&dataMetadata{
+				Data: hex.EncodeToString(bytes.Join(pushes, nil)),
+			})
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
+
+			ops = append(ops, &types.Operation{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index:        int64(len(ops)),
+					NetworkIndex: &networkIndex,
+				},
+				Type:     bitcoin.DataOpType,
+				Metadata: metadata,
+			})
+			continue
+		}
+
 		_, addr, err := bitcoin.ParseSingleAddress(s.config.Params, output.PkScript)
 		if err != nil {
 			return nil, wrapErr(
@@ -618,49 +2478,142 @@ func (s *ConstructionAPIService) parseUnsignedTransaction(
 		})
 	}
 
-	return &types.ConstructionParseResponse{
-		Operations:               ops,
-		AccountIdentifierSigners: []*types.AccountIdentifier{},
-	}, nil
+	return ops, nil
 }
 
-func (s *ConstructionAPIService) parseSignedTransaction(
-	request *types.ConstructionParseRequest,
-) (*types.ConstructionParseResponse, *types.Error) {
-	decodedTx, err := hex.DecodeString(request.Transaction)
+// multisigSigners returns the accounts of the public keys that
+// signed a P2SH multisig input. Signatures are matched to public
+// keys in the same order OP_CHECKMULTISIG evaluates them.
+func (s *ConstructionAPIService) multisigSigners(
+	tx *wire.MsgTx,
+	index int,
+) ([]*types.AccountIdentifier, error) {
+	pushes, err := txscript.PushedData(tx.TxIn[index].SignatureScript)
+	if err != nil {
+		return nil, fmt.Errorf("%w unable to parse signature script", err)
+	}
+
+	if len(pushes) < 2 { // nolint:gomnd
+		return nil, errors.New("signature script is missing signatures or redeem script")
+	}
+
+	redeemScript := pushes[len(pushes)-1]
+	pubKeys, _, err := bitcoin.ParseMultisigScript(s.config.Params, redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := []*types.AccountIdentifier{}
+	keyIndex := 0
+	for _, rawSig := range pushes[:len(pushes)-1] {
+		// Skip the dummy element consumed by OP_CHECKMULTISIG.
+		if len(rawSig) == 0 {
+			continue
+		}
+
+		hashType := txscript.SigHashType(rawSig[len(rawSig)-1])
+		sig, err := btcec.ParseDERSignature(rawSig[:len(rawSig)-1], btcec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("%w unable to parse signature", err)
+		}
+
+		hash, err := txscript.CalcSignatureHash(redeemScript, hashType, tx, index)
+		if err != nil {
+			return nil, fmt.Errorf("%w unable to calculate signature hash", err)
+		}
+
+		matched := false
+		for ; keyIndex < len(pubKeys); keyIndex++ {
+			if sig.Verify(hash, pubKeys[keyIndex].PubKey()) {
+				signers = append(signers, &types.AccountIdentifier{
+					Address: pubKeys[keyIndex].AddressPubKeyHash().EncodeAddress(),
+				})
+				keyIndex++
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return nil, errors.New("signature does not match any remaining public key")
+		}
+	}
+
+	return signers, nil
+}
+
+// decodeTransaction decodes a hex encoded transaction.
+func decodeTransaction(raw string) (*wire.MsgTx, *types.Error) {
+	serializedTx, err := hex.DecodeString(raw)
 	if err != nil {
 		return nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w signed transaction cannot be decoded", err),
+			fmt.Errorf("%w unable to decode hex transaction", err),
 		)
 	}
 
-	var signed signedTransaction
-	if err := json.Unmarshal(decodedTx, &signed); err != nil {
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
 		return nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w unable to unmarshal signed bitcoin transaction", err),
+			fmt.Errorf("%w unable to decode msgTx", err),
 		)
 	}
 
-	serializedTx, err := hex.DecodeString(signed.Transaction)
+	return &tx, nil
+}
+
+func (s *ConstructionAPIService) parseSignedTransaction(
+	request *types.ConstructionParseRequest,
+) (*types.ConstructionParseResponse, *types.Error) {
+	if isPSBT(request.Transaction) {
+		// A signed PSBT is finalized before it is parsed.
+		unsigned, tx, packet, rErr := s.decodeUnsignedTransaction(request.Transaction)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		signed, rErr := s.signTransaction(unsigned, tx, packet, nil)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		rawTx, err := json.Marshal(signed)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		request = &types.ConstructionParseRequest{
+			NetworkIdentifier: request.NetworkIdentifier,
+			Signed:            true,
+			Transaction:       hex.EncodeToString(rawTx),
+		}
+	}
+
+	decodedTx, err := hex.DecodeString(request.Transaction)
 	if err != nil {
 		return nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w unable to decode hex transaction", err),
+			fmt.Errorf("%w signed transaction cannot be decoded", err),
 		)
 	}
 
-	var tx wire.MsgTx
-	if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+	var signed signedTransaction
+	if err := json.Unmarshal(decodedTx, &signed); err != nil {
 		return nil, wrapErr(
 			ErrUnableToParseIntermediateResult,
-			fmt.Errorf("%w unable to decode msgTx", err),
+			fmt.Errorf("%w unable to unmarshal signed bitcoin transaction", err),
 		)
 	}
 
+	tx, rErr := decodeTransaction(signed.Transaction)
+	if rErr != nil {
+		return nil, rErr
+	}
+
 	ops := []*types.Operation{}
 	signers := []*types.AccountIdentifier{}
+	pkScripts := make([][]byte, len(tx.TxIn))
 	for i, input := range tx.TxIn {
 		pkScript, err := txscript.ComputePkScript(input.SignatureScript, input.Witness)
 		if err != nil {
@@ -669,6 +2622,7 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 				fmt.Errorf("%w: unable to compute pk script", err),
 			)
 		}
+		pkScripts[i] = pkScript.Script()
 
 		_, addr, err := bitcoin.ParseSingleAddress(s.config.Params, pkScript.Script())
 		if err != nil {
@@ -678,10 +2632,28 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 			)
 		}
 
+		if pkScript.Class() == txscript.ScriptHashTy {
+			multisigSigners, err := s.multisigSigners(tx, i)
+			if err != nil {
+				return nil, wrapErr(
+					ErrInvalidRedeemScript,
+					fmt.Errorf("%w unable to parse multisig signers of input %d", err, i),
+				)
+			}
+
+			signers = append(signers, multisigSigners...)
+		} else {
+			signers = append(signers, &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			})
+		}
+
+		metadata, rErr := parsedInputMetadata(input, signatureHashType(input))
+		if rErr != nil {
+			return nil, rErr
+		}
+
 		networkIndex := int64(i)
-		signers = append(signers, &types.AccountIdentifier{
-			Address: addr.EncodeAddress(),
-		})
 		ops = append(ops, &types.Operation{
 			OperationIdentifier: &types.OperationIdentifier{
 				Index:        int64(len(ops)),
@@ -705,38 +2677,33 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 					),
 				},
 			},
+			Metadata: metadata,
 		})
 	}
 
-	for i, output := range tx.TxOut {
-		networkIndex := int64(i)
-		_, addr, err := bitcoin.ParseSingleAddress(s.config.Params, output.PkScript)
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to parse output address", err),
-			)
-		}
+	if rErr := verifyScripts(tx, pkScripts, signed.InputAmounts); rErr != nil {
+		return nil, rErr
+	}
 
-		ops = append(ops, &types.Operation{
-			OperationIdentifier: &types.OperationIdentifier{
-				Index:        int64(len(ops)),
-				NetworkIndex: &networkIndex,
-			},
-			Type: bitcoin.OutputOpType,
-			Account: &types.AccountIdentifier{
-				Address: addr.String(),
-			},
-			Amount: &types.Amount{
-				Value:    strconv.FormatInt(output.Value, 10),
-				Currency: s.config.Currency,
-			},
-		})
+	ops, rErr = s.parseOutputOperations(tx, ops)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	ops, rErr = remapOperations(ops, signed.Operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	metadata, rErr := transactionMetadata(tx)
+	if rErr != nil {
+		return nil, rErr
 	}
 
 	return &types.ConstructionParseResponse{
 		Operations:               ops,
 		AccountIdentifierSigners: signers,
+		Metadata:                 metadata,
 	}, nil
 }
 
@@ -777,9 +2744,42 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 		)
 	}
 
+	tx, rErr := decodeTransaction(signed.Transaction)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	inputs := make([]string, len(tx.TxIn))
+	coins := make([]*types.Coin, len(tx.TxIn))
+	for j, input := range tx.TxIn {
+		inputs[j] = bitcoin.CoinIdentifier(
+			input.PreviousOutPoint.Hash.String(),
+			int64(input.PreviousOutPoint.Index),
+		)
+		coins[j] = &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: inputs[j]},
+		}
+	}
+
+	if rErr := s.policy.Check(ctx, tx); rErr != nil {
+		s.releaseCoins(ctx, signed.ReservationID, coins)
+		return nil, rErr
+	}
+
 	txHash, err := s.client.SendRawTransaction(ctx, signed.Transaction)
 	if err != nil {
-		return nil, wrapErr(ErrBitcoind, fmt.Errorf("%w unable to submit transaction", err))
+		s.releaseCoins(ctx, signed.ReservationID, coins)
+		return nil, rpcErr(fmt.Errorf("%w unable to submit transaction", err))
+	}
+
+	// The transaction is already broadcast, so failing
+	// to track it for rebroadcast does not fail the request.
+	if err := s.i.TrackTransaction(ctx, txHash, signed.Transaction, inputs); err != nil {
+		utils.ExtractLogger(ctx, "construction").Warnw(
+			"unable to track transaction",
+			"hash", txHash,
+			"error", err,
+		)
 	}
 
 	return &types.TransactionIdentifierResponse{
@@ -16,17 +16,32 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
 	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
+	"github.com/btcsuite/btcutil/txsort"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func forceHexDecode(t *testing.T, s string) []byte {
@@ -47,6 +62,36 @@ func forceMarshalMap(t *testing.T, i interface{}) map[string]interface{} {
 	return m
 }
 
+// forceTamperTransaction decodes the transaction of an unsigned
+// or signed transaction blob, applies tamper, and re-encodes it.
+func forceTamperTransaction(t *testing.T, raw string, tamper func(*wire.MsgTx)) string {
+	var blob map[string]interface{}
+	if err := json.Unmarshal(forceHexDecode(t, raw), &blob); err != nil {
+		t.Fatalf("could not unmarshal transaction blob %s", raw)
+	}
+
+	var tx wire.MsgTx
+	serializedTx := forceHexDecode(t, blob["transaction"].(string))
+	if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		t.Fatalf("could not deserialize transaction %s", blob["transaction"])
+	}
+
+	tamper(&tx)
+
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		t.Fatalf("could not serialize transaction")
+	}
+
+	blob["transaction"] = hex.EncodeToString(buf.Bytes())
+	encoded, err := json.Marshal(blob)
+	if err != nil {
+		t.Fatalf("could not marshal transaction blob")
+	}
+
+	return hex.EncodeToString(encoded)
+}
+
 func TestConstructionService(t *testing.T) {
 	networkIdentifier = &types.NetworkIdentifier{
 		Network:    dogecoin.TestnetNetwork,
@@ -69,7 +114,7 @@ func TestConstructionService(t *testing.T) {
 	publicKey := &types.PublicKey{
 		Bytes: forceHexDecode(
 			t,
-			"0325c9a4252789b31dbb3454ec647e9516e7c596bcde2bd5da71a60fab8644e438",
+			"0362ea463b406fe7133eee91c82f927f8815cd4ef0e3ffadb2f1501185ccb8b679",
 		),
 		CurveType: types.Secp256k1,
 	}
@@ -80,7 +125,7 @@ func TestConstructionService(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, &types.ConstructionDeriveResponse{
 		AccountIdentifier: &types.AccountIdentifier{
-			Address: "tdge1qcqzmqzkswhfshzd8kedhmtvgnxax48z4qjht0z",
+			Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
 		},
 	}, deriveResponse)
 
@@ -92,10 +137,10 @@ func TestConstructionService(t *testing.T) {
 			},
 			Type: bitcoin.InputOpType,
 			Account: &types.AccountIdentifier{
-				Address: "tdge1qcqzmqzkswhfshzd8kedhmtvgnxax48z4qjht0z",
+				Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
 			},
 			Amount: &types.Amount{
-				Value:    "-1000000",
+				Value:    "-1000000000",
 				Currency: dogecoin.TestnetCurrency,
 			},
 			CoinChange: &types.CoinChange{
@@ -111,10 +156,10 @@ func TestConstructionService(t *testing.T) {
 			},
 			Type: bitcoin.OutputOpType,
 			Account: &types.AccountIdentifier{
-				Address: "tdge1q3r8xjf0c2yazxnq9ey3wayelygfjxpfqmg6g08",
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
 			},
 			Amount: &types.Amount{
-				Value:    "954843",
+				Value:    "954843000",
 				Currency: dogecoin.TestnetCurrency,
 			},
 		},
@@ -124,10 +169,10 @@ func TestConstructionService(t *testing.T) {
 			},
 			Type: bitcoin.OutputOpType,
 			Account: &types.AccountIdentifier{
-				Address: "tdge1qjsrjvk2ug872pdypp33fjxke62y7awpgsdtxly",
+				Address: "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu",
 			},
 			Amount: &types.Amount{
-				Value:    "44657",
+				Value:    "44657000",
 				Currency: dogecoin.TestnetCurrency,
 			},
 		},
@@ -149,12 +194,12 @@ func TestConstructionService(t *testing.T) {
 					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
 				},
 				Amount: &types.Amount{
-					Value:    "-1000000",
+					Value:    "-1000000000",
 					Currency: dogecoin.TestnetCurrency,
 				},
 			},
 		},
-		EstimatedSize: 142,
+		EstimatedSize: 78,
 		FeeMultiplier: &feeMultiplier,
 	}
 	assert.Equal(t, &types.ConstructionPreprocessResponse{
@@ -165,18 +210,26 @@ func TestConstructionService(t *testing.T) {
 	metadata := &constructionMetadata{
 		ScriptPubKeys: []*bitcoin.ScriptPubKey{
 			{
-				ASM:          "0 c005b00ad075d30b89a7b65b7dad8899ba6a9c55",
-				Hex:          "0014c005b00ad075d30b89a7b65b7dad8899ba6a9c55",
+				ASM:          "OP_DUP OP_HASH160 81bcc7c983fe2fe74bc3d40564ef3e149b334da1 OP_EQUALVERIFY OP_CHECKSIG",
+				Hex:          "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
 				RequiredSigs: 1,
-				Type:         "witness_v0_keyhash",
+				Type:         "pubkeyhash",
 				Addresses: []string{
-					"tdge1qcqzmqzkswhfshzd8kedhmtvgnxax48z4qjht0z",
+					"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
 				},
 			},
 		},
 	}
 
 	// Normal Fee
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		options.Coins,
+	).Return(
+		[]int64{0},
+		nil,
+	).Twice()
 	mockIndexer.On(
 		"GetScriptPubKeys",
 		ctx,
@@ -190,7 +243,7 @@ func TestConstructionService(t *testing.T) {
 		ctx,
 		defaultConfirmationTarget,
 	).Return(
-		bitcoin.MinFeeRate*10,
+		dogecoin.MinRelayFeeRate*10,
 		nil,
 	).Once()
 	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
@@ -202,7 +255,7 @@ func TestConstructionService(t *testing.T) {
 		Metadata: forceMarshalMap(t, metadata),
 		SuggestedFee: []*types.Amount{
 			{
-				Value:    "1065", // 1,420 * 0.75
+				Value:    "1695000", // 226 bytes * 10,000 koinu/byte * 0.75
 				Currency: dogecoin.TestnetCurrency,
 			},
 		},
@@ -222,7 +275,7 @@ func TestConstructionService(t *testing.T) {
 		ctx,
 		defaultConfirmationTarget,
 	).Return(
-		bitcoin.MinFeeRate,
+		dogecoin.MinRelayFeeRate,
 		nil,
 	).Once()
 	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
@@ -234,14 +287,30 @@ func TestConstructionService(t *testing.T) {
 		Metadata: forceMarshalMap(t, metadata),
 		SuggestedFee: []*types.Amount{
 			{
-				Value:    "142", // we don't go below minimum fee rate
+				Value:    "226000", // we don't go below minimum fee rate
 				Currency: dogecoin.TestnetCurrency,
 			},
 		},
 	}, metadataResponse)
 
+	// Immature Coin
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		options.Coins,
+	).Return(
+		[]int64{1240},
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrImmatureCoin.Code, err.Code)
+
 	// Test Payloads
-	unsignedRaw := "7b227472616e73616374696f6e223a2230313030303030303031376639636635306230326464353235386638306364356333343337333032653032376464313333363137326132306364633830333035633561353537343162313031303030303030303066666666666666663032646239313065303030303030303030303136303031343838636536393235663835313361323334633035633932326565393333663232313332333035323037316165303030303030303030303030313630303134393430373236353935633431666361306234383130633632393931616439643238396565623832383030303030303030222c227363726970745075624b657973223a5b7b2261736d223a22302063303035623030616430373564333062383961376236356237646164383839396261366139633535222c22686578223a223030313463303035623030616430373564333062383961376236356237646164383839396261366139633535222c2272657153696773223a312c2274797065223a227769746e6573735f76305f6b657968617368222c22616464726573736573223a5b2274646765317163717a6d717a6b7377686673687a64386b6564686d7476676e78617834387a34716a6874307a225d7d5d2c22696e7075745f616d6f756e7473223a5b222d31303030303030225d2c22696e7075745f616464726573736573223a5b2274646765317163717a6d717a6b7377686673687a64386b6564686d7476676e78617834387a34716a6874307a225d7d" // nolint
+	unsignedRaw := "7b227472616e73616374696f6e223a2230313030303030303031376639636635306230326464353235386638306364356333343337333032653032376464313333363137326132306364633830333035633561353537343162313031303030303030303066666666666666663032373862666539333830303030303030303139373661393134363132363362303831626636326330343634326266306639306666666262393432343866376332363838616336383639613930323030303030303030313937366139313461363065363935666534313062633438373864383139326465383838353366643339376332376133383861633030303030303030222c227363726970745075624b657973223a5b7b2261736d223a224f505f445550204f505f484153483136302038316263633763393833666532666537346263336434303536346566336531343962333334646131204f505f455155414c564552494659204f505f434845434b534947222c22686578223a223736613931343831626363376339383366653266653734626333643430353634656633653134396233333464613138386163222c2272657153696773223a312c2274797065223a227075626b657968617368222c22616464726573736573223a5b226e673239616942463276796245684575736b7647513176524e336752524e72676246225d7d5d2c22696e7075745f616d6f756e7473223a5b222d31303030303030303030225d2c22696e7075745f616464726573736573223a5b226e673239616942463276796245684575736b7647513176524e336752524e72676246225d2c2272656465656d5f73637269707473223a5b22225d7d" // nolint
 	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
 		NetworkIdentifier: networkIdentifier,
 		Operations:        ops,
@@ -257,10 +326,10 @@ func TestConstructionService(t *testing.T) {
 			},
 			Type: bitcoin.InputOpType,
 			Account: &types.AccountIdentifier{
-				Address: "tdge1qcqzmqzkswhfshzd8kedhmtvgnxax48z4qjht0z",
+				Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
 			},
 			Amount: &types.Amount{
-				Value:    "-1000000",
+				Value:    "-1000000000",
 				Currency: dogecoin.TestnetCurrency,
 			},
 			CoinChange: &types.CoinChange{
@@ -277,10 +346,10 @@ func TestConstructionService(t *testing.T) {
 			},
 			Type: bitcoin.OutputOpType,
 			Account: &types.AccountIdentifier{
-				Address: "tdge1q3r8xjf0c2yazxnq9ey3wayelygfjxpfqmg6g08",
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
 			},
 			Amount: &types.Amount{
-				Value:    "954843",
+				Value:    "954843000",
 				Currency: dogecoin.TestnetCurrency,
 			},
 		},
@@ -291,10 +360,10 @@ func TestConstructionService(t *testing.T) {
 			},
 			Type: bitcoin.OutputOpType,
 			Account: &types.AccountIdentifier{
-				Address: "tdge1qjsrjvk2ug872pdypp33fjxke62y7awpgsdtxly",
+				Address: "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu",
 			},
 			Amount: &types.Amount{
-				Value:    "44657",
+				Value:    "44657000",
 				Currency: dogecoin.TestnetCurrency,
 			},
 		},
@@ -304,10 +373,10 @@ func TestConstructionService(t *testing.T) {
 	signingPayload := &types.SigningPayload{
 		Bytes: forceHexDecode(
 			t,
-			"7b98f8b77fa6ef34044f320073118033afdffbd3fd3f8423889d9e5953ff4a30",
+			"6c5166e21ae2e08be430590f2270fa323ee027798cb1291c1c50b5a3ce718349",
 		),
 		AccountIdentifier: &types.AccountIdentifier{
-			Address: "tdge1qcqzmqzkswhfshzd8kedhmtvgnxax48z4qjht0z",
+			Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
 		},
 		SignatureType: types.Ecdsa,
 	}
@@ -329,7 +398,7 @@ func TestConstructionService(t *testing.T) {
 	}, parseUnsignedResponse)
 
 	// Test Combine
-	signedRaw := "7b227472616e73616374696f6e223a22303130303030303030303031303137663963663530623032646435323538663830636435633334333733303265303237646431333336313732613230636463383033303563356135353734316231303130303030303030306666666666666666303264623931306530303030303030303030313630303134383863653639323566383531336132333463303563393232656539333366323231333233303532303731616530303030303030303030303031363030313439343037323635393563343166636130623438313063363239393161643964323839656562383238303234373330343430323230323538373665633862396635316433343361356135366163353439633063383238303035656634356562653964613136366462363435633039313537323233663032323034636430386237323738613838383961383131333539313562636531306431656633626239326232313766383161306465376537396666623364666436616335303132313033323563396134323532373839623331646262333435346563363437653935313665376335393662636465326264356461373161363066616238363434653433383030303030303030222c22696e7075745f616d6f756e7473223a5b222d31303030303030225d7d" // nolint
+	signedRaw := "7b227472616e73616374696f6e223a223031303030303030303137663963663530623032646435323538663830636435633334333733303265303237646431333336313732613230636463383033303563356135353734316231303130303030303036623438333034353032323130303964323832306161386537396662393566643236303464336434653064336533376132613231356639306535353363313831643466386433366639373330343130323230333662383461356139333331306537383464636662366237363264346161356232613162653632353363353430623266343361356131656662663863396238343031323130333632656134363362343036666537313333656565393163383266393237663838313563643465663065336666616462326631353031313835636362386236373966666666666666663032373862666539333830303030303030303139373661393134363132363362303831626636326330343634326266306639306666666262393432343866376332363838616336383639613930323030303030303030313937366139313461363065363935666534313062633438373864383139326465383838353366643339376332376133383861633030303030303030222c22696e7075745f616d6f756e7473223a5b222d31303030303030303030225d7d" // nolint
 	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
 		NetworkIdentifier:   networkIdentifier,
 		UnsignedTransaction: unsignedRaw,
@@ -337,7 +406,7 @@ func TestConstructionService(t *testing.T) {
 			{
 				Bytes: forceHexDecode(
 					t,
-					"25876ec8b9f51d343a5a56ac549c0c828005ef45ebe9da166db645c09157223f4cd08b7278a8889a81135915bce10d1ef3bb92b217f81a0de7e79ffb3dfd6ac5", // nolint
+					"9d2820aa8e79fb95fd2604d3d4e0d3e37a2a215f90e553c181d4f8d36f97304136b84a5a93310e784dcfb6b762d4aa5b2a1be6253c540b2f43a5a1efbf8c9b84", // nolint
 				),
 				SigningPayload: signingPayload,
 				PublicKey:      publicKey,
@@ -350,8 +419,80 @@ func TestConstructionService(t *testing.T) {
 		SignedTransaction: signedRaw,
 	}, combineResponse)
 
-	// Test Parse Signed
+	// Test Combine with a DER signature
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures: []*types.Signature{
+			{
+				Bytes: forceHexDecode(
+					t,
+					"3045022100"+"9d2820aa8e79fb95fd2604d3d4e0d3e37a2a215f90e553c181d4f8d36f973041"+
+						"0220"+"36b84a5a93310e784dcfb6b762d4aa5b2a1be6253c540b2f43a5a1efbf8c9b84",
+				),
+				SigningPayload: signingPayload,
+				PublicKey:      publicKey,
+				SignatureType:  types.Ecdsa,
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Combine with a malformed signature
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures: []*types.Signature{
+			{
+				Bytes:          forceHexDecode(t, "9d2820aa8e79fb95fd2604d3d4e0d3e3"),
+				SigningPayload: signingPayload,
+				PublicKey:      publicKey,
+				SignatureType:  types.Ecdsa,
+			},
+		},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrInvalidSignature.Code, err.Code)
+
+	// Test Combine with a signature for another transaction
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier: networkIdentifier,
+		UnsignedTransaction: forceTamperTransaction(t, unsignedRaw, func(tx *wire.MsgTx) {
+			tx.TxOut[0].Value--
+		}),
+		Signatures: []*types.Signature{
+			{
+				Bytes: forceHexDecode(
+					t,
+					"9d2820aa8e79fb95fd2604d3d4e0d3e37a2a215f90e553c181d4f8d36f97304136b84a5a93310e784dcfb6b762d4aa5b2a1be6253c540b2f43a5a1efbf8c9b84", // nolint
+				),
+				SigningPayload: signingPayload,
+				PublicKey:      publicKey,
+				SignatureType:  types.Ecdsa,
+			},
+		},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrScriptVerificationFailed.Code, err.Code)
+	assert.Equal(t, 0, err.Details["input_index"])
+
+	// Test Parse Signed with a tampered output
 	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction: forceTamperTransaction(t, signedRaw, func(tx *wire.MsgTx) {
+			tx.TxOut[1].Value++
+		}),
+	})
+	assert.Nil(t, parseSignedResponse)
+	assert.Equal(t, ErrScriptVerificationFailed.Code, err.Code)
+	assert.Equal(t, 0, err.Details["input_index"])
+
+	// Test Parse Signed
+	parseSignedResponse, err = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
 		NetworkIdentifier: networkIdentifier,
 		Signed:            true,
 		Transaction:       signedRaw,
@@ -360,13 +501,13 @@ func TestConstructionService(t *testing.T) {
 	assert.Equal(t, &types.ConstructionParseResponse{
 		Operations: parseOps,
 		AccountIdentifierSigners: []*types.AccountIdentifier{
-			{Address: "tdge1qcqzmqzkswhfshzd8kedhmtvgnxax48z4qjht0z"},
+			{Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF"},
 		},
 	}, parseSignedResponse)
 
 	// Test Hash
 	transactionIdentifier := &types.TransactionIdentifier{
-		Hash: "6d87ad0e26025128f5a8357fa423b340cbcffb9703f79f432f5520fca59cd20b",
+		Hash: "e3c281a98475ea6e06a5757ba7726204552b2a782a78c23cd298fbb61fdc04d1",
 	}
 	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
 		NetworkIdentifier: networkIdentifier,
@@ -377,8 +518,66 @@ func TestConstructionService(t *testing.T) {
 		TransactionIdentifier: transactionIdentifier,
 	}, hashResponse)
 
+	// Test Submit with a spent input
+	spentCoin := &types.CoinIdentifier{
+		Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
+	}
+	mockIndexer.On(
+		"GetMempoolSpender",
+		ctx,
+		spentCoin,
+	).Return(
+		"c14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f",
+		nil,
+	).Once()
+
+	// The coins of a transaction that cannot be submitted
+	// are released from the reservation it was built with.
+	var reserved signedTransaction
+	assert.NoError(t, json.Unmarshal(forceHexDecode(t, signedRaw), &reserved))
+	reserved.ReservationID = "reservation"
+	reservedRaw, jsonErr := json.Marshal(reserved)
+	assert.NoError(t, jsonErr)
+	mockIndexer.On(
+		"ReleaseCoins",
+		ctx,
+		"reservation",
+		[]*types.Coin{{CoinIdentifier: spentCoin}},
+	).Return(
+		nil,
+	).Once()
+	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: hex.EncodeToString(reservedRaw),
+	})
+	assert.Nil(t, submitResponse)
+	assert.Equal(t, ErrInputNotFound.Code, err.Code)
+
 	// Test Submit
-	bitcoinTransaction := "010000000001017f9cf50b02dd5258f80cd5c3437302e027dd1336172a20cdc80305c5a55741b10100000000ffffffff02db910e000000000016001488ce6925f8513a234c05c922ee933f221323052071ae000000000000160014940726595c41fca0b4810c62991ad9d289eeb82802473044022025876ec8b9f51d343a5a56ac549c0c828005ef45ebe9da166db645c09157223f02204cd08b7278a8889a81135915bce10d1ef3bb92b217f81a0de7e79ffb3dfd6ac501210325c9a4252789b31dbb3454ec647e9516e7c596bcde2bd5da71a60fab8644e43800000000" // nolint
+	mockIndexer.On(
+		"GetMempoolSpender",
+		ctx,
+		spentCoin,
+	).Return(
+		"",
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetCoin",
+		ctx,
+		spentCoin,
+	).Return(
+		&types.Coin{
+			CoinIdentifier: spentCoin,
+			Amount: &types.Amount{
+				Value:    "1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+		&types.AccountIdentifier{Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF"},
+		nil,
+	).Once()
+	bitcoinTransaction := "01000000017f9cf50b02dd5258f80cd5c3437302e027dd1336172a20cdc80305c5a55741b1010000006b4830450221009d2820aa8e79fb95fd2604d3d4e0d3e37a2a215f90e553c181d4f8d36f973041022036b84a5a93310e784dcfb6b762d4aa5b2a1be6253c540b2f43a5a1efbf8c9b8401210362ea463b406fe7133eee91c82f927f8815cd4ef0e3ffadb2f1501185ccb8b679ffffffff0278bfe938000000001976a91461263b081bf62c04642bf0f90fffbb94248f7c2688ac6869a902000000001976a914a60e695fe410bc4878d8192de88853fd397c27a388ac00000000" // nolint
 	mockClient.On(
 		"SendRawTransaction",
 		ctx,
@@ -387,7 +586,16 @@ func TestConstructionService(t *testing.T) {
 		transactionIdentifier.Hash,
 		nil,
 	)
-	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+	mockIndexer.On(
+		"TrackTransaction",
+		ctx,
+		transactionIdentifier.Hash,
+		bitcoinTransaction,
+		[]string{spentCoin.Identifier},
+	).Return(
+		nil,
+	).Once()
+	submitResponse, err = servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
 		NetworkIdentifier: networkIdentifier,
 		SignedTransaction: signedRaw,
 	})
@@ -399,3 +607,2213 @@ func TestConstructionService(t *testing.T) {
 	mockClient.AssertExpectations(t)
 	mockIndexer.AssertExpectations(t)
 }
+
+func TestConstructionServiceMultisig(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	// Test Derive 2-of-3
+	publicKeys := []*types.PublicKey{
+		{
+			Bytes: forceHexDecode(
+				t,
+				"03c330b3882ca6b0017c36358015a932e204b45bcdf84e2a852df2871723e7ef54",
+			),
+			CurveType: types.Secp256k1,
+		},
+		{
+			Bytes: forceHexDecode(
+				t,
+				"0337b50820ff8164bd44bcabd665c90320fea650efb000871f8037854dda58adeb",
+			),
+			CurveType: types.Secp256k1,
+		},
+	}
+	redeemScript := "522103c330b3882ca6b0017c36358015a932e204b45bcdf84e2a852df2871723e7ef54210337b50820ff8164bd44bcabd665c90320fea650efb000871f8037854dda58adeb2102aca18054fde5ee686bdfd2b48f730e24b61d0d638cdcc6284b7f4d98d9bed27153ae" // nolint
+	deriveResponse, err := servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKeys[0],
+		Metadata: forceMarshalMap(t, &deriveMetadata{
+			PublicKeys: []string{
+				"03c330b3882ca6b0017c36358015a932e204b45bcdf84e2a852df2871723e7ef54",
+				"0337b50820ff8164bd44bcabd665c90320fea650efb000871f8037854dda58adeb",
+				"02aca18054fde5ee686bdfd2b48f730e24b61d0d638cdcc6284b7f4d98d9bed271",
+			},
+			Threshold: 2,
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: "2MygCSLrZN8zF29ikUfLCFWZbUbZP2fNMWF",
+		},
+		Metadata: forceMarshalMap(t, &deriveResponseMetadata{
+			RedeemScript: redeemScript,
+		}),
+	}, deriveResponse)
+
+	// Test Derive with a threshold larger than the number of keys
+	_, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKeys[0],
+		Metadata: forceMarshalMap(t, &deriveMetadata{
+			PublicKeys: []string{
+				"03c330b3882ca6b0017c36358015a932e204b45bcdf84e2a852df2871723e7ef54",
+			},
+			Threshold: 2,
+		}),
+	})
+	assert.Equal(t, ErrUnableToDerive.Code, err.Code)
+
+	// Test Payloads
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "2MygCSLrZN8zF29ikUfLCFWZbUbZP2fNMWF",
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:0",
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: forceMarshalMap(t, &inputMetadata{
+				RedeemScript: redeemScript,
+			}),
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+			},
+			Amount: &types.Amount{
+				Value:    "999000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*bitcoin.ScriptPubKey{
+			{
+				ASM:          "OP_HASH160 4689dbfa1ba38300e7ae3c605fc1e9a29398b0d3 OP_EQUAL",
+				Hex:          "a9144689dbfa1ba38300e7ae3c605fc1e9a29398b0d387",
+				RequiredSigs: 1,
+				Type:         "scripthash",
+				Addresses: []string{
+					"2MygCSLrZN8zF29ikUfLCFWZbUbZP2fNMWF",
+				},
+			},
+		},
+	}
+	unsignedRaw := "7b227472616e73616374696f6e223a223031303030303030303137663963663530623032646435323538663830636435633334333733303265303237646431333336313732613230636463383033303563356135353734316231303030303030303030306666666666666666303163303837386233623030303030303030313937366139313438316263633763393833666532666537346263336434303536346566336531343962333334646131383861633030303030303030222c227363726970745075624b657973223a5b7b2261736d223a224f505f484153483136302034363839646266613162613338333030653761653363363035666331653961323933393862306433204f505f455155414c222c22686578223a2261393134343638396462666131626133383330306537616533633630356663316539613239333938623064333837222c2272657153696773223a312c2274797065223a2273637269707468617368222c22616464726573736573223a5b22324d796743534c725a4e387a463239696b55664c4346575a6255625a5032664e4d5746225d7d5d2c22696e7075745f616d6f756e7473223a5b222d31303030303030303030225d2c22696e7075745f616464726573736573223a5b22324d796743534c725a4e387a463239696b55664c4346575a6255625a5032664e4d5746225d2c2272656465656d5f73637269707473223a5b22353232313033633333306233383832636136623030313763333633353830313561393332653230346234356263646638346532613835326466323837313732336537656635343231303333376235303832306666383136346264343462636162643636356339303332306665613635306566623030303837316638303337383534646461353861646562323130326163613138303534666465356565363836626466643262343866373330653234623631643064363338636463633632383462376634643938643962656432373135336165225d7d" // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	signingPayloads := []*types.SigningPayload{
+		{
+			Bytes: forceHexDecode(
+				t,
+				"311b89ad44e4b98996e068a873686d7609452433173d2450ae0957c2d0a607fa",
+			),
+			AccountIdentifier: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			SignatureType: types.Ecdsa,
+		},
+		{
+			Bytes: forceHexDecode(
+				t,
+				"311b89ad44e4b98996e068a873686d7609452433173d2450ae0957c2d0a607fa",
+			),
+			AccountIdentifier: &types.AccountIdentifier{
+				Address: "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu",
+			},
+			SignatureType: types.Ecdsa,
+		},
+	}
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            signingPayloads,
+	}, payloadsResponse)
+
+	// Test Combine with signatures out of redeem script order
+	signedRaw := "7b227472616e73616374696f6e223a2230313030303030303031376639636635306230326464353235386638306364356333343337333032653032376464313333363137326132306364633830333035633561353537343162313030303030303030666466653030303034383330343530323231303065363561393030656235386630646630303632643339306162373165633765396533626264353664386632356230306661393132623465363530306137356262303232303333396366306166663132663130356363373162643363376463363736393832636535636534396436623137363265383537613364373837323432646234316130313438333034353032323130306633646265353632353965653661663636323739313063353736373963626561396464656165666632373631656331396534666638383966653161383431636630323230353032323461346131323836366137333130323064303533666365643336343939663933383737643938343831646538373333633636653664666565303433313031346336393532323130336333333062333838326361366230303137633336333538303135613933326532303462343562636466383465326138353264663238373137323365376566353432313033333762353038323066663831363462643434626361626436363563393033323066656136353065666230303038373166383033373835346464613538616465623231303261636131383035346664653565653638366264666432623438663733306532346236316430643633386364636336323834623766346439386439626564323731353361656666666666666666303163303837386233623030303030303030313937366139313438316263633763393833666532666537346263336434303536346566336531343962333334646131383861633030303030303030222c22696e7075745f616d6f756e7473223a5b222d31303030303030303030225d7d" // nolint
+	signatures := []*types.Signature{
+		{
+			Bytes: forceHexDecode(
+				t,
+				"f3dbe56259ee6af6627910c57679cbea9ddeaeff2761ec19e4ff889fe1a841cf50224a4a12866a731020d053fced36499f93877d98481de8733c66e6dfee0431", // nolint
+			),
+			SigningPayload: signingPayloads[1],
+			PublicKey:      publicKeys[1],
+			SignatureType:  types.Ecdsa,
+		},
+		{
+			Bytes: forceHexDecode(
+				t,
+				"e65a900eb58f0df0062d390ab71ec7e9e3bbd56d8f25b00fa912b4e6500a75bb339cf0aff12f105cc71bd3c7dc676982ce5ce49d6b1762e857a3d787242db41a", // nolint
+			),
+			SigningPayload: signingPayloads[0],
+			PublicKey:      publicKeys[0],
+			SignatureType:  types.Ecdsa,
+		},
+	}
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Combine with too few signatures
+	_, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures[:1],
+	})
+	assert.Equal(t, ErrUnableToBuildSignatureScript.Code, err.Code)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.AccountIdentifier{
+		{Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM"},
+		{Address: "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu"},
+	}, parseSignedResponse.AccountIdentifierSigners)
+
+	// Test Hash
+	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "ea17b24b4e229db4fc157e0d878cd6560d105b159cdcef12247c19a4676bcf1a",
+		},
+	}, hashResponse)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionServiceExtendedKey(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.MainnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Offline,
+		Network:  networkIdentifier,
+		Params:   dogecoin.MainnetParams,
+		Currency: dogecoin.MainnetCurrency,
+	}
+
+	servicer := NewConstructionAPIService(cfg, nil, nil)
+	ctx := context.Background()
+
+	extendedKey := "dgub8rRgxK5Zh4vYtZ1Yvqn6KwaL41mvAKWCv63Kihbtu6NjyGdkFdumsFosc97Wvon148BUCfospeL3RWHBpJfyPBt2P2KU97o2PVvh5wNNuCf" // nolint
+	accountKey := &types.PublicKey{
+		Bytes:     forceHexDecode(t, "026b5f8ea7fa348824a3b29b5cda79cd9585bfa04c4a1a63e498f24c855f3b07ad"),
+		CurveType: types.Secp256k1,
+	}
+
+	// Test Derive receiving and change addresses
+	tests := map[string]struct {
+		path      string
+		address   string
+		publicKey string
+	}{
+		"receiving address": {
+			path:      "m/44'/3'/0'/0/1",
+			address:   "D7hTxD1d8J2XTNfa9NvDZk6xzq5UsKGVjb",
+			publicKey: "031aa71653487585025391c484d4bdc30f4d08abc84afaf8a56fa697fc4a641621",
+		},
+		"change address": {
+			path:      "m/44h/3h/0h/1/2",
+			address:   "D7mcAh5jzpbCgw6ojqAr2cteP167JCsWku",
+			publicKey: "03852f1bce83af1c4cc24fbad86ccb88dfd63787ad9e64bdfb713c62cbbdd940b2",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			deriveResponse, err := servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+				NetworkIdentifier: networkIdentifier,
+				PublicKey:         accountKey,
+				Metadata: forceMarshalMap(t, &deriveMetadata{
+					ExtendedKey: extendedKey,
+					Path:        test.path,
+				}),
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, &types.ConstructionDeriveResponse{
+				AccountIdentifier: &types.AccountIdentifier{
+					Address: test.address,
+				},
+				Metadata: forceMarshalMap(t, &deriveExtendedKeyResponseMetadata{
+					PublicKey: test.publicKey,
+					Path:      test.path,
+				}),
+			}, deriveResponse)
+		})
+	}
+
+	// Test invalid derivations
+	invalid := map[string]struct {
+		extendedKey string
+		publicKey   *types.PublicKey
+		path        string
+	}{
+		"wrong coin type": {
+			extendedKey: extendedKey,
+			publicKey:   accountKey,
+			path:        "m/44'/1'/0'/0/1",
+		},
+		"wrong account": {
+			extendedKey: extendedKey,
+			publicKey:   accountKey,
+			path:        "m/44'/3'/1'/0/1",
+		},
+		"hardened address index": {
+			extendedKey: extendedKey,
+			publicKey:   accountKey,
+			path:        "m/44'/3'/0'/0/1'",
+		},
+		"invalid change level": {
+			extendedKey: extendedKey,
+			publicKey:   accountKey,
+			path:        "m/44'/3'/0'/2/1",
+		},
+		"invalid path": {
+			extendedKey: extendedKey,
+			publicKey:   accountKey,
+			path:        "44'/3'/0'/0/1",
+		},
+		"not an account key": {
+			extendedKey: "dgub8nnbYqHETn63Ws4veNeVQc87r7YWbiHLQLMXsTgKx7GZ3pNWM86kAKEGxHMrnqzYkYiGa1BJKusKv5W2U7tJTZyz8gxH77WurgZXJEofx9t", // nolint
+			publicKey:   accountKey,
+			path:        "m/44'/3'/0'/0/1",
+		},
+		"testnet key": {
+			extendedKey: "tpubDDW4jVEAkwNoHumzePCtQ5FcxXVc8RG8ACszXP1HD1WThkZ19sAoyaNeiXswjTtAKM14zjo8rdhxadti7zuNSfJBMuG68oxQ3Bi1wgo88fD", // nolint
+			publicKey:   accountKey,
+			path:        "m/44'/3'/0'/0/1",
+		},
+		"private key": {
+			extendedKey: "dgpv57Yfcd9g8vGDbhMPtcA8D62jbTQHLCUVdVG7ZbCv6yKjRDX3qsN1DxQE2vYXGQasyKSR4cNwUK8ZT4BjGhig8xgrz2DTqGyFaEbuPHJ2j2V", // nolint
+			publicKey:   accountKey,
+			path:        "m/44'/3'/0'/0/1",
+		},
+		"other public key": {
+			extendedKey: extendedKey,
+			publicKey: &types.PublicKey{
+				Bytes:     forceHexDecode(t, "031aa71653487585025391c484d4bdc30f4d08abc84afaf8a56fa697fc4a641621"),
+				CurveType: types.Secp256k1,
+			},
+			path: "m/44'/3'/0'/0/1",
+		},
+	}
+
+	for name, test := range invalid {
+		t.Run(name, func(t *testing.T) {
+			deriveResponse, err := servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+				NetworkIdentifier: networkIdentifier,
+				PublicKey:         test.publicKey,
+				Metadata: forceMarshalMap(t, &deriveMetadata{
+					ExtendedKey: test.extendedKey,
+					Path:        test.path,
+				}),
+			})
+			assert.Nil(t, deriveResponse)
+			assert.Equal(t, ErrUnableToDerive.Code, err.Code)
+		})
+	}
+}
+
+func TestConstructionServiceOffline(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	onlineCfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+	offlineCfg := &configuration.Configuration{
+		Mode:     configuration.Offline,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	online := NewConstructionAPIService(onlineCfg, mockClient, mockIndexer)
+	offline := NewConstructionAPIService(offlineCfg, nil, nil)
+	ctx := context.Background()
+
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "954843000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}
+	feeMultiplier := float64(0.75)
+	feeRate := dogecoin.MinRelayFeeRate * 10
+	scriptPubKeys := []*bitcoin.ScriptPubKey{
+		{
+			ASM:          "OP_DUP OP_HASH160 81bcc7c983fe2fe74bc3d40564ef3e149b334da1 OP_EQUALVERIFY OP_CHECKSIG",
+			Hex:          "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+			RequiredSigs: 1,
+			Type:         "pubkeyhash",
+			Addresses: []string{
+				"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+			},
+		},
+	}
+
+	// Run the flow online
+	preprocessResponse, err := online.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier:      networkIdentifier,
+		Operations:             ops,
+		SuggestedFeeMultiplier: &feeMultiplier,
+	})
+	assert.Nil(t, err)
+	var onlineOptions preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &onlineOptions))
+	mockIndexer.On("GetCoinMaturities", ctx, onlineOptions.Coins).Return([]int64{0}, nil).Once()
+	mockIndexer.On("GetScriptPubKeys", ctx, onlineOptions.Coins).Return(scriptPubKeys, nil).Once()
+	mockClient.On("SuggestedFeeRate", ctx, defaultConfirmationTarget).Return(feeRate, nil).Once()
+	onlineMetadata, err := online.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, err)
+	onlinePayloads, err := online.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          onlineMetadata.Metadata,
+	})
+	assert.Nil(t, err)
+
+	// Run the flow offline with the scriptPubKeys and fee rate
+	preprocessResponse, err = offline.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier:      networkIdentifier,
+		Operations:             ops,
+		SuggestedFeeMultiplier: &feeMultiplier,
+		Metadata: forceMarshalMap(t, &preprocessMetadata{
+			FeeRate: &feeRate,
+			ScriptPubKeys: []*bitcoin.ScriptPubKey{
+				{Hex: "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac"},
+			},
+		}),
+	})
+	assert.Nil(t, err)
+	offlineOptions := preprocessResponse.Options
+	offlineMetadata, err := offline.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           offlineOptions,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, onlineMetadata, offlineMetadata)
+	assert.Equal(t, "1440000", offlineMetadata.SuggestedFee[0].Value)
+
+	offlinePayloads, err := offline.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          offlineMetadata.Metadata,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, onlinePayloads, offlinePayloads)
+
+	// Test missing fee rate
+	options := forceMarshalMap(t, &preprocessOptions{
+		Coins:          onlineOptions.Coins,
+		EstimatedSize:  onlineOptions.EstimatedSize,
+		ScriptPubKeys:  scriptPubKeys,
+		InputAddresses: []string{"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF"},
+	})
+	metadataResponse, err := offline.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           options,
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	// Test invalid scriptPubKeys
+	invalid := map[string]*preprocessOptions{
+		"other address": {
+			Coins:          onlineOptions.Coins,
+			FeeRate:        &feeRate,
+			ScriptPubKeys:  scriptPubKeys,
+			InputAddresses: []string{"nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM"},
+		},
+		"missing scriptPubKey": {
+			Coins:          append(onlineOptions.Coins, onlineOptions.Coins[0]),
+			FeeRate:        &feeRate,
+			ScriptPubKeys:  scriptPubKeys,
+			InputAddresses: []string{"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF"},
+		},
+		"invalid hex": {
+			Coins:          onlineOptions.Coins,
+			FeeRate:        &feeRate,
+			ScriptPubKeys:  []*bitcoin.ScriptPubKey{{Hex: "zz"}},
+			InputAddresses: []string{"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF"},
+		},
+	}
+
+	for name, options := range invalid {
+		t.Run(name, func(t *testing.T) {
+			metadataResponse, err := offline.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+				NetworkIdentifier: networkIdentifier,
+				Options:           forceMarshalMap(t, options),
+			})
+			assert.Nil(t, metadataResponse)
+			assert.Equal(t, ErrInvalidScriptPubKeys.Code, err.Code)
+		})
+	}
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionServiceCoinSelection(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+
+		CoinReservationTTL: 10 * time.Minute,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	sender := &types.AccountIdentifier{
+		Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+	}
+	p2pkh := &bitcoin.ScriptPubKey{
+		ASM:          "OP_DUP OP_HASH160 81bcc7c983fe2fe74bc3d40564ef3e149b334da1 OP_EQUALVERIFY OP_CHECKSIG",
+		Hex:          "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+		RequiredSigs: 1,
+		Type:         "pubkeyhash",
+		Addresses: []string{
+			"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+		},
+	}
+	coin := func(identifier string, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: identifier},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: dogecoin.TestnetCurrency,
+			},
+		}
+	}
+	coins := []*types.Coin{
+		coin("b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:0", "200000000"),
+		coin("b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1", "500000000"),
+		coin("f7a4a4bc4bee3d4b4b1d5d0d0fa4f1c5d47f5ee3a4e2e8e3c1c2f8e6d8d4c3b2:0", "300000000"),
+	}
+	spends := []*types.Coin{
+		coin("b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:0", "-200000000"),
+		coin("b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1", "-500000000"),
+		coin("f7a4a4bc4bee3d4b4b1d5d0d0fa4f1c5d47f5ee3a4e2e8e3c1c2f8e6d8d4c3b2:0", "-300000000"),
+	}
+	selected := []*types.Coin{spends[1], spends[2]}
+
+	// Test Preprocess
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "600000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		defaultConfirmationTarget,
+	).Return(
+		dogecoin.MinRelayFeeRate,
+		nil,
+	).Twice()
+	mockIndexer.On("GetCoins", ctx, sender).Return(coins, nil, nil).Once()
+	mockIndexer.On(
+		"GetCoinReservations",
+		ctx,
+		coins,
+	).Return(
+		[]bool{true, false, false},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		coins[1:],
+	).Return(
+		[]int64{0, 0},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		spends[1:],
+	).Return(
+		[]*bitcoin.ScriptPubKey{p2pkh, p2pkh},
+		nil,
+	).Once()
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				Sender: sender,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins:         selected,
+		EstimatedSize: 44,
+		Sender:        sender,
+		ChangeAddress: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+		OutputAmount:  "600000000",
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		selected,
+	).Return(
+		[]int64{0, 0},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		selected,
+	).Return(
+		[]*bitcoin.ScriptPubKey{p2pkh, p2pkh},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"ReserveCoins",
+		ctx,
+		mock.Anything,
+		selected,
+		cfg.CoinReservationTTL,
+	).Return(
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	reservationID, ok := metadataResponse.Metadata["reservation_id"].(string)
+	assert.True(t, ok)
+	assert.Len(t, reservationID, 2*reservationIDSize)
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*bitcoin.ScriptPubKey{p2pkh, p2pkh},
+		Sender:        sender,
+		Coins:         selected,
+		ChangeAddress: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+		ChangeAmount:  "199626000",
+		ReservationID: reservationID,
+	}
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "374000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	mockIndexer.On(
+		"ReserveCoins",
+		ctx,
+		reservationID,
+		selected,
+		cfg.CoinReservationTTL,
+	).Return(
+		nil,
+	).Once()
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 2)
+
+	// The reservation is carried to ConstructionSubmit,
+	// which releases it if the transaction is rejected.
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(forceHexDecode(t, payloadsResponse.UnsignedTransaction), &unsigned))
+	assert.Equal(t, reservationID, unsigned.ReservationID)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	parsedOps := parseUnsignedResponse.Operations
+	assert.Len(t, parsedOps, 4)
+	assert.Equal(t, bitcoin.InputOpType, parsedOps[0].Type)
+	assert.Equal(t, selected[0].CoinIdentifier, parsedOps[0].CoinChange.CoinIdentifier)
+	assert.Equal(t, bitcoin.InputOpType, parsedOps[1].Type)
+	assert.Equal(t, selected[1].CoinIdentifier, parsedOps[1].CoinChange.CoinIdentifier)
+	assert.Equal(t, bitcoin.OutputOpType, parsedOps[2].Type)
+	assert.Equal(t, "600000000", parsedOps[2].Amount.Value)
+	assert.Equal(t, bitcoin.OutputOpType, parsedOps[3].Type)
+	assert.Equal(t, sender, parsedOps[3].Account)
+	assert.Equal(t, "199626000", parsedOps[3].Amount.Value)
+
+	// Test Insufficient Funds
+	mockIndexer.On("GetCoins", ctx, sender).Return(coins[:1], nil, nil).Once()
+	mockIndexer.On(
+		"GetCoinReservations",
+		ctx,
+		coins[:1],
+	).Return(
+		[]bool{false},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		coins[:1],
+	).Return(
+		[]int64{0},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		spends[:1],
+	).Return(
+		[]*bitcoin.ScriptPubKey{p2pkh},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		defaultConfirmationTarget,
+	).Return(
+		dogecoin.MinRelayFeeRate,
+		nil,
+	).Once()
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				Sender: sender,
+			}),
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInsufficientFunds.Code, err.Code)
+
+	// Test Immature Coins
+	mockIndexer.On("GetCoins", ctx, sender).Return(coins, nil, nil).Once()
+	mockIndexer.On(
+		"GetCoinReservations",
+		ctx,
+		coins,
+	).Return(
+		[]bool{false, false, false},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		coins,
+	).Return(
+		[]int64{1240, 0, 0},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		spends[1:],
+	).Return(
+		[]*bitcoin.ScriptPubKey{p2pkh, p2pkh},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		defaultConfirmationTarget,
+	).Return(
+		dogecoin.MinRelayFeeRate,
+		nil,
+	).Once()
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				Sender: sender,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Reserved Coins
+	mockIndexer.On(
+		"ReserveCoins",
+		ctx,
+		reservationID,
+		selected,
+		cfg.CoinReservationTTL,
+	).Return(
+		errors.New("coin is reserved"),
+	).Once()
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnableToReserveCoins.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionServiceData(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	// Test Preprocess
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "999000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: bitcoin.DataOpType,
+			Metadata: map[string]interface{}{
+				"text": "hello",
+			},
+		},
+	}
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins: []*types.Coin{
+			{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
+				},
+				Amount: &types.Amount{
+					Value:    "-1000000000",
+					Currency: dogecoin.TestnetCurrency,
+				},
+			},
+		},
+		EstimatedSize: 60,
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Payloads
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*bitcoin.ScriptPubKey{
+			{
+				ASM:          "OP_DUP OP_HASH160 81bcc7c983fe2fe74bc3d40564ef3e149b334da1 OP_EQUALVERIFY OP_CHECKSIG",
+				Hex:          "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+				RequiredSigs: 1,
+				Type:         "pubkeyhash",
+				Addresses: []string{
+					"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+				},
+			},
+		},
+	}
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	parsedOps := parseUnsignedResponse.Operations
+	assert.Len(t, parsedOps, 3)
+	assert.Equal(t, bitcoin.DataOpType, parsedOps[2].Type)
+	assert.Nil(t, parsedOps[2].Account)
+	assert.Nil(t, parsedOps[2].Amount)
+	assert.Equal(t, map[string]interface{}{
+		"data": hex.EncodeToString([]byte("hello")),
+	}, parsedOps[2].Metadata)
+
+	// Test Invalid Data
+	tests := map[string][]map[string]interface{}{
+		"data and text": {
+			{"data": "68656c6c6f", "text": "hello"},
+		},
+		"invalid hex": {
+			{"data": "hello"},
+		},
+		"missing payload": {
+			{},
+		},
+		"too large": {
+			{"data": strings.Repeat("00", dogecoin.MaxDataCarrierSize+1)},
+		},
+		"multiple outputs": {
+			{"text": "hello"},
+			{"text": "world"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dataOps := []*types.Operation{ops[0], ops[1]}
+			for _, metadata := range test {
+				dataOps = append(dataOps, &types.Operation{
+					OperationIdentifier: &types.OperationIdentifier{
+						Index: int64(len(dataOps)),
+					},
+					Type:     bitcoin.DataOpType,
+					Metadata: metadata,
+				})
+			}
+
+			preprocessResponse, err := servicer.ConstructionPreprocess(
+				ctx,
+				&types.ConstructionPreprocessRequest{
+					NetworkIdentifier: networkIdentifier,
+					Operations:        dataOps,
+				},
+			)
+			assert.Nil(t, preprocessResponse)
+			assert.Equal(t, ErrInvalidData.Code, err.Code)
+		})
+	}
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionServiceLockTime(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	// Test Preprocess
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "999000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}
+	lockTime := uint32(4000000)
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				LockTime: lockTime,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins: []*types.Coin{
+			{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f:1",
+				},
+				Amount: &types.Amount{
+					Value:    "-1000000000",
+					Currency: dogecoin.TestnetCurrency,
+				},
+			},
+		},
+		EstimatedSize: 44,
+		LockTime:      lockTime,
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*bitcoin.ScriptPubKey{
+			{
+				ASM:          "OP_DUP OP_HASH160 81bcc7c983fe2fe74bc3d40564ef3e149b334da1 OP_EQUALVERIFY OP_CHECKSIG",
+				Hex:          "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+				RequiredSigs: 1,
+				Type:         "pubkeyhash",
+				Addresses: []string{
+					"ng29aiBF2vybEhEuskvGQ1vRN3gRRNrgbF",
+				},
+			},
+		},
+		LockTime: lockTime,
+	}
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		options.Coins,
+	).Return(
+		[]int64{0},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		options.Coins,
+	).Return(
+		metadata.ScriptPubKeys,
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		defaultConfirmationTarget,
+	).Return(
+		dogecoin.MinRelayFeeRate,
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, forceMarshalMap(t, metadata), metadataResponse.Metadata)
+
+	// Test Payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, err)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	defaultSequence := uint32(wire.MaxTxInSequenceNum - 1)
+	assert.Equal(t, forceMarshalMap(t, &parseMetadata{
+		LockTime: lockTime,
+	}), parseUnsignedResponse.Metadata)
+	assert.Equal(t, forceMarshalMap(t, &inputMetadata{
+		Sequence: &defaultSequence,
+	}), parseUnsignedResponse.Operations[0].Metadata)
+	assert.Nil(t, parseUnsignedResponse.Operations[1].Metadata)
+
+	// Test Recoverable Signatures
+	recoveryMetadata := forceMarshalMap(t, &constructionMetadata{
+		ScriptPubKeys: metadata.ScriptPubKeys,
+		LockTime:      lockTime,
+		SignatureType: types.EcdsaRecovery,
+	})
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          recoveryMetadata,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, types.EcdsaRecovery, payloadsResponse.Payloads[0].SignatureType)
+
+	// Test Explicit Sequence
+	sequence := uint32(7)
+	sequenceOps := []*types.Operation{ops[0], ops[1]}
+	sequenceOps[0] = &types.Operation{
+		OperationIdentifier: ops[0].OperationIdentifier,
+		Type:                ops[0].Type,
+		Account:             ops[0].Account,
+		Amount:              ops[0].Amount,
+		CoinChange:          ops[0].CoinChange,
+		Metadata: forceMarshalMap(t, &inputMetadata{
+			Sequence: &sequence,
+		}),
+	}
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        sequenceOps,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, err)
+	parseUnsignedResponse, err = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, sequenceOps[0].Metadata, parseUnsignedResponse.Operations[0].Metadata)
+
+	// Test Final Inputs
+	sequence = wire.MaxTxInSequenceNum
+	sequenceOps[0].Metadata = forceMarshalMap(t, &inputMetadata{
+		Sequence: &sequence,
+	})
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        sequenceOps,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrInvalidLockTime.Code, err.Code)
+
+	// Test Unsupported Signature Type
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				SignatureType: types.Ed25519,
+			}),
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	// Lock times by height are accepted
+	// regardless of BIP65
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				LockTime: 1,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	assert.NotNil(t, preprocessResponse)
+
+	// Lock times by timestamp are accepted
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				LockTime: txscript.LockTimeThreshold,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	assert.NotNil(t, preprocessResponse)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionServicePSBT(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	seed := sha256.Sum256([]byte("signer"))
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), seed[:])
+	addr, err2 := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		dogecoin.TestnetParams,
+	)
+	assert.NoError(t, err2)
+	script, err2 := txscript.PayToAddrScript(addr)
+	assert.NoError(t, err2)
+	publicKey := &types.PublicKey{
+		Bytes:     pubKey.SerializeCompressed(),
+		CurveType: types.Secp256k1,
+	}
+
+	// The transaction creating the coin spent, which every
+	// input of a PSBT carries as its non-witness UTXO.
+	previousTx := wire.NewMsgTx(1)
+	previousTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	previousTx.AddTxOut(wire.NewTxOut(500000000, script))
+	previousTx.AddTxOut(wire.NewTxOut(1000000000, script))
+	previousBuf := bytes.NewBuffer(make([]byte, 0, previousTx.SerializeSize()))
+	assert.NoError(t, previousTx.Serialize(previousBuf))
+	previousRaw := hex.EncodeToString(previousBuf.Bytes())
+
+	coinIdentifier := &types.CoinIdentifier{
+		Identifier: fmt.Sprintf("%s:1", previousTx.TxHash().String()),
+	}
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "954843000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu",
+			},
+			Amount: &types.Amount{
+				Value:    "44657000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}
+	scriptPubKey := &bitcoin.ScriptPubKey{
+		Hex:       hex.EncodeToString(script),
+		Type:      "pubkeyhash",
+		Addresses: []string{addr.EncodeAddress()},
+	}
+	feeRate := dogecoin.MinRelayFeeRate
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				PSBT:          true,
+				FeeRate:       &feeRate,
+				ScriptPubKeys: []*bitcoin.ScriptPubKey{scriptPubKey},
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, true, preprocessResponse.Options["psbt"])
+
+	var options preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &options))
+
+	// Test Metadata Offline
+	offlineCfg := *cfg
+	offlineCfg.Mode = configuration.Offline
+	offlineServicer := NewConstructionAPIService(&offlineCfg, mockClient, mockIndexer)
+	metadataResponse, err := offlineServicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	// Test Metadata
+	mockIndexer.On(
+		"GetCoinMaturities",
+		ctx,
+		options.Coins,
+	).Return(
+		[]int64{0},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetRawTransactions",
+		ctx,
+		[]string{previousTx.TxHash().String()},
+	).Return(
+		[]string{previousRaw},
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, err)
+	var metadata constructionMetadata
+	assert.NoError(t, types.UnmarshalMap(metadataResponse.Metadata, &metadata))
+	assert.Equal(t, []string{previousRaw}, metadata.PreviousTransactions)
+
+	// Test Payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+	signingPayload := payloadsResponse.Payloads[0]
+	assert.Equal(t, addr.EncodeAddress(), signingPayload.AccountIdentifier.Address)
+
+	// Legacy inputs carry the full previous transaction.
+	unsignedPSBT := payloadsResponse.UnsignedTransaction
+	packet, err2 := psbt.NewFromRawBytes(strings.NewReader(unsignedPSBT), true)
+	assert.NoError(t, err2)
+	assert.NotNil(t, packet.Inputs[0].NonWitnessUtxo)
+	assert.Equal(t, previousTx.TxHash(), packet.Inputs[0].NonWitnessUtxo.TxHash())
+	assert.Nil(t, packet.Inputs[0].WitnessUtxo)
+
+	// Test Payloads Without Previous Transactions
+	metadata.PreviousTransactions = nil
+	missingResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, &metadata),
+	})
+	assert.Nil(t, missingResponse)
+	assert.Equal(t, ErrUnableToParseIntermediateResult.Code, err.Code)
+
+	// Test Parse Unsigned
+	val0 := int64(0)
+	val1 := int64(1)
+	parseOps := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        0,
+				NetworkIndex: &val0,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        1,
+				NetworkIndex: &val0,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "954843000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        2,
+				NetworkIndex: &val1,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu",
+			},
+			Amount: &types.Amount{
+				Value:    "44657000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		},
+	}
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedPSBT,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	sig, err2 := privKey.Sign(signingPayload.Bytes)
+	assert.NoError(t, err2)
+	compact := make([]byte, 64)
+	sig.R.FillBytes(compact[:32])
+	sig.S.FillBytes(compact[32:])
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedPSBT,
+		Signatures: []*types.Signature{
+			{
+				Bytes:          compact,
+				SigningPayload: signingPayload,
+				PublicKey:      publicKey,
+				SignatureType:  types.Ecdsa,
+			},
+		},
+	})
+	assert.Nil(t, err)
+	signedRaw := combineResponse.SignedTransaction
+
+	// Test Combine Without Signatures
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedPSBT,
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrUnexpectedSignatureCount.Code, err.Code)
+
+	// Test Combine Signed PSBT
+	packet.Inputs[0].PartialSigs = []*psbt.PartialSig{
+		{
+			PubKey:    publicKey.Bytes,
+			Signature: append(sig.Serialize(), byte(txscript.SigHashAll)),
+		},
+	}
+	signedPSBT, err2 := packet.B64Encode()
+	assert.NoError(t, err2)
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: signedPSBT,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Combine Finalized PSBT
+	finalizedPacket, err2 := psbt.NewFromRawBytes(strings.NewReader(signedPSBT), true)
+	assert.NoError(t, err2)
+	sigScript, err2 := txscript.NewScriptBuilder().
+		AddData(finalizedPacket.Inputs[0].PartialSigs[0].Signature).
+		AddData(publicKey.Bytes).
+		Script()
+	assert.NoError(t, err2)
+	finalizedPacket.Inputs[0].PartialSigs = nil
+	finalizedPacket.Inputs[0].FinalScriptSig = sigScript
+	finalizedPSBT, err2 := finalizedPacket.B64Encode()
+	assert.NoError(t, err2)
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: finalizedPSBT,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Parse Signed PSBT
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedPSBT,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: addr.EncodeAddress()},
+		},
+	}, parseSignedResponse)
+
+	mockIndexer.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionServiceSigHash(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	seed := sha256.Sum256([]byte("signer"))
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), seed[:])
+	addr, err2 := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		dogecoin.TestnetParams,
+	)
+	assert.NoError(t, err2)
+	script, err2 := txscript.PayToAddrScript(addr)
+	assert.NoError(t, err2)
+
+	previousTx := wire.NewMsgTx(1)
+	previousTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	previousTx.AddTxOut(wire.NewTxOut(1000000000, script))
+	previousTx.AddTxOut(wire.NewTxOut(1000000000, script))
+	previousBuf := bytes.NewBuffer(make([]byte, 0, previousTx.SerializeSize()))
+	assert.NoError(t, previousTx.Serialize(previousBuf))
+
+	inputMeta := forceMarshalMap(t, &inputMetadata{SigHashType: "SINGLE|ANYONECANPAY"})
+	input := func(index uint32, metadata map[string]interface{}) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: int64(index),
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: fmt.Sprintf("%s:%d", previousTx.TxHash().String(), index),
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: metadata,
+		}
+	}
+	output := func(index int64) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: index,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM",
+			},
+			Amount: &types.Amount{
+				Value:    "999000000",
+				Currency: dogecoin.TestnetCurrency,
+			},
+		}
+	}
+	scriptPubKey := &bitcoin.ScriptPubKey{
+		Hex:  hex.EncodeToString(script),
+		Type: "pubkeyhash",
+	}
+
+	// Test Invalid Sighash Type
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations: []*types.Operation{
+				input(0, map[string]interface{}{"sighash_type": "SOME"}),
+				output(1),
+			},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	// Test SIGHASH_SINGLE Without Matching Output
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        []*types.Operation{input(0, nil), input(1, inputMeta), output(2)},
+		Metadata: forceMarshalMap(t, &constructionMetadata{
+			ScriptPubKeys: []*bitcoin.ScriptPubKey{scriptPubKey, scriptPubKey},
+		}),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	// Test Payloads
+	ops := []*types.Operation{input(0, inputMeta), output(1)}
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata: forceMarshalMap(t, &constructionMetadata{
+			ScriptPubKeys: []*bitcoin.ScriptPubKey{scriptPubKey},
+		}),
+	})
+	assert.Nil(t, err)
+
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(forceHexDecode(t, payloadsResponse.UnsignedTransaction), &unsigned))
+	assert.Equal(
+		t,
+		[]txscript.SigHashType{txscript.SigHashSingle | txscript.SigHashAnyOneCanPay},
+		unsigned.SigHashTypes,
+	)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, inputMeta, parseUnsignedResponse.Operations[0].Metadata)
+
+	// Test Combine
+	sig, err2 := privKey.Sign(payloadsResponse.Payloads[0].Bytes)
+	assert.NoError(t, err2)
+	compact := make([]byte, 64)
+	sig.R.FillBytes(compact[:32])
+	sig.S.FillBytes(compact[32:])
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				Bytes:          compact,
+				SigningPayload: payloadsResponse.Payloads[0],
+				PublicKey: &types.PublicKey{
+					Bytes:     pubKey.SerializeCompressed(),
+					CurveType: types.Secp256k1,
+				},
+				SignatureType: types.Ecdsa,
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, inputMeta, parseSignedResponse.Operations[0].Metadata)
+
+	// Test PSBT
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata: forceMarshalMap(t, &constructionMetadata{
+			ScriptPubKeys:        []*bitcoin.ScriptPubKey{scriptPubKey},
+			PSBT:                 true,
+			PreviousTransactions: []string{hex.EncodeToString(previousBuf.Bytes())},
+		}),
+	})
+	assert.Nil(t, err)
+	packet, err2 := psbt.NewFromRawBytes(strings.NewReader(payloadsResponse.UnsignedTransaction), true)
+	assert.NoError(t, err2)
+	assert.Equal(t, txscript.SigHashSingle|txscript.SigHashAnyOneCanPay, packet.Inputs[0].SighashType)
+
+	parseUnsignedResponse, err = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, inputMeta, parseUnsignedResponse.Operations[0].Metadata)
+
+	mockIndexer.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionServiceBatch(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    dogecoin.TestnetNetwork,
+		Blockchain: dogecoin.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	seed := sha256.Sum256([]byte("signer"))
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), seed[:])
+	addr, err2 := btcutil.NewAddressPubKeyHash(
+		btcutil.Hash160(pubKey.SerializeCompressed()),
+		dogecoin.TestnetParams,
+	)
+	assert.NoError(t, err2)
+	script, err2 := txscript.PayToAddrScript(addr)
+	assert.NoError(t, err2)
+	scriptPubKey := &bitcoin.ScriptPubKey{
+		Hex:  hex.EncodeToString(script),
+		Type: "pubkeyhash",
+	}
+
+	input := func(index int64, coin string, value string) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: index,
+			},
+			Type: bitcoin.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: addr.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: dogecoin.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: coin,
+				},
+				CoinAction: types.CoinSpent,
+			},
+		}
+	}
+	output := func(index int64, address string, value string) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: index,
+			},
+			Type: bitcoin.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: address,
+			},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: dogecoin.TestnetCurrency,
+			},
+		}
+	}
+	withNetworkIndex := func(op *types.Operation, networkIndex int64) *types.Operation {
+		parsed := *op
+		parsed.OperationIdentifier = &types.OperationIdentifier{
+			Index:        op.OperationIdentifier.Index,
+			NetworkIndex: &networkIndex,
+		}
+
+		return &parsed
+	}
+
+	ops := []*types.Operation{
+		input(0, "bb0d4f5a44b7de6d4e5a53b5d1c3c4e80f1e2d3c4b5a69788796a5b4c3d2e1f0:0", "-500000000"),
+		input(1, "aa0d4f5a44b7de6d4e5a53b5d1c3c4e80f1e2d3c4b5a69788796a5b4c3d2e1f0:1", "-400000000"),
+		output(2, "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM", "500000000"),
+		output(3, "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu", "200000000"),
+		output(4, "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM", "100000000"),
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          forceMarshalMap(t, &preprocessMetadata{Batch: true, BIP69: true}),
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, true, preprocessResponse.Options["batch"])
+	assert.Equal(t, true, preprocessResponse.Options["bip69"])
+
+	// Test Preprocess With Dust
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations: []*types.Operation{
+				ops[0],
+				output(1, "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM", "600000"),
+				output(2, "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu", "600000"),
+				output(3, "njLBhTAWJEDCYRRNXnM1Ff2A9EcX4BXgUu", "600000"),
+			},
+			Metadata: forceMarshalMap(t, &preprocessMetadata{Batch: true}),
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrDustOutput.Code, err.Code)
+	assert.Contains(t, err.Details["context"], "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM")
+
+	// Test Payloads With Dust
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations: []*types.Operation{
+			ops[0],
+			output(1, "nd3qcBnvLz66JKT5AyP4xk6zZN9qvArnxM", "600000"),
+		},
+		Metadata: forceMarshalMap(t, &constructionMetadata{
+			ScriptPubKeys: []*bitcoin.ScriptPubKey{scriptPubKey},
+			Batch:         true,
+		}),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrDustOutput.Code, err.Code)
+
+	// Test Payloads
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata: forceMarshalMap(t, &constructionMetadata{
+			ScriptPubKeys: []*bitcoin.ScriptPubKey{scriptPubKey, scriptPubKey},
+			Batch:         true,
+			BIP69:         true,
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 2)
+
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(forceHexDecode(t, payloadsResponse.UnsignedTransaction), &unsigned))
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(forceHexDecode(t, unsigned.Transaction))))
+	assert.True(t, txsort.IsSorted(&tx))
+	assert.Len(t, tx.TxOut, 2)
+	assert.Equal(t, int64(200000000), tx.TxOut[0].Value)
+	assert.Equal(t, int64(600000000), tx.TxOut[1].Value)
+
+	// Test Parse Unsigned
+	parseOps := []*types.Operation{
+		withNetworkIndex(ops[0], 1),
+		withNetworkIndex(ops[1], 0),
+		withNetworkIndex(ops[2], 1),
+		withNetworkIndex(ops[3], 0),
+		withNetworkIndex(ops[4], 1),
+	}
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signatures := make([]*types.Signature, len(payloadsResponse.Payloads))
+	for i, payload := range payloadsResponse.Payloads {
+		sig, err := privKey.Sign(payload.Bytes)
+		assert.NoError(t, err)
+
+		signatures[i] = &types.Signature{
+			Bytes:          sig.Serialize(),
+			SigningPayload: payload,
+			PublicKey: &types.PublicKey{
+				Bytes:     pubKey.SerializeCompressed(),
+				CurveType: types.Secp256k1,
+			},
+			SignatureType: types.Ecdsa,
+		}
+	}
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: addr.EncodeAddress()},
+			{Address: addr.EncodeAddress()},
+		},
+	}, parseSignedResponse)
+
+	mockIndexer.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
+
+func TestEstimateInputSize(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Params:   dogecoin.TestnetParams,
+		Currency: dogecoin.TestnetCurrency,
+	}
+	servicer := &ConstructionAPIService{config: cfg}
+
+	redeemScript := "522103c330b3882ca6b0017c36358015a932e204b45bcdf84e2a852df2871723e7ef54210337b50820ff8164bd44bcabd665c90320fea650efb000871f8037854dda58adeb2102aca18054fde5ee686bdfd2b48f730e24b61d0d638cdcc6284b7f4d98d9bed27153ae" // nolint
+	tests := map[string]struct {
+		script   string
+		metadata *inputMetadata
+
+		size int
+		err  *types.Error
+	}{
+		"p2pkh compressed": {
+			script:   "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+			metadata: &inputMetadata{},
+			size:     148,
+		},
+		"p2pkh uncompressed": {
+			script:   "76a91481bcc7c983fe2fe74bc3d40564ef3e149b334da188ac",
+			metadata: &inputMetadata{UncompressedPublicKey: true},
+			size:     180,
+		},
+		"p2pk": {
+			script:   "2103c330b3882ca6b0017c36358015a932e204b45bcdf84e2a852df2871723e7ef54ac",
+			metadata: &inputMetadata{},
+			size:     114,
+		},
+		"p2sh 2-of-3": {
+			script:   "a9144689dbfa1ba38300e7ae3c605fc1e9a29398b0d387",
+			metadata: &inputMetadata{RedeemScript: redeemScript},
+			size:     297, // 40 + 3 + (1 + 2 * 73 + 2 + 105)
+		},
+		"p2sh without redeem script": {
+			script:   "a9144689dbfa1ba38300e7ae3c605fc1e9a29398b0d387",
+			metadata: &inputMetadata{},
+			err:      ErrInvalidRedeemScript,
+		},
+		"nulldata": {
+			script:   "6a0568656c6c6f",
+			metadata: &inputMetadata{},
+			err:      ErrUnsupportedScriptType,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			size, err := servicer.estimateInputSize(
+				&bitcoin.ScriptPubKey{Hex: test.script},
+				test.metadata,
+			)
+			if test.err != nil {
+				assert.Equal(t, test.err.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.size, size)
+			}
+		})
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	seed := sha256.Sum256([]byte("signer"))
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), seed[:])
+	hash := sha256.Sum256([]byte("payload"))
+	sig, err := privKey.Sign(hash[:])
+	assert.NoError(t, err)
+
+	compact := make([]byte, 64)
+	sig.R.FillBytes(compact[:32])
+	sig.S.FillBytes(compact[32:])
+	highS := make([]byte, 64)
+	copy(highS, compact)
+	new(big.Int).Sub(btcec.S256().N, sig.S).FillBytes(highS[32:])
+	otherHash := sha256.Sum256([]byte("other payload"))
+
+	expected := sig.Serialize()
+	tests := map[string]struct {
+		bytes         []byte
+		signatureType types.SignatureType
+		payload       []byte
+
+		err bool
+	}{
+		"ecdsa": {
+			bytes:         compact,
+			signatureType: types.Ecdsa,
+		},
+		"ecdsa with high S": {
+			bytes:         highS,
+			signatureType: types.Ecdsa,
+		},
+		"ecdsa DER": {
+			bytes:         sig.Serialize(),
+			signatureType: types.Ecdsa,
+		},
+		"ecdsa DER with sighash": {
+			bytes:         append(sig.Serialize(), byte(txscript.SigHashAll)),
+			signatureType: types.Ecdsa,
+		},
+		"ecdsa DER with SIGHASH_SINGLE": {
+			bytes:         append(sig.Serialize(), byte(txscript.SigHashSingle)),
+			signatureType: types.Ecdsa,
+		},
+		"ecdsa_recovery": {
+			bytes:         append(append([]byte{}, compact...), 1),
+			signatureType: types.EcdsaRecovery,
+		},
+		"ecdsa_recovery DER": {
+			bytes:         sig.Serialize(),
+			signatureType: types.EcdsaRecovery,
+		},
+		"short signature": {
+			bytes:         compact[:32],
+			signatureType: types.Ecdsa,
+			err:           true,
+		},
+		"recovery signature as ecdsa": {
+			bytes:         append(append([]byte{}, compact...), 1),
+			signatureType: types.Ecdsa,
+			err:           true,
+		},
+		"zero R": {
+			bytes:         append(make([]byte, 32), compact[32:]...),
+			signatureType: types.Ecdsa,
+			err:           true,
+		},
+		"wrong payload": {
+			bytes:         compact,
+			signatureType: types.Ecdsa,
+			payload:       otherHash[:],
+			err:           true,
+		},
+		"unsupported type": {
+			bytes:         compact,
+			signatureType: types.Ed25519,
+			err:           true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			payload := test.payload
+			if payload == nil {
+				payload = hash[:]
+			}
+
+			encoded, err := parseSignature(&types.Signature{
+				SigningPayload: &types.SigningPayload{
+					Bytes: payload,
+				},
+				PublicKey: &types.PublicKey{
+					Bytes:     pubKey.SerializeCompressed(),
+					CurveType: types.Secp256k1,
+				},
+				SignatureType: test.signatureType,
+				Bytes:         test.bytes,
+			})
+			if test.err {
+				assert.Error(t, err)
+				assert.Nil(t, encoded)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expected, encoded)
+			}
+		})
+	}
+}
@@ -15,6 +15,11 @@
 package services
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -41,6 +46,38 @@ var (
 		ErrTransactionNotFound,
 		ErrCouldNotGetFeeRate,
 		ErrUnableToGetBalance,
+		ErrUnableToBuildSignatureScript,
+		ErrInvalidRedeemScript,
+		ErrUnexpectedSignatureCount,
+		ErrInsufficientFunds,
+		ErrUnableToReserveCoins,
+		ErrInvalidData,
+		ErrInvalidLockTime,
+		ErrInvalidSignature,
+		ErrScriptVerificationFailed,
+		ErrDustOutput,
+		ErrFeeTooLow,
+		ErrTransactionTooLarge,
+		ErrNonStandardScript,
+		ErrInputNotFound,
+		ErrTransactionAlreadyInChain,
+		ErrMissingInputs,
+		ErrInsufficientFee,
+		ErrNodeWarmingUp,
+		ErrBlockPruned,
+		ErrTransactionRejected,
+		ErrInvalidCallParameters,
+		ErrUnableToGetTrackedTransactions,
+		ErrWatchOnlyAccountNotFound,
+		ErrUnableToWatchAccount,
+		ErrInvalidScriptPubKeys,
+		ErrAddressIndexDisabled,
+		ErrInvalidSearchQuery,
+		ErrUnableToSearchTransactions,
+		ErrImmatureCoin,
+		ErrInvalidSubAccount,
+		ErrUnableToFindTransaction,
+		ErrAddressIndexIncomplete,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -183,8 +220,308 @@ var (
 		Code:    18, //nolint
 		Message: "Unable to get balance",
 	}
+
+	// ErrUnableToBuildSignatureScript is returned
+	// when the signature script (scriptSig) of an
+	// input cannot be assembled in ConstructionCombine.
+	ErrUnableToBuildSignatureScript = &types.Error{
+		Code:    19, //nolint
+		Message: "Unable to build signature script",
+	}
+
+	// ErrInvalidRedeemScript is returned when the
+	// redeem script of a P2SH input is missing, does
+	// not match its scriptPubKey, or is not a
+	// supported multisig script.
+	ErrInvalidRedeemScript = &types.Error{
+		Code:    20, //nolint
+		Message: "Invalid redeem script",
+	}
+
+	// ErrUnexpectedSignatureCount is returned when
+	// the number of signatures provided to
+	// ConstructionCombine does not match the
+	// signing payloads of the transaction.
+	ErrUnexpectedSignatureCount = &types.Error{
+		Code:    21, //nolint
+		Message: "Number of signatures does not match the signing payloads",
+	}
+
+	// ErrInsufficientFunds is returned when the coins
+	// of the sender cannot cover the outputs and fee
+	// of a transaction.
+	ErrInsufficientFunds = &types.Error{
+		Code:    22, //nolint
+		Message: "Insufficient funds",
+	}
+
+	// ErrUnableToReserveCoins is returned when the coins
+	// spent by a transaction are reserved by another
+	// transaction under construction.
+	ErrUnableToReserveCoins = &types.Error{
+		Code:      23, //nolint
+		Message:   "Unable to reserve coins",
+		Retriable: true,
+	}
+
+	// ErrInvalidData is returned when the payload of a
+	// DATA operation is malformed or too large for a
+	// standard OP_RETURN output.
+	ErrInvalidData = &types.Error{
+		Code:    24, //nolint
+		Message: "Invalid data output",
+	}
+
+	// ErrInvalidLockTime is returned when the lock time
+	// of a transaction or the sequence of its inputs
+	// would not lock the transaction as intended.
+	ErrInvalidLockTime = &types.Error{
+		Code:    25, //nolint
+		Message: "Invalid lock time",
+	}
+
+	// ErrInvalidSignature is returned when a signature
+	// provided to ConstructionCombine is malformed or
+	// does not verify against its signing payload.
+	ErrInvalidSignature = &types.Error{
+		Code:    26, //nolint
+		Message: "Invalid signature",
+	}
+
+	// ErrScriptVerificationFailed is returned when an
+	// input of a signed transaction does not pass script
+	// verification against the output it spends. The
+	// index of the input is returned in the details.
+	ErrScriptVerificationFailed = &types.Error{
+		Code:    27, //nolint
+		Message: "Script verification failed",
+	}
+
+	// ErrDustOutput is returned when an output of a
+	// batched transaction is below the dust limit.
+	ErrDustOutput = &types.Error{
+		Code:    28, //nolint
+		Message: "Output is below the dust limit",
+	}
+
+	// ErrFeeTooLow is returned when the fee of a
+	// transaction is below the minimum relay fee.
+	ErrFeeTooLow = &types.Error{
+		Code:    29, //nolint
+		Message: "Fee is below the minimum relay fee",
+	}
+
+	// ErrTransactionTooLarge is returned when a
+	// transaction is larger than the maximum size
+	// of a standard transaction.
+	ErrTransactionTooLarge = &types.Error{
+		Code:    30, //nolint
+		Message: "Transaction is too large",
+	}
+
+	// ErrNonStandardScript is returned when an input
+	// or output script of a transaction is not
+	// standard and would not be relayed.
+	ErrNonStandardScript = &types.Error{
+		Code:    31, //nolint
+		Message: "Non-standard script",
+	}
+
+	// ErrInputNotFound is returned when an input of
+	// a transaction spends a coin that does not exist
+	// or is already spent.
+	ErrInputNotFound = &types.Error{
+		Code:    32, //nolint
+		Message: "Input is missing or already spent",
+	}
+
+	// ErrTransactionAlreadyInChain is returned when
+	// bitcoind rejects a transaction that is already
+	// included in a block.
+	ErrTransactionAlreadyInChain = &types.Error{
+		Code:    33, //nolint
+		Message: "Transaction already in chain",
+	}
+
+	// ErrMissingInputs is returned when bitcoind rejects
+	// a transaction because the coins it spends are unknown
+	// to it. This happens when the transaction creating them
+	// has not yet propagated to bitcoind.
+	ErrMissingInputs = &types.Error{
+		Code:      34, //nolint
+		Message:   "Missing inputs",
+		Retriable: true,
+	}
+
+	// ErrInsufficientFee is returned when bitcoind rejects
+	// a transaction because its fee is too low.
+	ErrInsufficientFee = &types.Error{
+		Code:    35, //nolint
+		Message: "Insufficient fee",
+	}
+
+	// ErrNodeWarmingUp is returned when bitcoind is
+	// still loading and does not yet serve requests.
+	ErrNodeWarmingUp = &types.Error{
+		Code:      36, //nolint
+		Message:   "Bitcoind is warming up",
+		Retriable: true,
+	}
+
+	// ErrBlockPruned is returned when bitcoind no
+	// longer stores a requested block.
+	ErrBlockPruned = &types.Error{
+		Code:    37, //nolint
+		Message: "Block pruned",
+	}
+
+	// ErrTransactionRejected is returned when bitcoind
+	// rejects a transaction for any other reason.
+	ErrTransactionRejected = &types.Error{
+		Code:    38, //nolint
+		Message: "Transaction rejected",
+	}
+
+	// ErrInvalidCallParameters is returned when the
+	// parameters of a /call request are invalid.
+	ErrInvalidCallParameters = &types.Error{
+		Code:    39, //nolint
+		Message: "Invalid call parameters",
+	}
+
+	// ErrUnableToGetTrackedTransactions is returned by
+	// the indexer when it is not possible to get the
+	// transactions tracked for rebroadcast.
+	ErrUnableToGetTrackedTransactions = &types.Error{
+		Code:    40, //nolint
+		Message: "Unable to get tracked transactions",
+	}
+
+	// ErrWatchOnlyAccountNotFound is returned when the
+	// balance or coins of an extended public key are
+	// requested before it is registered.
+	ErrWatchOnlyAccountNotFound = &types.Error{
+		Code:    41, //nolint
+		Message: "Watch-only account not found",
+	}
+
+	// ErrUnableToWatchAccount is returned by the indexer
+	// when it is not possible to register or update a
+	// watch-only account.
+	ErrUnableToWatchAccount = &types.Error{
+		Code:    42, //nolint
+		Message: "Unable to watch account",
+	}
+
+	// ErrInvalidScriptPubKeys is returned when the scriptPubKeys
+	// provided to ConstructionMetadata do not pay the addresses
+	// of the inputs.
+	ErrInvalidScriptPubKeys = &types.Error{
+		Code:    43, //nolint
+		Message: "Invalid scriptPubKeys",
+	}
+
+	// ErrAddressIndexDisabled is returned by /search/transactions
+	// when the address index is not enabled in the configuration.
+	ErrAddressIndexDisabled = &types.Error{
+		Code:    44, //nolint
+		Message: "Address index is disabled",
+	}
+
+	// ErrInvalidSearchQuery is returned when the conditions of
+	// a transaction search cannot be answered by the address
+	// index.
+	ErrInvalidSearchQuery = &types.Error{
+		Code:    45, //nolint
+		Message: "Invalid search query",
+	}
+
+	// ErrUnableToSearchTransactions is returned by the indexer
+	// when it is not possible to search transactions.
+	ErrUnableToSearchTransactions = &types.Error{
+		Code:      46, //nolint
+		Message:   "Unable to search transactions",
+		Retriable: true,
+	}
+
+	// ErrImmatureCoin is returned when a transaction under
+	// construction spends a coinbase output that has not
+	// reached coinbase maturity.
+	ErrImmatureCoin = &types.Error{
+		Code:      47, //nolint
+		Message:   "Coin is immature",
+		Retriable: true,
+	}
+
+	// ErrInvalidSubAccount is returned by /account/balance
+	// when the sub-account is neither spendable nor immature.
+	ErrInvalidSubAccount = &types.Error{
+		Code:    48, //nolint
+		Message: "Invalid sub-account",
+	}
+
+	// ErrUnableToFindTransaction is returned when a
+	// transaction cannot be looked up in the indexer
+	// or hydrated from the mempool.
+	ErrUnableToFindTransaction = &types.Error{
+		Code:      49, //nolint
+		Message:   "Unable to find transaction",
+		Retriable: true,
+	}
+
+	// ErrAddressIndexIncomplete is returned by /search/transactions
+	// when a search reaches blocks synced before the address index
+	// was enabled.
+	ErrAddressIndexIncomplete = &types.Error{
+		Code:    50, //nolint
+		Message: "Address index does not cover the searched blocks",
+	}
 )
 
+// rpcErr returns the *types.Error matching the RPC error
+// returned by bitcoind in err, with its code and message in the
+// details. Errors that are not RPC errors are wrapped in
+// ErrBitcoind.
+func rpcErr(err error) *types.Error {
+	var rpcError *bitcoin.RPCError
+	if !errors.As(err, &rpcError) {
+		return wrapErr(ErrBitcoind, err)
+	}
+
+	message := strings.ToLower(rpcError.Message)
+
+	rErr := ErrBitcoind
+	switch rpcError.Code {
+	case bitcoin.RPCVerifyAlreadyInChainErrCode:
+		rErr = ErrTransactionAlreadyInChain
+	case bitcoin.RPCVerifyErrCode:
+		if strings.Contains(message, "missing inputs") {
+			rErr = ErrMissingInputs
+		}
+	case bitcoin.RPCVerifyRejectedErrCode:
+		// Rejections are prefixed with their reject code,
+		// 66 (REJECT_INSUFFICIENTFEE) for fees too low.
+		rErr = ErrTransactionRejected
+		if strings.HasPrefix(message, "66:") ||
+			strings.Contains(message, "fee not met") ||
+			strings.Contains(message, "insufficient priority") {
+			rErr = ErrInsufficientFee
+		}
+	case bitcoin.RPCInWarmupErrCode:
+		rErr = ErrNodeWarmingUp
+	case bitcoin.RPCMiscErrCode:
+		if strings.Contains(message, "pruned") {
+			rErr = ErrBlockPruned
+		}
+	}
+
+	newErr := wrapErr(rErr, err)
+	newErr.Details["rpc_code"] = rpcError.Code
+	newErr.Details["rpc_message"] = rpcError.Message
+
+	return newErr
+}
+
 // wrapErr adds details to the types.Error provided. We use a function
 // to do this so that we don't accidentially overrwrite the standard
 // errors.
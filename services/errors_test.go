@@ -16,8 +16,12 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +42,84 @@ func TestWrapErr(t *testing.T) {
 	// Assert we don't overwrite our reference.
 	assert.Nil(t, ErrUnclearIntent.Details)
 }
+
+func TestRPCErr(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		expected *types.Error
+	}{
+		"not an rpc error": {
+			err:      errors.New("connection refused"),
+			expected: ErrBitcoind,
+		},
+		"already in chain": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCVerifyAlreadyInChainErrCode,
+				Message: "transaction already in block chain",
+			},
+			expected: ErrTransactionAlreadyInChain,
+		},
+		"missing inputs": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCVerifyErrCode,
+				Message: "Missing inputs",
+			},
+			expected: ErrMissingInputs,
+		},
+		"insufficient fee": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCVerifyRejectedErrCode,
+				Message: "66: min relay fee not met",
+			},
+			expected: ErrInsufficientFee,
+		},
+		"rejected": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCVerifyRejectedErrCode,
+				Message: "18: txn-mempool-conflict",
+			},
+			expected: ErrTransactionRejected,
+		},
+		"warming up": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCInWarmupErrCode,
+				Message: "Loading block index...",
+			},
+			expected: ErrNodeWarmingUp,
+		},
+		"block pruned": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCMiscErrCode,
+				Message: "Block not available (pruned data)",
+			},
+			expected: ErrBlockPruned,
+		},
+		"unknown rpc error": {
+			err: &bitcoin.RPCError{
+				Code:    bitcoin.RPCMiscErrCode,
+				Message: "unknown",
+			},
+			expected: ErrBitcoind,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := fmt.Errorf("%w unable to submit transaction", test.err)
+			typedErr := rpcErr(err)
+
+			assert.Equal(t, test.expected.Code, typedErr.Code)
+			assert.Equal(t, test.expected.Retriable, typedErr.Retriable)
+			assert.Equal(t, err.Error(), typedErr.Details["context"])
+
+			var rpcError *bitcoin.RPCError
+			if errors.As(test.err, &rpcError) {
+				assert.Equal(t, rpcError.Code, typedErr.Details["rpc_code"])
+				assert.Equal(t, rpcError.Message, typedErr.Details["rpc_message"])
+			} else {
+				assert.NotContains(t, typedErr.Details, "rpc_code")
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// blockFeeEstimatorDepth is the number of recently
+	// indexed blocks sampled by the BlockFeeEstimator.
+	blockFeeEstimatorDepth = int64(6) // nolint:gomnd
+)
+
+// FeeEstimator estimates the fee rate (in DOGE per kB)
+// needed to get a transaction in a block within confTarget
+// blocks. Implementations may return a rate below the minimum
+// relay fee rate, callers are expected to enforce it.
+type FeeEstimator interface {
+	FeeRate(ctx context.Context, confTarget int64) (float64, error)
+}
+
+// NewFeeEstimator returns the FeeEstimator selected
+// in the provided configuration.
+func NewFeeEstimator(
+	config *configuration.Configuration,
+	client Client,
+	i Indexer,
+) FeeEstimator {
+	switch config.FeeEstimator {
+	case configuration.StaticFeeEstimator:
+		return &StaticFeeEstimator{Rate: config.StaticFeeRate}
+	case configuration.EstimateFeeEstimator:
+		return &EstimateFeeEstimator{client: client}
+	case configuration.BlocksFeeEstimator:
+		return &BlockFeeEstimator{i: i, depth: blockFeeEstimatorDepth}
+	default:
+		return &EstimateSmartFeeEstimator{client: client}
+	}
+}
+
+// StaticFeeEstimator always returns the same fee rate.
+type StaticFeeEstimator struct {
+	Rate float64
+}
+
+// FeeRate returns the configured fee rate.
+func (e *StaticFeeEstimator) FeeRate(ctx context.Context, confTarget int64) (float64, error) {
+	return e.Rate, nil
+}
+
+// EstimateFeeEstimator uses the legacy `estimatefee`
+// RPC of Dogecoin Core to estimate fee rates.
+type EstimateFeeEstimator struct {
+	client Client
+}
+
+// FeeRate returns the fee rate estimated by `estimatefee`.
+func (e *EstimateFeeEstimator) FeeRate(ctx context.Context, confTarget int64) (float64, error) {
+	return e.client.EstimateFee(ctx, confTarget)
+}
+
+// EstimateSmartFeeEstimator uses the `estimatesmartfee`
+// RPC of Dogecoin Core to estimate fee rates.
+type EstimateSmartFeeEstimator struct {
+	client Client
+}
+
+// FeeRate returns the fee rate estimated by `estimatesmartfee`.
+func (e *EstimateSmartFeeEstimator) FeeRate(ctx context.Context, confTarget int64) (float64, error) {
+	return e.client.SuggestedFeeRate(ctx, confTarget)
+}
+
+// BlockFeeEstimator returns the median fee rate paid by
+// the transactions in the most recently indexed blocks.
+type BlockFeeEstimator struct {
+	i     Indexer
+	depth int64
+}
+
+// FeeRate returns the median fee rate of the transactions
+// in the last depth indexed blocks. confTarget is ignored.
+func (e *BlockFeeEstimator) FeeRate(ctx context.Context, confTarget int64) (float64, error) {
+	head, err := e.i.GetBlockLazy(ctx, nil)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to get current block", err)
+	}
+
+	rates := []float64{}
+	for index := head.Block.BlockIdentifier.Index; index > head.Block.BlockIdentifier.Index-e.depth && index >= 0; index-- {
+		blockIndex := index
+		blockResponse, err := e.i.GetBlockLazy(ctx, &types.PartialBlockIdentifier{Index: &blockIndex})
+		if err != nil {
+			return -1, fmt.Errorf("%w: unable to get block %d", err, blockIndex)
+		}
+
+		transactions := blockResponse.OtherTransactions
+		if len(transactions) > inlineFetchLimit {
+			transactions = transactions[:inlineFetchLimit]
+		}
+
+		for _, transactionIdentifier := range transactions {
+			tx, err := e.i.GetBlockTransaction(
+				ctx,
+				blockResponse.Block.BlockIdentifier,
+				transactionIdentifier,
+			)
+			if err != nil {
+				return -1, fmt.Errorf(
+					"%w: unable to get transaction %s",
+					err,
+					transactionIdentifier.Hash,
+				)
+			}
+
+			rate, ok := transactionFeeRate(tx)
+			if ok {
+				rates = append(rates, rate)
+			}
+		}
+	}
+
+	if len(rates) == 0 {
+		return dogecoin.MinRelayFeeRate, nil
+	}
+
+	sort.Float64s(rates)
+	return rates[len(rates)/2], nil
+}
+
+// transactionFeeRate returns the fee rate (in DOGE per kB)
+// paid by a transaction. Coinbase transactions and
+// transactions without a known size are skipped.
+func transactionFeeRate(tx *types.Transaction) (float64, bool) {
+	var metadata bitcoin.TransactionMetadata
+	if err := types.UnmarshalMap(tx.Metadata, &metadata); err != nil || metadata.Size == 0 {
+		return 0, false
+	}
+
+	fee := new(big.Int)
+	for _, op := range tx.Operations {
+		switch op.Type {
+		case bitcoin.CoinbaseOpType:
+			return 0, false
+		case bitcoin.InputOpType, bitcoin.OutputOpType:
+			if op.Amount == nil {
+				continue
+			}
+
+			amount, ok := new(big.Int).SetString(op.Amount.Value, 10) // nolint:gomnd
+			if !ok {
+				return 0, false
+			}
+
+			fee.Sub(fee, amount)
+		}
+	}
+
+	if fee.Sign() <= 0 {
+		return 0, false
+	}
+
+	koinuPerB := float64(fee.Int64()) / float64(metadata.Size)
+	return koinuPerB * bytesInKb / float64(dogecoin.SatoshisInBitcoin), true
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func feeTransaction(hash string, size int64, amounts ...string) *types.Transaction {
+	ops := []*types.Operation{}
+	for i, amount := range amounts {
+		opType := bitcoin.OutputOpType
+		if amount[0] == '-' {
+			opType = bitcoin.InputOpType
+		}
+
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: int64(i),
+			},
+			Type: opType,
+			Amount: &types.Amount{
+				Value:    amount,
+				Currency: dogecoin.TestnetCurrency,
+			},
+		})
+	}
+
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: hash,
+		},
+		Operations: ops,
+		Metadata: map[string]interface{}{
+			"size": size,
+		},
+	}
+}
+
+func TestNewFeeEstimator(t *testing.T) {
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+
+	assert.Equal(
+		t,
+		&EstimateSmartFeeEstimator{client: mockClient},
+		NewFeeEstimator(&configuration.Configuration{}, mockClient, mockIndexer),
+	)
+	assert.Equal(
+		t,
+		&EstimateFeeEstimator{client: mockClient},
+		NewFeeEstimator(&configuration.Configuration{
+			FeeEstimator: configuration.EstimateFeeEstimator,
+		}, mockClient, mockIndexer),
+	)
+	assert.Equal(
+		t,
+		&StaticFeeEstimator{Rate: 0.02},
+		NewFeeEstimator(&configuration.Configuration{
+			FeeEstimator:  configuration.StaticFeeEstimator,
+			StaticFeeRate: 0.02,
+		}, mockClient, mockIndexer),
+	)
+	assert.Equal(
+		t,
+		&BlockFeeEstimator{i: mockIndexer, depth: blockFeeEstimatorDepth},
+		NewFeeEstimator(&configuration.Configuration{
+			FeeEstimator: configuration.BlocksFeeEstimator,
+		}, mockClient, mockIndexer),
+	)
+}
+
+func TestRPCFeeEstimators(t *testing.T) {
+	mockClient := &mocks.Client{}
+	ctx := context.Background()
+
+	mockClient.On("EstimateFee", ctx, int64(2)).Return(float64(0.02), nil).Once()
+	rate, err := (&EstimateFeeEstimator{client: mockClient}).FeeRate(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0.02), rate)
+
+	mockClient.On("SuggestedFeeRate", ctx, int64(2)).Return(float64(0.03), nil).Once()
+	rate, err = (&EstimateSmartFeeEstimator{client: mockClient}).FeeRate(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0.03), rate)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlockFeeEstimator(t *testing.T) {
+	mockIndexer := &mocks.Indexer{}
+	estimator := &BlockFeeEstimator{i: mockIndexer, depth: blockFeeEstimatorDepth}
+	ctx := context.Background()
+
+	block1 := &types.BlockIdentifier{Hash: "block 1", Index: 1}
+	block0 := &types.BlockIdentifier{Hash: "block 0", Index: 0}
+	coinbase := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "coinbase"},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Type:                bitcoin.CoinbaseOpType,
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 1},
+				Type:                bitcoin.OutputOpType,
+				Amount: &types.Amount{
+					Value:    "1000000000000",
+					Currency: dogecoin.TestnetCurrency,
+				},
+			},
+		},
+		Metadata: map[string]interface{}{"size": 100},
+	}
+	txs := []*types.Transaction{
+		feeTransaction("tx 1", 250, "-1000000", "900000"),    // 0.004 DOGE/kB
+		feeTransaction("tx 2", 500, "-2000000", "1000000"),   // 0.02 DOGE/kB
+		feeTransaction("tx 3", 1000, "-10000000", "9000000"), // 0.01 DOGE/kB
+	}
+
+	mockIndexer.On("GetBlockLazy", ctx, (*types.PartialBlockIdentifier)(nil)).Return(
+		&types.BlockResponse{Block: &types.Block{BlockIdentifier: block1}},
+		nil,
+	).Once()
+	index1 := int64(1)
+	mockIndexer.On("GetBlockLazy", ctx, &types.PartialBlockIdentifier{Index: &index1}).Return(
+		&types.BlockResponse{
+			Block: &types.Block{BlockIdentifier: block1},
+			OtherTransactions: []*types.TransactionIdentifier{
+				coinbase.TransactionIdentifier,
+				txs[0].TransactionIdentifier,
+				txs[1].TransactionIdentifier,
+			},
+		},
+		nil,
+	).Once()
+	index0 := int64(0)
+	mockIndexer.On("GetBlockLazy", ctx, &types.PartialBlockIdentifier{Index: &index0}).Return(
+		&types.BlockResponse{
+			Block: &types.Block{BlockIdentifier: block0},
+			OtherTransactions: []*types.TransactionIdentifier{
+				txs[2].TransactionIdentifier,
+			},
+		},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetBlockTransaction",
+		ctx,
+		block1,
+		coinbase.TransactionIdentifier,
+	).Return(coinbase, nil).Once()
+	mockIndexer.On(
+		"GetBlockTransaction",
+		ctx,
+		block1,
+		txs[0].TransactionIdentifier,
+	).Return(txs[0], nil).Once()
+	mockIndexer.On(
+		"GetBlockTransaction",
+		ctx,
+		block1,
+		txs[1].TransactionIdentifier,
+	).Return(txs[1], nil).Once()
+	mockIndexer.On(
+		"GetBlockTransaction",
+		ctx,
+		block0,
+		txs[2].TransactionIdentifier,
+	).Return(txs[2], nil).Once()
+
+	rate, err := estimator.FeeRate(ctx, defaultConfirmationTarget)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(0.01), rate, 1e-9)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestCountDustOutputs(t *testing.T) {
+	ops := []*types.Operation{
+		feeTransaction("tx", 0, "-5000000").Operations[0],
+		feeTransaction("tx", 0, "999999").Operations[0],
+		feeTransaction("tx", 0, "1000000").Operations[0],
+		feeTransaction("tx", 0, "1").Operations[0],
+	}
+
+	assert.Equal(t, int64(2), countDustOutputs(ops))
+}
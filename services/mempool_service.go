@@ -17,7 +17,9 @@ package services
 
 import (
 	"context"
+	"errors"
 
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 
 	"github.com/coinbase/rosetta-sdk-go/server"
@@ -28,16 +30,19 @@ import (
 type MempoolAPIService struct {
 	config *configuration.Configuration
 	client Client
+	i      Indexer
 }
 
 // NewMempoolAPIService creates a new instance of a MempoolAPIService.
 func NewMempoolAPIService(
 	config *configuration.Configuration,
 	client Client,
+	i Indexer,
 ) server.MempoolAPIServicer {
 	return &MempoolAPIService{
 		config: config,
 		client: client,
+		i:      i,
 	}
 }
 
@@ -52,7 +57,7 @@ func (s *MempoolAPIService) Mempool(
 
 	mempoolTransactions, err := s.client.RawMempool(ctx)
 	if err != nil {
-		return nil, wrapErr(ErrBitcoind, err)
+		return nil, rpcErr(err)
 	}
 
 	transactionIdentifiers := make([]*types.TransactionIdentifier, len(mempoolTransactions))
@@ -74,5 +79,27 @@ func (s *MempoolAPIService) MempoolTransaction(
 		return nil, wrapErr(ErrUnavailableOffline, nil)
 	}
 
-	return nil, wrapErr(ErrUnimplemented, nil)
+	hash := request.TransactionIdentifier.Hash
+	transaction, err := s.i.GetMempoolTransaction(ctx, hash)
+	if err != nil {
+		var confirmedErr *bitcoin.ConfirmedTransactionError
+		if errors.As(err, &confirmedErr) {
+			return nil, wrapErr(ErrTransactionNotFound, err)
+		}
+
+		var rpcError *bitcoin.RPCError
+		if errors.As(err, &rpcError) {
+			if rpcError.Code == bitcoin.RPCInvalidAddressOrKeyErrCode {
+				return nil, wrapErr(ErrTransactionNotFound, err)
+			}
+
+			return nil, rpcErr(err)
+		}
+
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	return &types.MempoolTransactionResponse{
+		Transaction: transaction,
+	}, nil
 }
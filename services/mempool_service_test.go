@@ -17,8 +17,11 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
 	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
 
@@ -31,7 +34,8 @@ func TestMempoolEndpoints_Offline(t *testing.T) {
 		Mode: configuration.Offline,
 	}
 	mockClient := &mocks.Client{}
-	servicer := NewMempoolAPIService(cfg, mockClient)
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
 	ctx := context.Background()
 	mem, err := servicer.Mempool(ctx, nil)
 	assert.Nil(t, mem)
@@ -43,6 +47,7 @@ func TestMempoolEndpoints_Offline(t *testing.T) {
 	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
 	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
 }
 
 func TestMempoolEndpoints_Online(t *testing.T) {
@@ -51,7 +56,8 @@ func TestMempoolEndpoints_Online(t *testing.T) {
 	}
 
 	mockClient := &mocks.Client{}
-	servicer := NewMempoolAPIService(cfg, mockClient)
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
 	ctx := context.Background()
 
 	mockClient.On("RawMempool", ctx).Return([]string{
@@ -71,9 +77,82 @@ func TestMempoolEndpoints_Online(t *testing.T) {
 		},
 	}, mem)
 
-	memTransaction, err := servicer.MempoolTransaction(ctx, nil)
+	transaction := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "tx1",
+		},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index: 0,
+				},
+				Type:   bitcoin.InputOpType,
+				Status: types.String(bitcoin.SuccessStatus),
+				Account: &types.AccountIdentifier{
+					Address: "DDzmtB41R1dNRLst99jciLWhKVmYw1yEEY",
+				},
+				Amount: &types.Amount{
+					Value: "-100",
+				},
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: &types.CoinIdentifier{
+						Identifier: "tx0:0",
+					},
+					CoinAction: types.CoinSpent,
+				},
+			},
+		},
+	}
+	mockIndexer.On("GetMempoolTransaction", ctx, "tx1").Return(transaction, nil).Once()
+	memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.MempoolTransactionResponse{
+		Transaction: transaction,
+	}, memTransaction)
+
+	// Transactions that are not in the mempool are not found
+	notFound := &bitcoin.RPCError{
+		Code:    bitcoin.RPCInvalidAddressOrKeyErrCode,
+		Message: "No such mempool or blockchain transaction",
+	}
+	mockIndexer.On("GetMempoolTransaction", ctx, "tx3").Return(
+		nil,
+		fmt.Errorf("%w: error getting raw transaction tx3", notFound),
+	).Once()
+	memTransaction, err = servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx3"},
+	})
+	assert.Nil(t, memTransaction)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	// Transactions already included in a block are not found
+	mockIndexer.On("GetMempoolTransaction", ctx, "tx4").Return(
+		nil,
+		&bitcoin.ConfirmedTransactionError{
+			Hash:          "tx4",
+			Block:         &types.BlockIdentifier{Hash: "block", Index: 1},
+			Confirmations: 1,
+		},
+	).Once()
+	memTransaction, err = servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx4"},
+	})
 	assert.Nil(t, memTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	// Transactions whose inputs cannot be hydrated
+	mockIndexer.On("GetMempoolTransaction", ctx, "tx2").Return(
+		nil,
+		errors.New("unable to find the coins spent by tx2"),
+	).Once()
+	memTransaction, err = servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx2"},
+	})
+	assert.Nil(t, memTransaction)
+	assert.Equal(t, ErrUnableToGetCoins.Code, err.Code)
+
 	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
 }
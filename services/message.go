@@ -0,0 +1,166 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// compactSignatureSize is the size of a compact
+	// signature: a header byte followed by r and s.
+	compactSignatureSize = 65
+
+	// compactHeader is the header of a compact signature
+	// before the recovery id is added. compactCompressed is
+	// added when the signing key is compressed.
+	compactHeader     = byte(27)
+	compactCompressed = byte(4)
+)
+
+// messageHash returns the hash signed by the signmessage
+// RPC of dogecoind: the double SHA256 of the message
+// magic and the message, both prefixed by their length.
+func messageHash(message string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wire.WriteVarString(&buf, 0, dogecoin.MessageMagic); err != nil {
+		return nil, fmt.Errorf("%w unable to write message magic", err)
+	}
+
+	if err := wire.WriteVarString(&buf, 0, message); err != nil {
+		return nil, fmt.Errorf("%w unable to write message", err)
+	}
+
+	return chainhash.DoubleHashB(buf.Bytes()), nil
+}
+
+// parseMessageAddress decodes the P2PKH address a
+// message is signed with.
+func parseMessageAddress(
+	params *chaincfg.Params,
+	address string,
+) (*btcutil.AddressPubKeyHash, error) {
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil {
+		return nil, fmt.Errorf("%w unable to decode address %s", err, address)
+	}
+
+	pubKeyHash, ok := addr.(*btcutil.AddressPubKeyHash)
+	if !ok || !addr.IsForNet(params) {
+		return nil, fmt.Errorf("%s is not a P2PKH address", address)
+	}
+
+	return pubKeyHash, nil
+}
+
+// messageSigningPayload returns the *types.SigningPayload of a
+// message signed by address. Signing the payload with an
+// ecdsa_recovery signature produces r, s and the recovery id
+// of the compact signature verified by verifyMessage.
+func messageSigningPayload(
+	params *chaincfg.Params,
+	address string,
+	message string,
+) (*types.SigningPayload, error) {
+	if _, err := parseMessageAddress(params, address); err != nil {
+		return nil, err
+	}
+
+	hash, err := messageHash(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SigningPayload{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: address,
+		},
+		Bytes:         hash,
+		SignatureType: types.EcdsaRecovery,
+	}, nil
+}
+
+// compactSignatures returns the compact signatures a signature may
+// encode. Signatures produced by signmessage are base64 encoded
+// compact signatures. Hex encoded ecdsa_recovery signatures (r, s
+// and the recovery id) do not encode whether the signing key is
+// compressed, so both compact signatures are returned.
+func compactSignatures(signature string) ([][]byte, error) {
+	if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil &&
+		len(decoded) == compactSignatureSize {
+		return [][]byte{decoded}, nil
+	}
+
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || len(decoded) != compactSignatureSize {
+		return nil, errors.New("signature must be a base64 compact signature or a hex ecdsa_recovery signature")
+	}
+
+	recoveryID := decoded[compactSignatureSize-1]
+	compressed := append([]byte{compactHeader + compactCompressed + recoveryID}, decoded[:64]...)
+	uncompressed := append([]byte{compactHeader + recoveryID}, decoded[:64]...)
+	return [][]byte{compressed, uncompressed}, nil
+}
+
+// verifyMessage returns the public key recovered from the
+// signature of a message if it is the key of address, serialized
+// compressed or uncompressed as in the address. The returned
+// error describes why the signature is not valid.
+func verifyMessage(
+	address *btcutil.AddressPubKeyHash,
+	message string,
+	signature string,
+) ([]byte, error) {
+	signatures, err := compactSignatures(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := messageHash(message)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sig := range signatures {
+		pubKey, compressed, err := btcec.RecoverCompact(btcec.S256(), sig, hash)
+		if err != nil {
+			continue
+		}
+
+		serialized := pubKey.SerializeUncompressed()
+		if compressed {
+			serialized = pubKey.SerializeCompressed()
+		}
+
+		if bytes.Equal(btcutil.Hash160(serialized), address.ScriptAddress()) {
+			return serialized, nil
+		}
+	}
+
+	return nil, fmt.Errorf("signature is not signed by %s", address.EncodeAddress())
+}
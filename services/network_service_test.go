@@ -41,6 +41,8 @@ var (
 			OperationTypes:          bitcoin.OperationTypes,
 			Errors:                  Errors,
 			HistoricalBalanceLookup: HistoricalBalanceLookup,
+			CallMethods:             CallMethods,
+			MempoolCoins:            MempoolCoins,
 		},
 	}
 
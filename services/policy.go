@@ -0,0 +1,299 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// PolicyChecker applies the standardness and relay policy of
+// Dogecoin Core to a signed transaction before it is broadcast,
+// so transactions that would be rejected fail with a specific
+// error instead of ErrBitcoind.
+type PolicyChecker struct {
+	client Client
+	i      Indexer
+}
+
+// NewPolicyChecker returns a new *PolicyChecker.
+func NewPolicyChecker(client Client, i Indexer) *PolicyChecker {
+	return &PolicyChecker{
+		client: client,
+		i:      i,
+	}
+}
+
+// Check returns an error if tx would not be accepted
+// to the mempool of a Dogecoin Core node. The fee of tx
+// is not checked when the indexer is behind bitcoind and
+// some of the coins it spends are not indexed yet.
+func (p *PolicyChecker) Check(
+	ctx context.Context,
+	tx *wire.MsgTx,
+) *types.Error {
+	size := tx.SerializeSize()
+	if size > dogecoin.MaxStandardTxSize {
+		return wrapErr(
+			ErrTransactionTooLarge,
+			fmt.Errorf("size %d is above %d", size, dogecoin.MaxStandardTxSize),
+		)
+	}
+
+	if err := checkScripts(tx); err != nil {
+		return err
+	}
+
+	dustOutputs, err := checkOutputs(tx)
+	if err != nil {
+		return err
+	}
+
+	inputTotal, known, err := p.checkInputs(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if !known {
+		return nil
+	}
+
+	outputTotal := int64(0)
+	for _, output := range tx.TxOut {
+		outputTotal += output.Value
+	}
+
+	fee := inputTotal - outputTotal
+	minFee := int64(math.Ceil(
+		dogecoin.MinRelayTxFeeRate*float64(dogecoin.SatoshisInBitcoin)/bytesInKb*float64(size),
+	)) + dustOutputs*dogecoin.DustLimit
+	if fee < minFee {
+		return wrapErr(
+			ErrFeeTooLow,
+			fmt.Errorf("fee %d is below %d", fee, minFee),
+		)
+	}
+
+	return nil
+}
+
+// checkScripts ensures every output of tx pays to a standard
+// script and every signature script is push only and no larger
+// than the maximum standard size.
+func checkScripts(tx *wire.MsgTx) *types.Error {
+	for i, input := range tx.TxIn {
+		if len(input.SignatureScript) > dogecoin.MaxStandardScriptSigSize {
+			return wrapErr(
+				ErrNonStandardScript,
+				fmt.Errorf("signature script of input %d is larger than %d bytes", i, dogecoin.MaxStandardScriptSigSize),
+			)
+		}
+
+		if !txscript.IsPushOnlyScript(input.SignatureScript) {
+			return wrapErr(
+				ErrNonStandardScript,
+				fmt.Errorf("signature script of input %d is not push only", i),
+			)
+		}
+	}
+
+	for i, output := range tx.TxOut {
+		// OP_RETURN outputs carrying more than the standard
+		// amount of data are classified as non-standard.
+		if txscript.GetScriptClass(output.PkScript) == txscript.NonStandardTy {
+			return wrapErr(
+				ErrNonStandardScript,
+				fmt.Errorf("script of output %d is non-standard", i),
+			)
+		}
+	}
+
+	return nil
+}
+
+// checkOutputs ensures no output of tx is below the hard
+// dust limit and returns the number of outputs below the
+// soft dust limit. OP_RETURN outputs are not considered.
+func checkOutputs(tx *wire.MsgTx) (int64, *types.Error) {
+	dustOutputs := int64(0)
+	for i, output := range tx.TxOut {
+		if txscript.GetScriptClass(output.PkScript) == txscript.NullDataTy {
+			continue
+		}
+
+		if output.Value < dogecoin.HardDustLimit {
+			return -1, wrapErr(
+				ErrDustOutput,
+				fmt.Errorf("output %d of %d is below %d", i, output.Value, dogecoin.HardDustLimit),
+			)
+		}
+
+		if output.Value < dogecoin.DustLimit {
+			dustOutputs++
+		}
+	}
+
+	return dustOutputs, nil
+}
+
+// checkInputs ensures no coin spent by tx is spent by a transaction
+// in the mempool and every coin is either unspent in the coin storage
+// of the indexer or created by a transaction in the mempool, and
+// returns the total amount spent. Coins that cannot be found while
+// the indexer is behind bitcoind may be in blocks that are not indexed
+// yet, so they are not rejected but the total is reported unknown.
+func (p *PolicyChecker) checkInputs(
+	ctx context.Context,
+	tx *wire.MsgTx,
+) (int64, bool, *types.Error) {
+	total := int64(0)
+	known := true
+	for _, input := range tx.TxIn {
+		parentHash := input.PreviousOutPoint.Hash.String()
+		identifier := &types.CoinIdentifier{
+			Identifier: bitcoin.CoinIdentifier(
+				parentHash,
+				int64(input.PreviousOutPoint.Index),
+			),
+		}
+
+		spender, err := p.i.GetMempoolSpender(ctx, identifier)
+		if err != nil {
+			return -1, false, wrapErr(ErrUnableToGetCoins, err)
+		}
+
+		if len(spender) > 0 && spender != tx.TxHash().String() {
+			return -1, false, wrapErr(
+				ErrInputNotFound,
+				fmt.Errorf("%s is already spent by %s", identifier.Identifier, spender),
+			)
+		}
+
+		coin, _, err := p.i.GetCoin(ctx, identifier)
+		if err != nil {
+			return -1, false, wrapErr(ErrUnableToGetCoins, err)
+		}
+
+		// Coins created by unconfirmed transactions are
+		// not in the coin storage until they are included
+		// in a block, so their amount is read from the
+		// mempool transaction creating them.
+		if coin == nil {
+			var rErr *types.Error
+			coin, rErr = p.mempoolCoin(ctx, parentHash, identifier)
+			if rErr != nil {
+				return -1, false, rErr
+			}
+		}
+
+		if coin == nil {
+			behind, rErr := p.indexerBehind(ctx)
+			if rErr != nil {
+				return -1, false, rErr
+			}
+
+			if !behind {
+				return -1, false, wrapErr(
+					ErrInputNotFound,
+					fmt.Errorf("%s is missing or already spent", identifier.Identifier),
+				)
+			}
+
+			known = false
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(coin.Amount.Value, 10) // nolint:gomnd
+		if !ok {
+			return -1, false, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("unable to parse amount of %s", identifier.Identifier),
+			)
+		}
+
+		total += amount.Int64()
+	}
+
+	return total, known, nil
+}
+
+// mempoolCoin returns the coin created by the output of a mempool
+// transaction, or nil if the transaction is not in the mempool or
+// has no such output.
+func (p *PolicyChecker) mempoolCoin(
+	ctx context.Context,
+	hash string,
+	identifier *types.CoinIdentifier,
+) (*types.Coin, *types.Error) {
+	parent, err := p.i.GetMempoolTransaction(ctx, hash)
+	if err != nil {
+		var confirmedErr *bitcoin.ConfirmedTransactionError
+		if errors.As(err, &confirmedErr) {
+			return nil, nil
+		}
+
+		var rpcError *bitcoin.RPCError
+		if errors.As(err, &rpcError) {
+			if rpcError.Code == bitcoin.RPCInvalidAddressOrKeyErrCode {
+				return nil, nil
+			}
+
+			return nil, rpcErr(err)
+		}
+
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	for _, op := range parent.Operations {
+		if op.CoinChange == nil ||
+			op.CoinChange.CoinAction != types.CoinCreated ||
+			op.CoinChange.CoinIdentifier.Identifier != identifier.Identifier {
+			continue
+		}
+
+		return &types.Coin{
+			CoinIdentifier: op.CoinChange.CoinIdentifier,
+			Amount:         op.Amount,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// indexerBehind returns whether the head of the
+// indexer is behind the tip of bitcoind.
+func (p *PolicyChecker) indexerBehind(ctx context.Context) (bool, *types.Error) {
+	head, err := p.i.GetBlockLazy(ctx, nil)
+	if err != nil {
+		return false, wrapErr(ErrNotReady, err)
+	}
+
+	status, err := p.client.NetworkStatus(ctx)
+	if err != nil {
+		return false, rpcErr(fmt.Errorf("%w unable to get network status", err))
+	}
+
+	return head.Block.BlockIdentifier.Index < status.CurrentBlockIdentifier.Index, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/dogecoin"
+	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	policyInputHash = "b14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f"
+)
+
+// policyTransaction returns a transaction spending output 0 of
+// policyInputHash with a standard signature script and P2PKH
+// outputs of the provided values.
+func policyTransaction(t *testing.T, values ...int64) *wire.MsgTx {
+	hash, err := chainhash.NewHashFromStr(policyInputHash)
+	assert.NoError(t, err)
+
+	sigScript, err := txscript.NewScriptBuilder().
+		AddData(bytes.Repeat([]byte{0x30}, 72)). // nolint:gomnd
+		AddData(bytes.Repeat([]byte{0x02}, 33)). // nolint:gomnd
+		Script()
+	assert.NoError(t, err)
+
+	pkScript := forceHexDecode(t, "76a91461263b081bf62c04642bf0f90fffbb94248f7c2688ac")
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0), sigScript, nil))
+	for _, value := range values {
+		tx.AddTxOut(wire.NewTxOut(value, pkScript))
+	}
+
+	return tx
+}
+
+func TestPolicyChecker(t *testing.T) {
+	inputCoin := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: policyInputHash + ":0"},
+		Amount: &types.Amount{
+			Value:    "100000000",
+			Currency: dogecoin.TestnetCurrency,
+		},
+	}
+
+	// parentTransaction returns a mempool transaction
+	// creating the coin spent by policyTransaction.
+	parentTransaction := func(value string) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: policyInputHash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                bitcoin.OutputOpType,
+					Amount: &types.Amount{
+						Value:    value,
+						Currency: dogecoin.TestnetCurrency,
+					},
+					CoinChange: &types.CoinChange{
+						CoinIdentifier: inputCoin.CoinIdentifier,
+						CoinAction:     types.CoinCreated,
+					},
+				},
+			},
+		}
+	}
+
+	notFound := &bitcoin.RPCError{
+		Code:    bitcoin.RPCInvalidAddressOrKeyErrCode,
+		Message: "No such mempool or blockchain transaction",
+	}
+
+	tooLarge := make([]int64, 3000) // nolint:gomnd
+	for i := range tooLarge {
+		tooLarge[i] = dogecoin.DustLimit
+	}
+
+	tests := map[string]struct {
+		tx        *wire.MsgTx
+		spender   string
+		coin      *types.Coin
+		getCoin   bool
+		parent    *types.Transaction
+		parentErr error
+		getParent bool
+		nodeIndex int64
+
+		err *types.Error
+	}{
+		"standard": {
+			tx:      policyTransaction(t, 99000000),
+			coin:    inputCoin,
+			getCoin: true,
+		},
+		"unconfirmed input": {
+			tx:        policyTransaction(t, 99000000),
+			getCoin:   true,
+			parent:    parentTransaction("100000000"),
+			getParent: true,
+		},
+		"unconfirmed input amount is read from its parent": {
+			tx:        policyTransaction(t, 99000000),
+			getCoin:   true,
+			parent:    parentTransaction("99000100"),
+			getParent: true,
+			err:       ErrFeeTooLow,
+		},
+		"input spent in mempool": {
+			tx:      policyTransaction(t, 99000000),
+			spender: "c14157a5c50503c8cd202a173613dd27e0027343c3d50cf85852dd020bf59c7f",
+			err:     ErrInputNotFound,
+		},
+		"missing input": {
+			tx:        policyTransaction(t, 99000000),
+			getCoin:   true,
+			parentErr: notFound,
+			getParent: true,
+			nodeIndex: 100,
+			err:       ErrInputNotFound,
+		},
+		"missing input while indexer is behind": {
+			tx:        policyTransaction(t, 99999000),
+			getCoin:   true,
+			parentErr: notFound,
+			getParent: true,
+			nodeIndex: 101,
+		},
+		"input created in block that is not indexed": {
+			tx:      policyTransaction(t, 99000000),
+			getCoin: true,
+			parentErr: &bitcoin.ConfirmedTransactionError{
+				Hash:          policyInputHash,
+				Block:         &types.BlockIdentifier{Hash: "block 101", Index: 101},
+				Confirmations: 1,
+			},
+			getParent: true,
+			nodeIndex: 101,
+		},
+		"fee too low": {
+			tx:      policyTransaction(t, 99999000),
+			coin:    inputCoin,
+			getCoin: true,
+			err:     ErrFeeTooLow,
+		},
+		"soft dust output raises minimum fee": {
+			tx:      policyTransaction(t, 99000000, 500000),
+			coin:    inputCoin,
+			getCoin: true,
+			err:     ErrFeeTooLow,
+		},
+		"dust output": {
+			tx:  policyTransaction(t, 99000000, 50000),
+			err: ErrDustOutput,
+		},
+		"non-standard output": {
+			tx: func() *wire.MsgTx {
+				tx := policyTransaction(t, 99000000)
+				tx.TxOut[0].PkScript = []byte{txscript.OP_TRUE}
+				return tx
+			}(),
+			err: ErrNonStandardScript,
+		},
+		"non push only signature script": {
+			tx: func() *wire.MsgTx {
+				tx := policyTransaction(t, 99000000)
+				tx.TxIn[0].SignatureScript = []byte{txscript.OP_DUP}
+				return tx
+			}(),
+			err: ErrNonStandardScript,
+		},
+		"too large": {
+			tx:  policyTransaction(t, tooLarge...),
+			err: ErrTransactionTooLarge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mocks.Client{}
+			mockIndexer := &mocks.Indexer{}
+			checker := NewPolicyChecker(mockClient, mockIndexer)
+			ctx := context.Background()
+
+			if test.getCoin || len(test.spender) > 0 {
+				mockIndexer.On(
+					"GetMempoolSpender",
+					ctx,
+					&types.CoinIdentifier{Identifier: policyInputHash + ":0"},
+				).Return(test.spender, nil).Once()
+			}
+			if test.getCoin {
+				mockIndexer.On(
+					"GetCoin",
+					ctx,
+					&types.CoinIdentifier{Identifier: policyInputHash + ":0"},
+				).Return(test.coin, nil, nil).Once()
+			}
+			if test.getParent {
+				mockIndexer.On(
+					"GetMempoolTransaction",
+					ctx,
+					policyInputHash,
+				).Return(test.parent, test.parentErr).Once()
+			}
+			if test.nodeIndex > 0 {
+				mockIndexer.On("GetBlockLazy", ctx, (*types.PartialBlockIdentifier)(nil)).Return(
+					&types.BlockResponse{
+						Block: &types.Block{
+							BlockIdentifier: &types.BlockIdentifier{Hash: "block 100", Index: 100},
+						},
+					},
+					nil,
+				).Once()
+				mockClient.On("NetworkStatus", ctx).Return(
+					&types.NetworkStatusResponse{
+						CurrentBlockIdentifier: &types.BlockIdentifier{
+							Hash:  "node block",
+							Index: test.nodeIndex,
+						},
+					},
+					nil,
+				).Once()
+			}
+
+			err := checker.Check(ctx, test.tx)
+			if test.err != nil {
+				assert.Equal(t, test.err.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+			mockIndexer.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/psbt"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// psbtPrefix is the base64 encoding of the
+	// PSBT magic bytes ("psbt" followed by 0xff).
+	psbtPrefix = "cHNidP8"
+)
+
+// isPSBT returns whether a transaction provided to the
+// Construction API is a base64 encoded PSBT rather than
+// a hex encoded transaction blob.
+func isPSBT(raw string) bool {
+	return strings.HasPrefix(raw, psbtPrefix)
+}
+
+// encodePSBT returns the base64 encoded PSBT (BIP174) of an
+// unsigned transaction. Dogecoin has no segregated witness, so each
+// input carries the full transaction it spends (from previousTxs) as
+// its non-witness UTXO and, for P2SH inputs, its redeem script.
+func encodePSBT(
+	tx *wire.MsgTx,
+	unsigned *unsignedTransaction,
+	previousTxs []string,
+) (string, error) {
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return "", fmt.Errorf("%w unable to create psbt", err)
+	}
+
+	previous := make(map[chainhash.Hash]*wire.MsgTx, len(previousTxs))
+	for i, rawTx := range previousTxs {
+		decoded, err := hex.DecodeString(rawTx)
+		if err != nil {
+			return "", fmt.Errorf("%w unable to decode previous transaction %d", err, i)
+		}
+
+		var previousTx wire.MsgTx
+		if err := previousTx.Deserialize(bytes.NewReader(decoded)); err != nil {
+			return "", fmt.Errorf("%w unable to deserialize previous transaction %d", err, i)
+		}
+
+		previous[previousTx.TxHash()] = &previousTx
+	}
+
+	for i, input := range tx.TxIn {
+		outpoint := input.PreviousOutPoint
+		previousTx, ok := previous[outpoint.Hash]
+		if !ok {
+			return "", fmt.Errorf("previous transaction of input %d is missing", i)
+		}
+
+		if int(outpoint.Index) >= len(previousTx.TxOut) {
+			return "", fmt.Errorf("previous transaction of input %d has no output %d", i, outpoint.Index)
+		}
+
+		// The output spent must be the one the
+		// signing payloads were computed with.
+		amount, ok := new(big.Int).SetString(unsigned.InputAmounts[i], 10) // nolint:gomnd
+		if !ok {
+			return "", fmt.Errorf("unable to parse amount of input %d", i)
+		}
+
+		prevOut := previousTx.TxOut[outpoint.Index]
+		if hex.EncodeToString(prevOut.PkScript) != unsigned.ScriptPubKeys[i].Hex ||
+			prevOut.Value != new(big.Int).Abs(amount).Int64() {
+			return "", fmt.Errorf("previous transaction of input %d does not match its coin", i)
+		}
+
+		packet.Inputs[i].NonWitnessUtxo = previousTx
+		packet.Inputs[i].SighashType = inputSigHashType(unsigned, i)
+
+		if len(unsigned.RedeemScripts[i]) > 0 {
+			redeemScript, err := hex.DecodeString(unsigned.RedeemScripts[i])
+			if err != nil {
+				return "", fmt.Errorf("%w unable to decode redeem script of input %d", err, i)
+			}
+
+			packet.Inputs[i].RedeemScript = redeemScript
+		}
+	}
+
+	return packet.B64Encode()
+}
+
+// decodePSBT decodes a base64 encoded PSBT into the unsigned
+// transaction it spends and the unsignedTransaction used by the rest
+// of the Construction API. The prevout of each input is read from
+// either its witness or non-witness UTXO.
+func decodePSBT(
+	params *chaincfg.Params,
+	raw string,
+) (*psbt.Packet, *unsignedTransaction, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(raw), true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w unable to decode psbt", err)
+	}
+
+	tx := packet.UnsignedTx
+	unsigned := &unsignedTransaction{
+		ScriptPubKeys:  make([]*bitcoin.ScriptPubKey, len(tx.TxIn)),
+		InputAmounts:   make([]string, len(tx.TxIn)),
+		InputAddresses: make([]string, len(tx.TxIn)),
+		RedeemScripts:  make([]string, len(tx.TxIn)),
+	}
+	for i, input := range packet.Inputs {
+		var prevOut *wire.TxOut
+		switch {
+		case input.WitnessUtxo != nil:
+			prevOut = input.WitnessUtxo
+		case input.NonWitnessUtxo != nil:
+			outpoint := tx.TxIn[i].PreviousOutPoint
+			if input.NonWitnessUtxo.TxHash() != outpoint.Hash ||
+				int(outpoint.Index) >= len(input.NonWitnessUtxo.TxOut) {
+				return nil, nil, fmt.Errorf("utxo of input %d does not match its outpoint", i)
+			}
+
+			prevOut = input.NonWitnessUtxo.TxOut[outpoint.Index]
+		default:
+			return nil, nil, fmt.Errorf("utxo of input %d is missing", i)
+		}
+
+		class, addr, err := bitcoin.ParseSingleAddress(params, prevOut.PkScript)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w unable to parse address of input %d", err, i)
+		}
+
+		unsigned.ScriptPubKeys[i] = &bitcoin.ScriptPubKey{
+			Hex:       hex.EncodeToString(prevOut.PkScript),
+			Type:      class.String(),
+			Addresses: []string{addr.EncodeAddress()},
+		}
+		unsigned.InputAmounts[i] = fmt.Sprintf("-%d", prevOut.Value)
+		unsigned.InputAddresses[i] = addr.EncodeAddress()
+		unsigned.RedeemScripts[i] = hex.EncodeToString(input.RedeemScript)
+
+		if input.SighashType != 0 && input.SighashType != txscript.SigHashAll {
+			if len(unsigned.SigHashTypes) == 0 {
+				unsigned.SigHashTypes = make([]txscript.SigHashType, len(tx.TxIn))
+				for j := range unsigned.SigHashTypes {
+					unsigned.SigHashTypes[j] = txscript.SigHashAll
+				}
+			}
+
+			unsigned.SigHashTypes[i] = input.SighashType
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return nil, nil, fmt.Errorf("%w unable to serialize tx", err)
+	}
+	unsigned.Transaction = hex.EncodeToString(buf.Bytes())
+
+	return packet, unsigned, nil
+}
+
+// psbtSignatures returns the partial signatures of an input of a PSBT
+// in the form used by ConstructionCombine, without their sighash type.
+func psbtSignatures(
+	input *psbt.PInput,
+	hashType txscript.SigHashType,
+) ([]*types.Signature, [][]byte, error) {
+	signatures := make([]*types.Signature, len(input.PartialSigs))
+	encoded := make([][]byte, len(input.PartialSigs))
+	for i, partialSig := range input.PartialSigs {
+		last := len(partialSig.Signature) - 1
+		if txscript.SigHashType(partialSig.Signature[last]) != hashType {
+			return nil, nil, fmt.Errorf("partial signatures must use sighash type %#x", uint32(hashType))
+		}
+
+		signatures[i] = &types.Signature{
+			PublicKey: &types.PublicKey{
+				Bytes:     partialSig.PubKey,
+				CurveType: types.Secp256k1,
+			},
+			SignatureType: types.Ecdsa,
+		}
+		encoded[i] = partialSig.Signature[:last]
+	}
+
+	return signatures, encoded, nil
+}
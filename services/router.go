@@ -56,17 +56,31 @@ func NewBlockchainRouter(
 		asserter,
 	)
 
-	mempoolAPIService := NewMempoolAPIService(config, client)
+	mempoolAPIService := NewMempoolAPIService(config, client, i)
 	mempoolAPIController := server.NewMempoolAPIController(
 		mempoolAPIService,
 		asserter,
 	)
 
+	callAPIService := NewCallAPIService(config, client, i)
+	callAPIController := server.NewCallAPIController(
+		callAPIService,
+		asserter,
+	)
+
+	searchAPIService := NewSearchAPIService(config, i)
+	searchAPIController := server.NewSearchAPIController(
+		searchAPIService,
+		asserter,
+	)
+
 	return server.NewRouter(
 		networkAPIController,
 		blockAPIController,
 		accountAPIController,
 		constructionAPIController,
 		mempoolAPIController,
+		callAPIController,
+		searchAPIController,
 	)
 }
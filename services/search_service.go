@@ -0,0 +1,112 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// defaultSearchLimit is the number of transactions
+	// returned by /search/transactions when no limit
+	// is provided. maxSearchLimit caps the limit.
+	defaultSearchLimit = int64(100)
+	maxSearchLimit     = int64(1000)
+)
+
+// SearchAPIService implements the server.SearchAPIServicer interface.
+type SearchAPIService struct {
+	config *configuration.Configuration
+	i      Indexer
+}
+
+// NewSearchAPIService returns a new *SearchAPIService.
+func NewSearchAPIService(
+	config *configuration.Configuration,
+	i Indexer,
+) server.SearchAPIServicer {
+	return &SearchAPIService{
+		config: config,
+		i:      i,
+	}
+}
+
+// SearchTransactions implements /search/transactions.
+func (s *SearchAPIService) SearchTransactions(
+	ctx context.Context,
+	request *types.SearchTransactionsRequest,
+) (*types.SearchTransactionsResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	if !s.config.AddressIndex {
+		return nil, wrapErr(ErrAddressIndexDisabled, nil)
+	}
+
+	// Transactions are looked up in the address index, so
+	// searches need a condition it indexes. Conditions on
+	// operations only narrow down the indexed transactions,
+	// which is not possible when any condition may match.
+	if request.TransactionIdentifier == nil &&
+		request.AccountIdentifier == nil &&
+		request.Address == nil &&
+		request.CoinIdentifier == nil {
+		return nil, wrapErr(
+			ErrInvalidSearchQuery,
+			errors.New("transaction_identifier, account_identifier, address or coin_identifier must be provided"),
+		)
+	}
+
+	if request.Operator != nil && *request.Operator == types.OR &&
+		(request.Currency != nil ||
+			request.Status != nil ||
+			request.Type != nil ||
+			request.Success != nil) {
+		return nil, wrapErr(
+			ErrInvalidSearchQuery,
+			errors.New("currency, status, type and success are not supported with the or operator"),
+		)
+	}
+
+	limit := defaultSearchLimit
+	if request.Limit != nil && *request.Limit > 0 {
+		limit = *request.Limit
+	}
+
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	request.Limit = &limit
+
+	response, err := s.i.SearchTransactions(ctx, request)
+	if err != nil {
+		var historyErr *bitcoin.HistoryNotIndexedError
+		if errors.As(err, &historyErr) {
+			return nil, wrapErr(ErrAddressIndexIncomplete, err)
+		}
+
+		return nil, wrapErr(ErrUnableToSearchTransactions, err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 Rosetta Dogecoin Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
+	"github.com/rosetta-dogecoin/rosetta-dogecoin/configuration"
+	mocks "github.com/rosetta-dogecoin/rosetta-dogecoin/mocks/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTransactions_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:         configuration.Offline,
+		AddressIndex: true,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{})
+	assert.Nil(t, search)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestSearchTransactions_Disabled(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	address := "address1"
+	search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+		Address: &address,
+	})
+	assert.Nil(t, search)
+	assert.Equal(t, ErrAddressIndexDisabled.Code, err.Code)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestSearchTransactions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:         configuration.Online,
+		AddressIndex: true,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	address := "address1"
+	opType := bitcoin.InputOpType
+	or := types.OR
+	success := true
+
+	t.Run("missing indexed condition", func(t *testing.T) {
+		search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+			Type: &opType,
+		})
+		assert.Nil(t, search)
+		assert.Equal(t, ErrInvalidSearchQuery.Code, err.Code)
+	})
+
+	t.Run("operation condition with or", func(t *testing.T) {
+		search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+			Operator: &or,
+			Address:  &address,
+			Success:  &success,
+		})
+		assert.Nil(t, search)
+		assert.Equal(t, ErrInvalidSearchQuery.Code, err.Code)
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		limit := defaultSearchLimit
+		nextOffset := int64(100)
+		response := &types.SearchTransactionsResponse{
+			Transactions: []*types.BlockTransaction{
+				{
+					BlockIdentifier: &types.BlockIdentifier{
+						Hash:  "block 1",
+						Index: 1,
+					},
+					Transaction: &types.Transaction{
+						TransactionIdentifier: &types.TransactionIdentifier{
+							Hash: "tx1",
+						},
+					},
+				},
+			},
+			TotalCount: 150,
+			NextOffset: &nextOffset,
+		}
+		mockIndexer.On(
+			"SearchTransactions",
+			ctx,
+			&types.SearchTransactionsRequest{
+				Address: &address,
+				Type:    &opType,
+				Limit:   &limit,
+			},
+		).Return(response, nil).Once()
+
+		search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+			Address: &address,
+			Type:    &opType,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, response, search)
+	})
+
+	t.Run("capped limit", func(t *testing.T) {
+		requested := int64(5000)
+		limit := maxSearchLimit
+		response := &types.SearchTransactionsResponse{
+			Transactions: []*types.BlockTransaction{},
+		}
+		mockIndexer.On(
+			"SearchTransactions",
+			ctx,
+			&types.SearchTransactionsRequest{
+				Operator: &or,
+				Address:  &address,
+				Limit:    &limit,
+			},
+		).Return(response, nil).Once()
+
+		search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+			Operator: &or,
+			Address:  &address,
+			Limit:    &requested,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, response, search)
+	})
+
+	t.Run("indexer error", func(t *testing.T) {
+		limit := defaultSearchLimit
+		mockIndexer.On(
+			"SearchTransactions",
+			ctx,
+			&types.SearchTransactionsRequest{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "tx1:0",
+				},
+				Limit: &limit,
+			},
+		).Return(nil, errors.New("unable to scan")).Once()
+
+		search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "tx1:0",
+			},
+		})
+		assert.Nil(t, search)
+		assert.Equal(t, ErrUnableToSearchTransactions.Code, err.Code)
+	})
+
+	t.Run("blocks not indexed", func(t *testing.T) {
+		limit := defaultSearchLimit
+		mockIndexer.On(
+			"SearchTransactions",
+			ctx,
+			&types.SearchTransactionsRequest{
+				Address: &address,
+				Limit:   &limit,
+			},
+		).Return(nil, &bitcoin.HistoryNotIndexedError{Start: 100}).Once()
+
+		search, err := servicer.SearchTransactions(ctx, &types.SearchTransactionsRequest{
+			Address: &address,
+		})
+		assert.Nil(t, search)
+		assert.Equal(t, ErrAddressIndexIncomplete.Code, err.Code)
+	})
+
+	mockIndexer.AssertExpectations(t)
+}
@@ -17,9 +17,11 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/rosetta-dogecoin/rosetta-dogecoin/bitcoin"
 
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -34,8 +36,88 @@ const (
 
 	// MempoolCoins indicates that
 	// including mempool coins in the /account/coins
-	// response is not supported.
-	MempoolCoins = false
+	// response is supported.
+	MempoolCoins = true
+
+	// SpendableSubAccount is the sub-account of /account/balance
+	// returning the balance of the coins that can be spent.
+	SpendableSubAccount = "spendable"
+
+	// ImmatureSubAccount is the sub-account of /account/balance
+	// returning the balance of the coinbase outputs that have
+	// not reached coinbase maturity.
+	ImmatureSubAccount = "immature"
+
+	// GetTrackedTransactionsMethod is the /call method
+	// returning the transactions tracked for rebroadcast.
+	GetTrackedTransactionsMethod = "get_tracked_transactions"
+
+	// ValidateAddressMethod is the /call method checking
+	// that an address is valid on the network.
+	ValidateAddressMethod = "validate_address"
+
+	// DecodeScriptMethod is the /call method
+	// decoding a hex encoded script.
+	DecodeScriptMethod = "decode_script"
+
+	// DecodeRawTransactionMethod is the /call method
+	// decoding a hex encoded transaction.
+	DecodeRawTransactionMethod = "decode_raw_transaction"
+
+	// EstimateFeeMethod is the /call method returning
+	// the fee rate suggested by the Construction API.
+	EstimateFeeMethod = "estimate_fee"
+
+	// GetTxStatusMethod is the /call method returning
+	// whether a transaction is confirmed or in the mempool.
+	GetTxStatusMethod = "get_tx_status"
+
+	// GetTransactionMethod is the /call method returning a
+	// transaction with its block or whether it is in the mempool.
+	GetTransactionMethod = "get_transaction"
+
+	// TxStatusConfirmed is the get_tx_status status of
+	// a transaction included in an indexed block.
+	TxStatusConfirmed = "confirmed"
+
+	// TxStatusMempool is the get_tx_status status of
+	// a transaction in the mempool of bitcoind.
+	TxStatusMempool = "mempool"
+
+	// TxStatusConflicted is the get_tx_status status of
+	// a transaction submitted through ConstructionSubmit
+	// with an input spent by another transaction.
+	TxStatusConflicted = "conflicted"
+
+	// TxStatusFailed is the get_tx_status status of a
+	// transaction submitted through ConstructionSubmit
+	// that bitcoind rejected when it was rebroadcast.
+	TxStatusFailed = "failed"
+
+	// TxStatusUnknown is the get_tx_status status
+	// of any other transaction.
+	TxStatusUnknown = "unknown"
+
+	// MessageSigningPayloadMethod is the /call method returning
+	// the payload to sign to sign a message with an address.
+	MessageSigningPayloadMethod = "message_signing_payload"
+
+	// VerifyMessageMethod is the /call method verifying
+	// the signature of a message signed by an address.
+	VerifyMessageMethod = "verify_message"
+
+	// WatchExtendedKeyMethod is the /call method registering
+	// the extended public key of a watch-only account.
+	WatchExtendedKeyMethod = "watch_extended_key"
+
+	// defaultGapLimit is the number of unused addresses
+	// derived after the last used address of a watch-only
+	// account, as suggested by BIP44.
+	defaultGapLimit = int64(20) // nolint:gomnd
+
+	// maxGapLimit is the largest gap limit
+	// of a watch-only account.
+	maxGapLimit = int64(1000) // nolint:gomnd
 
 	// inlineFetchLimit is the maximum number
 	// of transactions to fetch inline.
@@ -54,8 +136,10 @@ const (
 type Client interface {
 	GetPeers(context.Context) ([]*types.Peer, error)
 	SendRawTransaction(context.Context, string) (string, error)
+	EstimateFee(context.Context, int64) (float64, error)
 	SuggestedFeeRate(context.Context, int64) (float64, error)
 	RawMempool(context.Context) ([]string, error)
+	NetworkStatus(context.Context) (*types.NetworkStatusResponse, error)
 }
 
 // Indexer is used by the servicers to get block and account data.
@@ -69,10 +153,57 @@ type Indexer interface {
 		*types.BlockIdentifier,
 		*types.TransactionIdentifier,
 	) (*types.Transaction, error)
+	FindTransaction(
+		context.Context,
+		*types.TransactionIdentifier,
+	) (*types.BlockIdentifier, *types.Transaction, error)
+	GetCoin(
+		context.Context,
+		*types.CoinIdentifier,
+	) (*types.Coin, *types.AccountIdentifier, error)
 	GetCoins(
 		context.Context,
 		*types.AccountIdentifier,
 	) ([]*types.Coin, *types.BlockIdentifier, error)
+	GetCoinBlocks(
+		context.Context,
+		[]*types.Coin,
+	) ([]*types.BlockIdentifier, error)
+	GetCoinMaturities(
+		context.Context,
+		[]*types.Coin,
+	) ([]int64, error)
+	GetRawTransactions(
+		context.Context,
+		[]string,
+	) ([]string, error)
+	GetMempoolCoins(
+		context.Context,
+		[]*types.AccountIdentifier,
+	) (*bitcoin.MempoolCoins, error)
+	GetMempoolSpender(
+		context.Context,
+		*types.CoinIdentifier,
+	) (string, error)
+	GetMempoolTransaction(
+		context.Context,
+		string,
+	) (*types.Transaction, error)
+	GetCoinReservations(
+		context.Context,
+		[]*types.Coin,
+	) ([]bool, error)
+	ReleaseCoins(
+		context.Context,
+		string,
+		[]*types.Coin,
+	) error
+	ReserveCoins(
+		context.Context,
+		string,
+		[]*types.Coin,
+		time.Duration,
+	) error
 	GetScriptPubKeys(
 		context.Context,
 		[]*types.Coin,
@@ -83,6 +214,56 @@ type Indexer interface {
 		*types.Currency,
 		*types.PartialBlockIdentifier,
 	) (*types.Amount, *types.BlockIdentifier, error)
+	TrackTransaction(
+		context.Context,
+		string,
+		string,
+		[]string,
+	) error
+	GetTrackedTransaction(
+		context.Context,
+		string,
+	) (*bitcoin.TrackedTransaction, error)
+	GetTrackedTransactions(
+		context.Context,
+	) ([]*bitcoin.TrackedTransaction, error)
+	RegisterWatchOnlyAccount(
+		context.Context,
+		string,
+		int64,
+	) (*bitcoin.WatchOnlyAccount, error)
+	GetWatchOnlyAccount(
+		context.Context,
+		string,
+	) (*bitcoin.WatchOnlyAccount, error)
+	GetWatchOnlyBalance(
+		context.Context,
+		string,
+		*types.Currency,
+		*types.PartialBlockIdentifier,
+	) (*types.Amount, *types.BlockIdentifier, error)
+	GetWatchOnlyCoins(
+		context.Context,
+		string,
+	) ([]*types.Coin, *types.BlockIdentifier, error)
+	SearchTransactions(
+		context.Context,
+		*types.SearchTransactionsRequest,
+	) (*types.SearchTransactionsResponse, error)
+}
+
+// CallMethods are the methods supported by /call.
+var CallMethods = []string{
+	GetTrackedTransactionsMethod,
+	ValidateAddressMethod,
+	DecodeScriptMethod,
+	DecodeRawTransactionMethod,
+	EstimateFeeMethod,
+	GetTxStatusMethod,
+	GetTransactionMethod,
+	WatchExtendedKeyMethod,
+	MessageSigningPayloadMethod,
+	VerifyMessageMethod,
 }
 
 type unsignedTransaction struct {
@@ -90,21 +271,171 @@ type unsignedTransaction struct {
 	ScriptPubKeys  []*bitcoin.ScriptPubKey `json:"scriptPubKeys"`
 	InputAmounts   []string                `json:"input_amounts"`
 	InputAddresses []string                `json:"input_addresses"`
+	RedeemScripts  []string                `json:"redeem_scripts"`
+
+	// SigHashTypes is omitted when every input
+	// is signed with SIGHASH_ALL.
+	SigHashTypes []txscript.SigHashType `json:"sighash_types,omitempty"`
+
+	// Operations is populated when outputs are
+	// batched or inputs and outputs are sorted.
+	Operations []*operationMapping `json:"operations,omitempty"`
+
+	// ReservationID identifies the reservation of the coins spent
+	// by the transaction, released if it cannot be submitted.
+	ReservationID string `json:"reservation_id,omitempty"`
+}
+
+// operationMapping ties an operation provided to ConstructionPayloads
+// to the input or output of the transaction it is constructed into,
+// so ConstructionParse returns operations at their original indexes.
+type operationMapping struct {
+	Index        int64  `json:"index"`
+	Type         string `json:"type"`
+	NetworkIndex int64  `json:"network_index"`
+
+	// Amount is the amount of an OUTPUT operation, which
+	// may be merged with others paying the same address.
+	Amount string `json:"amount,omitempty"`
+}
+
+// deriveMetadata is the optional metadata provided
+// to ConstructionDerive to derive a P2SH multisig address
+// or the address at a BIP44 path of an extended public key.
+type deriveMetadata struct {
+	PublicKeys []string `json:"public_keys,omitempty"`
+	Threshold  int      `json:"threshold,omitempty"`
+
+	ExtendedKey string `json:"extended_public_key,omitempty"`
+	Path        string `json:"path,omitempty"`
+}
+
+// deriveResponseMetadata is returned from
+// ConstructionDerive for P2SH multisig addresses.
+type deriveResponseMetadata struct {
+	RedeemScript string `json:"redeem_script"`
+}
+
+// deriveExtendedKeyResponseMetadata is returned from
+// ConstructionDerive for addresses derived from an
+// extended public key.
+type deriveExtendedKeyResponseMetadata struct {
+	PublicKey string `json:"public_key"`
+	Path      string `json:"path"`
+}
+
+// inputMetadata is the optional metadata of an
+// INPUT operation in the Construction API.
+type inputMetadata struct {
+	RedeemScript          string   `json:"redeem_script,omitempty"`
+	Signers               []string `json:"signers,omitempty"`
+	UncompressedPublicKey bool     `json:"uncompressed_public_key,omitempty"`
+	Sequence              *uint32  `json:"sequence,omitempty"`
+	SigHashType           string   `json:"sighash_type,omitempty"`
+}
+
+// dataMetadata is the metadata of a DATA operation in
+// the Construction API. The payload of the OP_RETURN
+// output is provided either as hex or as UTF-8 text.
+type dataMetadata struct {
+	Data string `json:"data,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// preprocessMetadata is the optional metadata provided to
+// ConstructionPreprocess to set the lock time of the transaction,
+// the type of the signatures requested in the signing payloads,
+// whether the unsigned transaction is returned as a PSBT,
+// how outputs are batched and ordered
+// or to select the input coins of the sender automatically.
+type preprocessMetadata struct {
+	LockTime      uint32              `json:"lock_time,omitempty"`
+	SignatureType types.SignatureType `json:"signature_type,omitempty"`
+	PSBT          bool                `json:"psbt,omitempty"`
+	Batch         bool                `json:"batch,omitempty"`
+	BIP69         bool                `json:"bip69,omitempty"`
+
+	Sender        *types.AccountIdentifier `json:"sender,omitempty"`
+	CoinSelection CoinSelection            `json:"coin_selection,omitempty"`
+	ChangeAddress string                   `json:"change_address,omitempty"`
+	InputMetadata *inputMetadata           `json:"input_metadata,omitempty"`
+
+	// FeeRate (in DOGE per kB) replaces the fee rate of the
+	// FeeEstimator. Together with the ScriptPubKeys of the
+	// inputs, it allows ConstructionMetadata to run offline.
+	FeeRate       *float64                `json:"fee_rate,omitempty"`
+	ScriptPubKeys []*bitcoin.ScriptPubKey `json:"script_pub_keys,omitempty"`
+
+	// PreviousTransactions are the hex encoded transactions
+	// creating the coins spent by a PSBT, looked up by
+	// ConstructionMetadata when they are not provided.
+	PreviousTransactions []string `json:"previous_transactions,omitempty"`
 }
 
 type preprocessOptions struct {
-	Coins         []*types.Coin `json:"coins"`
-	EstimatedSize float64       `json:"estimated_size"`
-	FeeMultiplier *float64      `json:"fee_multiplier,omitempty"`
+	Coins         []*types.Coin       `json:"coins"`
+	Inputs        []*inputMetadata    `json:"inputs,omitempty"`
+	EstimatedSize float64             `json:"estimated_size"`
+	DustOutputs   int64               `json:"dust_outputs,omitempty"`
+	FeeMultiplier *float64            `json:"fee_multiplier,omitempty"`
+	LockTime      uint32              `json:"lock_time,omitempty"`
+	SignatureType types.SignatureType `json:"signature_type,omitempty"`
+	PSBT          bool                `json:"psbt,omitempty"`
+	Batch         bool                `json:"batch,omitempty"`
+	BIP69         bool                `json:"bip69,omitempty"`
+
+	// Populated when coins are selected automatically.
+	Sender        *types.AccountIdentifier `json:"sender,omitempty"`
+	ChangeAddress string                   `json:"change_address,omitempty"`
+	OutputAmount  string                   `json:"output_amount,omitempty"`
+
+	// Populated when provided by the caller. The ScriptPubKeys
+	// must pay the InputAddresses of the INPUT operations.
+	FeeRate        *float64                `json:"fee_rate,omitempty"`
+	ScriptPubKeys  []*bitcoin.ScriptPubKey `json:"script_pub_keys,omitempty"`
+	InputAddresses []string                `json:"input_addresses,omitempty"`
+
+	// Populated for PSBTs, provided by the caller or
+	// looked up in ConstructionMetadata.
+	PreviousTransactions []string `json:"previous_transactions,omitempty"`
 }
 
 type constructionMetadata struct {
 	ScriptPubKeys []*bitcoin.ScriptPubKey `json:"script_pub_keys"`
+	LockTime      uint32                  `json:"lock_time,omitempty"`
+	SignatureType types.SignatureType     `json:"signature_type,omitempty"`
+	PSBT          bool                    `json:"psbt,omitempty"`
+	Batch         bool                    `json:"batch,omitempty"`
+	BIP69         bool                    `json:"bip69,omitempty"`
+
+	// Populated when coins are selected automatically, the
+	// inputs and change output are added in ConstructionPayloads.
+	Sender        *types.AccountIdentifier `json:"sender,omitempty"`
+	Coins         []*types.Coin            `json:"coins,omitempty"`
+	Inputs        []*inputMetadata         `json:"inputs,omitempty"`
+	ChangeAddress string                   `json:"change_address,omitempty"`
+	ChangeAmount  string                   `json:"change_amount,omitempty"`
+
+	// PreviousTransactions are the hex encoded transactions
+	// creating the coins spent, added to each input of a PSBT.
+	PreviousTransactions []string `json:"previous_transactions,omitempty"`
+
+	// ReservationID identifies the reservation of the coins
+	// spent by the transaction, renewed in ConstructionPayloads.
+	ReservationID string `json:"reservation_id,omitempty"`
 }
 
 type signedTransaction struct {
-	Transaction  string   `json:"transaction"`
-	InputAmounts []string `json:"input_amounts"`
+	Transaction   string              `json:"transaction"`
+	InputAmounts  []string            `json:"input_amounts"`
+	Operations    []*operationMapping `json:"operations,omitempty"`
+	ReservationID string              `json:"reservation_id,omitempty"`
+}
+
+// parseMetadata is the metadata of a
+// transaction returned from ConstructionParse.
+type parseMetadata struct {
+	LockTime uint32 `json:"lock_time"`
 }
 
 // ParseOperationMetadata is returned from
@@ -112,3 +443,142 @@ type signedTransaction struct {
 type ParseOperationMetadata struct {
 	ScriptPubKey *bitcoin.ScriptPubKey `json:"scriptPubKey"`
 }
+
+// trackedTransactionsParameters are the parameters
+// of the get_tracked_transactions /call method.
+type trackedTransactionsParameters struct {
+	Hash string `json:"hash,omitempty"`
+}
+
+// trackedTransactionsResult is the result of the
+// get_tracked_transactions /call method.
+type trackedTransactionsResult struct {
+	Transactions []*bitcoin.TrackedTransaction `json:"transactions"`
+}
+
+// validateAddressParameters are the parameters
+// of the validate_address /call method.
+type validateAddressParameters struct {
+	Address string `json:"address"`
+}
+
+// validateAddressResult is the result of the
+// validate_address /call method. Type and
+// ScriptPubKey are only set for valid addresses.
+type validateAddressResult struct {
+	Address      string `json:"address"`
+	Valid        bool   `json:"is_valid"`
+	Type         string `json:"type,omitempty"`
+	ScriptPubKey string `json:"script_pub_key,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// decodeScriptParameters are the parameters
+// of the decode_script /call method.
+type decodeScriptParameters struct {
+	Script string `json:"script"`
+}
+
+// decodeScriptResult is the result of the decode_script
+// /call method. P2SH is the address paying to the script.
+type decodeScriptResult struct {
+	ASM          string   `json:"asm"`
+	Hex          string   `json:"hex"`
+	RequiredSigs int64    `json:"reqSigs,omitempty"`
+	Type         string   `json:"type"`
+	Addresses    []string `json:"addresses,omitempty"`
+	P2SH         string   `json:"p2sh"`
+}
+
+// decodeRawTransactionParameters are the parameters
+// of the decode_raw_transaction /call method.
+type decodeRawTransactionParameters struct {
+	Transaction string `json:"transaction"`
+}
+
+// estimateFeeParameters are the parameters
+// of the estimate_fee /call method.
+type estimateFeeParameters struct {
+	ConfTarget int64 `json:"conf_target,omitempty"`
+}
+
+// estimateFeeResult is the result of the
+// estimate_fee /call method. FeeRate is
+// in DOGE per kB.
+type estimateFeeResult struct {
+	ConfTarget int64   `json:"conf_target"`
+	FeeRate    float64 `json:"fee_rate"`
+}
+
+// txStatusParameters are the parameters
+// of the get_tx_status /call method.
+type txStatusParameters struct {
+	Hash string `json:"hash"`
+}
+
+// txStatusResult is the result of the get_tx_status
+// /call method. Tracked is set for transactions
+// submitted through ConstructionSubmit.
+type txStatusResult struct {
+	Hash            string                      `json:"hash"`
+	Status          string                      `json:"status"`
+	BlockIdentifier *types.BlockIdentifier      `json:"block_identifier,omitempty"`
+	Confirmations   int64                       `json:"confirmations,omitempty"`
+	Tracked         *bitcoin.TrackedTransaction `json:"tracked,omitempty"`
+}
+
+// getTransactionParameters are the parameters
+// of the get_transaction /call method.
+type getTransactionParameters struct {
+	Hash string `json:"hash"`
+}
+
+// getTransactionResult is the result of the get_transaction
+// /call method. BlockIdentifier is set for transactions in a
+// block, InMempool for transactions in the mempool. Transaction
+// is omitted for transactions in a block that is not indexed yet.
+type getTransactionResult struct {
+	Transaction     *types.Transaction     `json:"transaction,omitempty"`
+	BlockIdentifier *types.BlockIdentifier `json:"block_identifier,omitempty"`
+	Confirmations   int64                  `json:"confirmations"`
+	InMempool       bool                   `json:"in_mempool"`
+}
+
+// watchExtendedKeyParameters are the parameters
+// of the watch_extended_key /call method.
+type watchExtendedKeyParameters struct {
+	ExtendedKey string `json:"extended_public_key"`
+	GapLimit    int64  `json:"gap_limit,omitempty"`
+}
+
+// messageSigningPayloadParameters are the parameters
+// of the message_signing_payload /call method.
+type messageSigningPayloadParameters struct {
+	Address string `json:"address"`
+	Message string `json:"message"`
+}
+
+// messageSigningPayloadResult is the result of the
+// message_signing_payload /call method.
+type messageSigningPayloadResult struct {
+	SigningPayload *types.SigningPayload `json:"signing_payload"`
+}
+
+// verifyMessageParameters are the parameters of the
+// verify_message /call method. Signature is either the
+// base64 encoded output of signmessage or a hex encoded
+// ecdsa_recovery signature of the message signing payload.
+type verifyMessageParameters struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// verifyMessageResult is the result of the verify_message
+// /call method. PublicKey is the key recovered from a valid
+// signature, Error the reason a signature is not valid.
+type verifyMessageResult struct {
+	Valid     bool   `json:"is_valid"`
+	PublicKey string `json:"public_key,omitempty"`
+	Error     string `json:"error,omitempty"`
+}